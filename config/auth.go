@@ -0,0 +1,69 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"github.com/Ayyasy123/dibimbing-take-home-test/mail"
+)
+
+// LoadAuthPolicy membaca aturan akun dari environment. Verifikasi email sebelum
+// membeli tiket dan 2FA untuk admin tidak diwajibkan kecuali
+// REQUIRE_EMAIL_VERIFICATION=true dan REQUIRE_ADMIN_2FA=true.
+func LoadAuthPolicy() entity.AuthPolicy {
+	baseURL := strings.TrimRight(os.Getenv("APP_BASE_URL"), "/")
+	if baseURL == "" {
+		baseURL = "http://localhost:8080"
+	}
+
+	passwordResetURL := os.Getenv("PASSWORD_RESET_URL")
+	if passwordResetURL == "" {
+		passwordResetURL = baseURL + "/reset-password"
+	}
+
+	invitationURL := os.Getenv("ADMIN_INVITATION_URL")
+	if invitationURL == "" {
+		invitationURL = baseURL + "/accept-invitation"
+	}
+
+	issuer := os.Getenv("TOTP_ISSUER")
+	if issuer == "" {
+		issuer = "Event Ticketing"
+	}
+
+	return entity.AuthPolicy{
+		BaseURL:                  baseURL,
+		PasswordResetURL:         passwordResetURL,
+		InvitationURL:            invitationURL,
+		BootstrapToken:           os.Getenv("ADMIN_BOOTSTRAP_TOKEN"),
+		RequireEmailVerification: envBool("REQUIRE_EMAIL_VERIFICATION", false),
+		RequireAdminTwoFactor:    envBool("REQUIRE_ADMIN_2FA", false),
+		TOTPIssuer:               issuer,
+	}
+}
+
+// LoadMailSender memilih pengirim email. Jika MAIL_OUTBOX_DIR diisi email disimpan
+// sebagai file .eml di folder tersebut, jika tidak email hanya ditulis ke log.
+func LoadMailSender() mail.Sender {
+	dir := os.Getenv("MAIL_OUTBOX_DIR")
+	if dir == "" {
+		return mail.NewLogSender()
+	}
+
+	sender, err := mail.NewFileSender(dir)
+	if err != nil {
+		log.Fatal("Failed to create mail outbox directory", err)
+	}
+	return sender
+}
+
+func envBool(key string, defaultValue bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
@@ -0,0 +1,15 @@
+package config
+
+import (
+	"time"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+)
+
+// LoadCheckInPolicy membaca aturan check-in dari environment. Secara default pintu
+// dibuka 60 menit sebelum event dimulai.
+func LoadCheckInPolicy() entity.CheckInPolicy {
+	return entity.CheckInPolicy{
+		DoorsOpenBefore: time.Duration(envInt("CHECKIN_DOORS_OPEN_MINUTES", 60)) * time.Minute,
+	}
+}
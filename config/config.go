@@ -29,14 +29,44 @@ func ConnectDatabase() {
 
 	err = db.AutoMigrate(
 		&entity.User{},
+		&entity.Venue{},
+		&entity.Section{},
+		&entity.Seat{},
+		&entity.EventSeries{},
 		&entity.Event{},
+		&entity.TicketTier{},
+		&entity.Order{},
 		&entity.Ticket{},
+		&entity.TicketHold{},
+		&entity.EventSeat{},
+		&entity.WaitlistEntry{},
+		&entity.Refund{},
+		&entity.RefundRule{},
+		&entity.Payment{},
+		&entity.PromoCode{},
+		&entity.PromoRedemption{},
+		&entity.CheckInScan{},
+		&entity.TicketTransfer{},
+		&entity.ResaleListing{},
+		&entity.ResalePayout{},
+		&entity.RefreshToken{},
+		&entity.RevokedToken{},
+		&entity.AccountToken{},
+		&entity.RecoveryCode{},
+		&entity.RoleGrant{},
+		&entity.Invitation{},
+		&entity.SigningKey{},
 	)
 
 	if err != nil {
 		log.Fatal("Failed to migrate database", err)
 	}
 
+	err = runDataMigrations(db)
+	if err != nil {
+		log.Fatal("Failed to run data migrations", err)
+	}
+
 	DB = db
 	log.Println("Database connected and migrated successfully")
 }
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"os"
+	"time"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"github.com/Ayyasy123/dibimbing-take-home-test/utils"
+)
+
+// LoadKeyPolicy membaca aturan kunci JWT dari environment. Secara default token
+// ditandatangani dengan RS256, kunci dirotasi setiap 30 hari dan kunci lama masih
+// diterima 24 jam setelah dirotasi.
+func LoadKeyPolicy() entity.KeyPolicy {
+	algorithm := os.Getenv("JWT_SIGNING_ALG")
+	if algorithm == "" {
+		algorithm = utils.AlgRS256
+	}
+
+	rotationHours := envInt("JWT_KEY_ROTATION_HOURS", 720)
+	if rotationHours == 0 {
+		rotationHours = 720
+	}
+
+	// Masa tenggang tidak boleh lebih pendek dari umur access token dan link undangan
+	// admin, jika tidak token yang baru diterbitkan sebelum rotasi langsung ditolak
+	gracePeriod := time.Duration(envInt("JWT_KEY_GRACE_HOURS", 24)) * time.Hour
+	if gracePeriod < utils.InvitationTTL {
+		gracePeriod = utils.InvitationTTL
+	}
+
+	return entity.KeyPolicy{
+		Algorithm:        algorithm,
+		RotationInterval: time.Duration(rotationHours) * time.Hour,
+		GracePeriod:      gracePeriod,
+	}
+}
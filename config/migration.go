@@ -0,0 +1,123 @@
+package config
+
+import (
+	"log"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"github.com/Ayyasy123/dibimbing-take-home-test/utils"
+	"gorm.io/gorm"
+)
+
+// runDataMigrations menjalankan perubahan data yang tidak bisa ditangani AutoMigrate.
+// Setiap query harus aman dijalankan berulang kali karena dipanggil setiap aplikasi start.
+func runDataMigrations(db *gorm.DB) error {
+	// Isi harga tiket lama yang dibuat sebelum harga disimpan pada tiket.
+	// Tiket baru selalu memiliki currency, jadi currency kosong menandakan tiket lama.
+	err := db.Exec(`
+		UPDATE tickets
+		JOIN events ON tickets.event_id = events.id
+		LEFT JOIN ticket_tiers ON tickets.tier_id = ticket_tiers.id
+		SET tickets.unit_price = COALESCE(ticket_tiers.price, events.price),
+			tickets.discount = 0,
+			tickets.currency = 'IDR'
+		WHERE tickets.currency IS NULL OR tickets.currency = ''`).Error
+	if err != nil {
+		return err
+	}
+
+	// Event lama belum memiliki waktu selesai, anggap selesai di akhir hari event
+	err = db.Exec(`
+		UPDATE events
+		SET end_date = DATE_ADD(date, INTERVAL 1 DAY)
+		WHERE end_date IS NULL`).Error
+	if err != nil {
+		return err
+	}
+
+	err = normalizeStatuses(db)
+	if err != nil {
+		return err
+	}
+
+	return backfillTicketCodes(db)
+}
+
+// normalizeStatuses menyeragamkan status lama yang pernah ditulis dengan ejaan lain
+// (misalnya "active" atau "upcoming") ke status pada entity.EventStatus dan
+// entity.TicketStatus. Status "Habis" pada event dipindahkan ke ticket_availability.
+// BINARY dipakai karena collation MySQL tidak membedakan huruf besar dan kecil.
+func normalizeStatuses(db *gorm.DB) error {
+	err := db.Exec(`
+		UPDATE events
+		SET status = CASE
+				WHEN LOWER(status) IN ('berlangsung', 'ongoing') THEN 'Berlangsung'
+				WHEN LOWER(status) IN ('selesai', 'finished', 'completed', 'done') THEN 'Selesai'
+				WHEN LOWER(status) IN ('dibatalkan', 'cancelled', 'canceled') THEN 'Dibatalkan'
+				ELSE 'Aktif'
+			END,
+			ticket_availability = CASE WHEN available_tickets > 0 THEN 'Tersedia' ELSE 'Habis' END
+		WHERE status IS NULL
+			OR BINARY status NOT IN ('Aktif', 'Berlangsung', 'Selesai', 'Dibatalkan')
+			OR ticket_availability IS NULL
+			OR BINARY ticket_availability <> CASE WHEN available_tickets > 0 THEN 'Tersedia' ELSE 'Habis' END`).Error
+	if err != nil {
+		return err
+	}
+
+	// Status order mengikuti status tiketnya, jadi keduanya dinormalkan dengan aturan yang sama.
+	// Hanya ejaan lama yang dikenal yang diubah, status lain dibiarkan dan dilaporkan
+	// agar tidak diam-diam berubah menjadi tiket yang sah.
+	for _, table := range []string{"tickets", "orders"} {
+		err := db.Exec(`
+			UPDATE ` + table + `
+			SET status = CASE
+					WHEN LOWER(status) IN ('menunggu pembayaran', 'pending') THEN 'Menunggu Pembayaran'
+					WHEN LOWER(status) IN ('dibeli', 'purchased', 'paid') THEN 'Dibeli'
+					WHEN LOWER(status) IN ('dibatalkan', 'cancelled', 'canceled') THEN 'Dibatalkan'
+					WHEN LOWER(status) IN ('gagal', 'failed') THEN 'Gagal'
+				END
+			WHERE BINARY status NOT IN ('Menunggu Pembayaran', 'Dibeli', 'Dibatalkan', 'Gagal')
+				AND LOWER(status) IN ('menunggu pembayaran', 'pending', 'dibeli', 'purchased', 'paid',
+					'dibatalkan', 'cancelled', 'canceled', 'gagal', 'failed')`).Error
+		if err != nil {
+			return err
+		}
+
+		var unknown int64
+		err = db.Table(table).
+			Where("status IS NULL OR BINARY status NOT IN ('Menunggu Pembayaran', 'Dibeli', 'Dibatalkan', 'Gagal')").
+			Count(&unknown).Error
+		if err != nil {
+			return err
+		}
+
+		if unknown > 0 {
+			log.Printf("Warning: %d rows in %s have an unknown status and were left unchanged, fix them manually", unknown, table)
+		}
+	}
+
+	return nil
+}
+
+// backfillTicketCodes membuat kode check-in untuk tiket lama yang dibuat sebelum
+// tiket memiliki kode. Kode ditandatangani dengan HMAC sehingga tidak bisa dibuat lewat SQL.
+func backfillTicketCodes(db *gorm.DB) error {
+	var tickets []entity.Ticket
+	if err := db.Select("id").Where("code IS NULL OR code = ''").Find(&tickets).Error; err != nil {
+		return err
+	}
+
+	for _, ticket := range tickets {
+		code, err := utils.GenerateTicketCode(ticket.ID)
+		if err != nil {
+			return err
+		}
+
+		if err := db.Model(&entity.Ticket{}).Where("id = ?", ticket.ID).
+			Update("code", code).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,23 @@
+package config
+
+import (
+	"log"
+	"os"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/payment"
+)
+
+// LoadPaymentGateway memilih penyedia pembayaran. PAYMENT_WEBHOOK_SECRET wajib diisi.
+// Saat ini hanya ada mock gateway, dan mock gateway hanya dipakai jika
+// PAYMENT_MOCK_ENABLED=true sehingga tidak pernah aktif tanpa sengaja di production.
+func LoadPaymentGateway() payment.PaymentGateway {
+	webhookSecret := os.Getenv("PAYMENT_WEBHOOK_SECRET")
+	if webhookSecret == "" {
+		log.Fatal("PAYMENT_WEBHOOK_SECRET is not set")
+	}
+
+	if !envBool("PAYMENT_MOCK_ENABLED", false) {
+		log.Fatal("No payment gateway configured, set PAYMENT_MOCK_ENABLED=true to use the mock gateway in development or tests")
+	}
+	return payment.NewMockGateway(webhookSecret)
+}
@@ -0,0 +1,25 @@
+package config
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+)
+
+// LoadResalePolicy membaca aturan resale dari environment. Secara default tiket
+// boleh dijual kembali paling mahal 110% dari harga aslinya dengan biaya platform 10%.
+func LoadResalePolicy() entity.ResalePolicy {
+	return entity.ResalePolicy{
+		MaxPricePercent: envInt("RESALE_MAX_PRICE_PERCENT", 110),
+		FeePercent:      envInt("RESALE_FEE_PERCENT", 10),
+	}
+}
+
+func envInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value < 0 {
+		return defaultValue
+	}
+	return value
+}
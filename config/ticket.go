@@ -0,0 +1,17 @@
+package config
+
+import (
+	"log"
+	"os"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/utils"
+)
+
+// LoadTicketCodeSecret memasang TICKET_CODE_SECRET untuk menandatangani kode tiket.
+// Aplikasi tidak boleh jalan tanpa secret yang cukup panjang, karena kode tiket
+// bisa dipalsukan jika secret kosong atau mudah ditebak.
+func LoadTicketCodeSecret() {
+	if err := utils.SetTicketCodeSecret(os.Getenv("TICKET_CODE_SECRET")); err != nil {
+		log.Fatal("Invalid TICKET_CODE_SECRET: ", err)
+	}
+}
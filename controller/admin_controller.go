@@ -0,0 +1,198 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"github.com/Ayyasy123/dibimbing-take-home-test/helper"
+	"github.com/Ayyasy123/dibimbing-take-home-test/repository"
+	"github.com/Ayyasy123/dibimbing-take-home-test/service"
+	"github.com/Ayyasy123/dibimbing-take-home-test/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type AdminController struct {
+	adminService service.AdminService
+}
+
+func NewAdminController(adminService service.AdminService) *AdminController {
+	return &AdminController{adminService: adminService}
+}
+
+func (c *AdminController) RegisterAdmin(ctx *gin.Context) {
+	adminID, _, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req entity.RegisterReq
+	if !bindAdminReq(ctx, &req) {
+		return
+	}
+
+	admin, err := c.adminService.RegisterAdmin(&req, adminID)
+	if err != nil {
+		handleAdminError(ctx, "Failed to register admin", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusCreated, "Admin registered successfully", admin)
+}
+
+// BootstrapAdmin membuat admin pertama. Token dikirim lewat header X-Bootstrap-Token.
+func (c *AdminController) BootstrapAdmin(ctx *gin.Context) {
+	var req entity.RegisterReq
+	if !bindAdminReq(ctx, &req) {
+		return
+	}
+
+	admin, err := c.adminService.BootstrapAdmin(&req, ctx.GetHeader("X-Bootstrap-Token"))
+	if err != nil {
+		handleAdminError(ctx, "Failed to bootstrap admin", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusCreated, "Admin registered successfully", admin)
+}
+
+func (c *AdminController) UpdateUserRole(ctx *gin.Context) {
+	adminID, _, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid user ID", err)
+		return
+	}
+
+	var req entity.UpdateRoleReq
+	if !bindAdminReq(ctx, &req) {
+		return
+	}
+
+	if err := c.adminService.UpdateUserRole(id, &req, adminID); err != nil {
+		handleAdminError(ctx, "Failed to update user role", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusOK, "User role updated successfully", nil)
+}
+
+func (c *AdminController) FindAllRoleGrants(ctx *gin.Context) {
+	// Filter user_id opsional
+	userID := 0
+	if userIDStr := ctx.Query("user_id"); userIDStr != "" {
+		var err error
+		userID, err = strconv.Atoi(userIDStr)
+		if err != nil {
+			helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid user_id", err)
+			return
+		}
+	}
+
+	grants, err := c.adminService.FindAllRoleGrants(userID)
+	if err != nil {
+		helper.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve role grants", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusOK, "Role grants retrieved successfully", grants)
+}
+
+func (c *AdminController) CreateInvitation(ctx *gin.Context) {
+	adminID, _, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req entity.CreateInvitationReq
+	if !bindAdminReq(ctx, &req) {
+		return
+	}
+
+	invitation, err := c.adminService.CreateInvitation(&req, adminID)
+	if err != nil {
+		handleAdminError(ctx, "Failed to create invitation", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusCreated, "Invitation sent successfully", invitation)
+}
+
+func (c *AdminController) FindAllInvitations(ctx *gin.Context) {
+	invitations, err := c.adminService.FindAllInvitations()
+	if err != nil {
+		helper.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve invitations", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusOK, "Invitations retrieved successfully", invitations)
+}
+
+func (c *AdminController) RevokeInvitation(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid invitation ID", err)
+		return
+	}
+
+	if err := c.adminService.RevokeInvitation(id); err != nil {
+		handleAdminError(ctx, "Failed to revoke invitation", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusOK, "Invitation revoked successfully", nil)
+}
+
+func (c *AdminController) AcceptInvitation(ctx *gin.Context) {
+	var req entity.AcceptInvitationReq
+	if !bindAdminReq(ctx, &req) {
+		return
+	}
+
+	user, err := c.adminService.AcceptInvitation(&req)
+	if err != nil {
+		handleAdminError(ctx, "Failed to accept invitation", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusOK, "Invitation accepted, please login", user)
+}
+
+func bindAdminReq(ctx *gin.Context, req interface{}) bool {
+	if err := ctx.ShouldBindJSON(req); err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return false
+	}
+
+	// Perform validation
+	if err := helper.ValidateStruct(req); err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return false
+	}
+
+	return true
+}
+
+func handleAdminError(ctx *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidBootstrapToken):
+		helper.SendErrorResponse(ctx, http.StatusUnauthorized, message, err)
+	case errors.Is(err, service.ErrBootstrapDisabled), errors.Is(err, repository.ErrBootstrapUsed):
+		helper.SendErrorResponse(ctx, http.StatusForbidden, message, err)
+	case errors.Is(err, utils.ErrInvalidInvitationToken), errors.Is(err, repository.ErrInvalidInvitation),
+		errors.Is(err, service.ErrInvitationEmailInUse):
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, message, err)
+	case errors.Is(err, service.ErrCannotChangeOwnRole), errors.Is(err, service.ErrUserAlreadyHasRole):
+		helper.SendErrorResponse(ctx, http.StatusConflict, message, err)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		helper.SendErrorResponse(ctx, http.StatusNotFound, message, err)
+	default:
+		helper.SendErrorResponse(ctx, http.StatusInternalServerError, message, err)
+	}
+}
@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"github.com/Ayyasy123/dibimbing-take-home-test/helper"
+	"github.com/Ayyasy123/dibimbing-take-home-test/service"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type CalendarController struct {
+	calendarService service.CalendarService
+}
+
+func NewCalendarController(calendarService service.CalendarService) *CalendarController {
+	return &CalendarController{calendarService: calendarService}
+}
+
+func (c *CalendarController) GetSubscription(ctx *gin.Context) {
+	userId, exists := ctx.Get("user_id")
+	if !exists {
+		helper.SendErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized", errors.New("user id not found in token"))
+		return
+	}
+
+	token, err := c.calendarService.GetSubscriptionToken(userId.(int))
+	if err != nil {
+		helper.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve calendar subscription", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusOK, "Calendar subscription retrieved successfully", toSubscriptionRes(ctx, token))
+}
+
+func (c *CalendarController) ResetSubscription(ctx *gin.Context) {
+	userId, exists := ctx.Get("user_id")
+	if !exists {
+		helper.SendErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized", errors.New("user id not found in token"))
+		return
+	}
+
+	token, err := c.calendarService.ResetSubscriptionToken(userId.(int))
+	if err != nil {
+		helper.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to reset calendar subscription", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusOK, "Calendar subscription reset successfully", toSubscriptionRes(ctx, token))
+}
+
+// GetUserCalendar dipanggil langsung oleh aplikasi kalender, jadi autentikasinya
+// memakai token pada URL, bukan JWT. Feed hanya dilayani di /calendar/<token>.ics.
+func (c *CalendarController) GetUserCalendar(ctx *gin.Context) {
+	token, ok := strings.CutSuffix(ctx.Param("token"), ".ics")
+	if !ok {
+		helper.SendErrorResponse(ctx, http.StatusNotFound, "Calendar not found", errors.New("calendar feed URL must end with .ics"))
+		return
+	}
+
+	ics, err := c.calendarService.GetUserCalendar(token)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCalendarToken) || errors.Is(err, gorm.ErrRecordNotFound) {
+			helper.SendErrorResponse(ctx, http.StatusNotFound, "Calendar not found", err)
+			return
+		}
+		helper.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to generate calendar", err)
+		return
+	}
+
+	ctx.Data(http.StatusOK, "text/calendar; charset=utf-8", ics)
+}
+
+// toSubscriptionRes menyusun URL langganan lengkap dari host request
+func toSubscriptionRes(ctx *gin.Context, token string) entity.CalendarSubscriptionRes {
+	scheme := "http"
+	if ctx.Request.TLS != nil || ctx.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+
+	return entity.CalendarSubscriptionRes{
+		URL: scheme + "://" + ctx.Request.Host + "/calendar/" + token + ".ics",
+	}
+}
@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/service"
+	"github.com/gin-gonic/gin"
+)
+
+// fakeCalendarService hanya mengenal satu token feed
+type fakeCalendarService struct {
+	token string
+}
+
+func (s *fakeCalendarService) GetSubscriptionToken(userID int) (string, error) {
+	return s.token, nil
+}
+
+func (s *fakeCalendarService) ResetSubscriptionToken(userID int) (string, error) {
+	return s.token, nil
+}
+
+func (s *fakeCalendarService) GetUserCalendar(token string) ([]byte, error) {
+	if token != s.token {
+		return nil, service.ErrInvalidCalendarToken
+	}
+	return []byte("BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n"), nil
+}
+
+func TestGetUserCalendarServesICSPath(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/calendar/:token", NewCalendarController(&fakeCalendarService{token: "feed-token"}).GetUserCalendar)
+
+	tests := []struct {
+		path   string
+		status int
+	}{
+		{"/calendar/feed-token.ics", http.StatusOK},
+		{"/calendar/feed-token", http.StatusNotFound},
+		{"/calendar/other-token.ics", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if w.Code != tt.status {
+				t.Fatalf("GET %s = %d, want %d", tt.path, w.Code, tt.status)
+			}
+			if tt.status == http.StatusOK && w.Header().Get("Content-Type") != "text/calendar; charset=utf-8" {
+				t.Fatalf("Content-Type = %q", w.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"github.com/Ayyasy123/dibimbing-take-home-test/helper"
+	"github.com/Ayyasy123/dibimbing-take-home-test/service"
+	"github.com/gin-gonic/gin"
+)
+
+type CheckInController struct {
+	checkInService service.CheckInService
+}
+
+func NewCheckInController(checkInService service.CheckInService) *CheckInController {
+	return &CheckInController{checkInService: checkInService}
+}
+
+func (c *CheckInController) GetCheckInBundle(ctx *gin.Context) {
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid event ID", err)
+		return
+	}
+
+	bundle, err := c.checkInService.GetCheckInBundle(eventID)
+	if err != nil {
+		helper.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to export check-in bundle", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusOK, "Check-in bundle exported successfully", bundle)
+}
+
+func (c *CheckInController) UploadScans(ctx *gin.Context) {
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid event ID", err)
+		return
+	}
+
+	// ambil user id staff dari token
+	userId, exists := ctx.Get("user_id")
+	if !exists {
+		helper.SendErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized", errors.New("user id not found in token"))
+		return
+	}
+
+	var req entity.UploadScansReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	// Perform validation
+	if err := helper.ValidateStruct(&req); err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	uploadRes, err := c.checkInService.UploadScans(eventID, userId.(int), &req)
+	if err != nil {
+		helper.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to upload scans", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusOK, "Scans uploaded successfully", uploadRes)
+}
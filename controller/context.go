@@ -0,0 +1,24 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/helper"
+	"github.com/gin-gonic/gin"
+)
+
+// currentUser mengambil ID dan role user dari claims JWT. Jika tidak ada, response
+// 401 langsung dikirim dan handler cukup berhenti.
+func currentUser(ctx *gin.Context) (int, string, bool) {
+	userId, exists := ctx.Get("user_id")
+	if !exists {
+		helper.SendErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized", errors.New("user id not found in token"))
+		return 0, "", false
+	}
+
+	role, _ := ctx.Get("role")
+	roleStr, _ := role.(string)
+
+	return userId.(int), roleStr, true
+}
@@ -1,14 +1,18 @@
 package controller
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
 	"github.com/Ayyasy123/dibimbing-take-home-test/helper"
+	"github.com/Ayyasy123/dibimbing-take-home-test/repository"
 	"github.com/Ayyasy123/dibimbing-take-home-test/service"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type EventController struct {
@@ -32,9 +36,14 @@ func (c *EventController) CreateEvent(ctx *gin.Context) {
 		return
 	}
 
-	eventRes, err := c.eventService.CreateEvent(&req)
+	userID, role, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	eventRes, err := c.eventService.CreateEvent(&req, userID, role)
 	if err != nil {
-		helper.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to create event", err)
+		handleEventManageError(ctx, "Failed to create event", err)
 		return
 	}
 
@@ -107,9 +116,14 @@ func (c *EventController) UpdateEvent(ctx *gin.Context) {
 		return
 	}
 
-	err = c.eventService.UpdateEvent(id, &req)
+	userID, role, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	err = c.eventService.UpdateEvent(id, &req, userID, role)
 	if err != nil {
-		helper.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to update event", err)
+		handleEventManageError(ctx, "Failed to update event", err)
 		return
 	}
 
@@ -123,9 +137,14 @@ func (c *EventController) DeleteEvent(ctx *gin.Context) {
 		return
 	}
 
-	err = c.eventService.DeleteEvent(id)
+	userID, role, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	err = c.eventService.DeleteEvent(id, userID, role)
 	if err != nil {
-		helper.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to delete event", err)
+		handleEventManageError(ctx, "Failed to delete event", err)
 		return
 	}
 
@@ -228,8 +247,13 @@ func (c *EventController) GetEventReport(ctx *gin.Context) {
 		return
 	}
 
-	// Panggil service untuk menghasilkan laporan
-	report, err := c.eventService.GetEventReport(startDate, endDate)
+	userID, role, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	// Panggil service untuk menghasilkan laporan, organizer hanya melihat event miliknya
+	report, err := c.eventService.GetEventReport(startDate, endDate, userID, role)
 	if err != nil {
 		helper.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to generate event report", err)
 		return
@@ -246,12 +270,159 @@ func (c *EventController) CancelEvent(ctx *gin.Context) {
 		return
 	}
 
+	userID, role, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
 	// Panggil service untuk membatalkan event
-	if err := c.eventService.CancelEvent(eventID); err != nil {
-		helper.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to cancel event", err)
+	if err := c.eventService.CancelEvent(eventID, userID, role); err != nil {
+		handleEventManageError(ctx, "Failed to cancel event", err)
 		return
 	}
 
 	// Kirim response sukses
 	helper.SendSuccessResponse(ctx, http.StatusOK, "Event and associated tickets cancelled successfully", nil)
 }
+
+func (c *EventController) CreateTier(ctx *gin.Context) {
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid event ID", err)
+		return
+	}
+
+	var req entity.CreateTierReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	// Perform validation
+	if err := helper.ValidateStruct(&req); err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	userID, role, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	tierRes, err := c.eventService.CreateTier(eventID, &req, userID, role)
+	if err != nil {
+		handleEventManageError(ctx, "Failed to create ticket tier", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusCreated, "Ticket tier created successfully", tierRes)
+}
+
+func (c *EventController) FindTiersByEventID(ctx *gin.Context) {
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid event ID", err)
+		return
+	}
+
+	tiersRes, err := c.eventService.FindTiersByEventID(eventID)
+	if err != nil {
+		helper.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve ticket tiers", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusOK, "Ticket tiers retrieved successfully", tiersRes)
+}
+
+func (c *EventController) UpdateTier(ctx *gin.Context) {
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid event ID", err)
+		return
+	}
+
+	tierID, err := strconv.Atoi(ctx.Param("tier_id"))
+	if err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid tier ID", err)
+		return
+	}
+
+	var req entity.UpdateTierReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	// Perform validation
+	if err := helper.ValidateStruct(&req); err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	userID, role, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	err = c.eventService.UpdateTier(eventID, tierID, &req, userID, role)
+	if err != nil {
+		handleEventManageError(ctx, "Failed to update ticket tier", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusOK, "Ticket tier updated successfully", nil)
+}
+
+func (c *EventController) FindSeatsByEventID(ctx *gin.Context) {
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid event ID", err)
+		return
+	}
+
+	seats, err := c.eventService.FindSeatsByEventID(eventID)
+	if err != nil {
+		helper.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve seats", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusOK, "Seats retrieved successfully", seats)
+}
+
+func (c *EventController) GetEventCalendar(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid event ID", err)
+		return
+	}
+
+	ics, err := c.eventService.GetEventCalendar(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			helper.SendErrorResponse(ctx, http.StatusNotFound, "Event not found", err)
+			return
+		}
+		helper.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to generate event calendar", err)
+		return
+	}
+
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="event-%d.ics"`, id))
+	ctx.Data(http.StatusOK, "text/calendar; charset=utf-8", ics)
+}
+
+// handleEventManageError memetakan error saat admin atau organizer mengelola event
+func handleEventManageError(ctx *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, service.ErrNotEventOrganizer):
+		helper.SendErrorResponse(ctx, http.StatusForbidden, message, err)
+	case errors.Is(err, service.ErrInvalidOrganizer):
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, message, err)
+	case errors.Is(err, service.ErrInvalidStatusTransition), errors.Is(err, repository.ErrTierCapacityExceeded),
+		errors.Is(err, repository.ErrEventStatusChanged), errors.Is(err, repository.ErrCapacityBelowSold):
+		helper.SendErrorResponse(ctx, http.StatusConflict, message, err)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		helper.SendErrorResponse(ctx, http.StatusNotFound, message, err)
+	default:
+		helper.SendErrorResponse(ctx, http.StatusInternalServerError, message, err)
+	}
+}
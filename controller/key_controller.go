@@ -0,0 +1,23 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/service"
+	"github.com/gin-gonic/gin"
+)
+
+type KeyController struct {
+	keyService service.KeyService
+}
+
+func NewKeyController(keyService service.KeyService) *KeyController {
+	return &KeyController{keyService: keyService}
+}
+
+// GetJWKS mengembalikan kunci publik dalam format JWK Set apa adanya (tanpa
+// pembungkus response) karena dibaca langsung oleh library JWT di layanan lain
+func (c *KeyController) GetJWKS(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(http.StatusOK, c.keyService.JWKS())
+}
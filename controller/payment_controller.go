@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"github.com/Ayyasy123/dibimbing-take-home-test/helper"
+	"github.com/Ayyasy123/dibimbing-take-home-test/service"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type PaymentController struct {
+	paymentService service.PaymentService
+}
+
+func NewPaymentController(paymentService service.PaymentService) *PaymentController {
+	return &PaymentController{paymentService: paymentService}
+}
+
+func (c *PaymentController) HandleWebhook(ctx *gin.Context) {
+	// Signature dihitung dari body mentah, jadi body tidak boleh di-bind ke struct terlebih dahulu
+	payload, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	err = c.paymentService.HandleWebhook(payload, ctx.GetHeader("X-Signature"))
+	if err != nil {
+		handlePaymentError(ctx, "Failed to process webhook", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusOK, "Webhook processed successfully", nil)
+}
+
+func (c *PaymentController) SimulateMockPayment(ctx *gin.Context) {
+	userID, _, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req entity.SimulatePaymentReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	// Perform validation
+	if err := helper.ValidateStruct(&req); err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	err := c.paymentService.SimulateMockPayment(ctx.Param("charge_id"), req.Event, userID)
+	if err != nil {
+		handlePaymentError(ctx, "Failed to simulate payment", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusOK, "Payment simulated successfully", nil)
+}
+
+func handlePaymentError(ctx *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidWebhookSignature):
+		helper.SendErrorResponse(ctx, http.StatusUnauthorized, message, err)
+	case errors.Is(err, service.ErrNotOrderOwner):
+		helper.SendErrorResponse(ctx, http.StatusForbidden, message, err)
+	case errors.Is(err, service.ErrInvalidPaymentTransition):
+		helper.SendErrorResponse(ctx, http.StatusConflict, message, err)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		helper.SendErrorResponse(ctx, http.StatusNotFound, message, err)
+	default:
+		helper.SendErrorResponse(ctx, http.StatusInternalServerError, message, err)
+	}
+}
@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"github.com/Ayyasy123/dibimbing-take-home-test/helper"
+	"github.com/Ayyasy123/dibimbing-take-home-test/repository"
+	"github.com/Ayyasy123/dibimbing-take-home-test/service"
+	"github.com/gin-gonic/gin"
+)
+
+type PromoController struct {
+	promoService service.PromoService
+}
+
+func NewPromoController(promoService service.PromoService) *PromoController {
+	return &PromoController{promoService: promoService}
+}
+
+func (c *PromoController) CreatePromoCode(ctx *gin.Context) {
+	var req entity.CreatePromoCodeReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	// Perform validation
+	if err := helper.ValidateStruct(&req); err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	promoRes, err := c.promoService.CreatePromoCode(&req)
+	if err != nil {
+		helper.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to create promo code", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusCreated, "Promo code created successfully", promoRes)
+}
+
+func (c *PromoController) FindAllPromoCodes(ctx *gin.Context) {
+	var paginationReq helper.PaginationRequest
+	if err := ctx.ShouldBindQuery(&paginationReq); err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid pagination parameters", err)
+		return
+	}
+
+	// Set default values if page or limit is not provided
+	if paginationReq.Page == 0 {
+		paginationReq.Page = 1 // Default page is 1
+	}
+	if paginationReq.Limit == 0 {
+		paginationReq.Limit = 10 // Default limit is 10
+	}
+
+	promosRes, err := c.promoService.FindAllPromoCodes()
+	if err != nil {
+		helper.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve promo codes", err)
+		return
+	}
+
+	var data []interface{}
+	for _, promo := range promosRes {
+		data = append(data, promo)
+	}
+
+	paginatedResponse := helper.Paginate(data, paginationReq.Page, paginationReq.Limit)
+
+	helper.SendSuccessResponse(ctx, http.StatusOK, "Promo codes retrieved successfully", paginatedResponse)
+}
+
+func (c *PromoController) ValidatePromoCode(ctx *gin.Context) {
+	// ambil user id dari token
+	userId, exists := ctx.Get("user_id")
+	if !exists {
+		helper.SendErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized", errors.New("user id not found in token"))
+		return
+	}
+
+	var req entity.ValidatePromoCodeReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	// Perform validation
+	if err := helper.ValidateStruct(&req); err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	previewRes, err := c.promoService.ValidatePromoCode(&req, userId.(int))
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidPromoCode) {
+			helper.SendErrorResponse(ctx, http.StatusBadRequest, "Promo code cannot be used", err)
+			return
+		}
+		helper.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to validate promo code", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusOK, "Promo code is valid", previewRes)
+}
@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"github.com/Ayyasy123/dibimbing-take-home-test/helper"
+	"github.com/Ayyasy123/dibimbing-take-home-test/service"
+	"github.com/gin-gonic/gin"
+)
+
+type RefundController struct {
+	refundService service.RefundService
+}
+
+func NewRefundController(refundService service.RefundService) *RefundController {
+	return &RefundController{refundService: refundService}
+}
+
+func (c *RefundController) FindAllRefunds(ctx *gin.Context) {
+	var paginationReq helper.PaginationRequest
+	if err := ctx.ShouldBindQuery(&paginationReq); err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid pagination parameters", err)
+		return
+	}
+
+	// Set default values if page or limit is not provided
+	if paginationReq.Page == 0 {
+		paginationReq.Page = 1 // Default page is 1
+	}
+	if paginationReq.Limit == 0 {
+		paginationReq.Limit = 10 // Default limit is 10
+	}
+
+	refundsRes, err := c.refundService.FindAllRefunds()
+	if err != nil {
+		helper.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve refunds", err)
+		return
+	}
+
+	var data []interface{}
+	for _, refund := range refundsRes {
+		data = append(data, refund)
+	}
+
+	paginatedResponse := helper.Paginate(data, paginationReq.Page, paginationReq.Limit)
+
+	helper.SendSuccessResponse(ctx, http.StatusOK, "Refunds retrieved successfully", paginatedResponse)
+}
+
+func (c *RefundController) UpdateRefund(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid refund ID", err)
+		return
+	}
+
+	var req entity.UpdateRefundReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	// Perform validation
+	if err := helper.ValidateStruct(&req); err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	err = c.refundService.UpdateRefund(id, &req)
+	if err != nil {
+		helper.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to update refund", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusOK, "Refund updated successfully", nil)
+}
+
+func (c *RefundController) FindRefundPolicy(ctx *gin.Context) {
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid event ID", err)
+		return
+	}
+
+	rules, err := c.refundService.FindRefundPolicy(eventID)
+	if err != nil {
+		helper.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve refund policy", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusOK, "Refund policy retrieved successfully", rules)
+}
+
+func (c *RefundController) SetRefundPolicy(ctx *gin.Context) {
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid event ID", err)
+		return
+	}
+
+	var req entity.SetRefundPolicyReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	// Perform validation
+	if err := helper.ValidateStruct(&req); err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	userID, role, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	err = c.refundService.SetRefundPolicy(eventID, &req, userID, role)
+	if err != nil {
+		handleEventManageError(ctx, "Failed to update refund policy", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusOK, "Refund policy updated successfully", nil)
+}
@@ -0,0 +1,174 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"github.com/Ayyasy123/dibimbing-take-home-test/helper"
+	"github.com/Ayyasy123/dibimbing-take-home-test/repository"
+	"github.com/Ayyasy123/dibimbing-take-home-test/service"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ResaleController struct {
+	resaleService service.ResaleService
+}
+
+func NewResaleController(resaleService service.ResaleService) *ResaleController {
+	return &ResaleController{resaleService: resaleService}
+}
+
+func (c *ResaleController) CreateListing(ctx *gin.Context) {
+	// ambil user id dari token
+	userId, exists := ctx.Get("user_id")
+	if !exists {
+		helper.SendErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized", errors.New("user id not found in token"))
+		return
+	}
+
+	var req entity.CreateListingReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	// Perform validation
+	if err := helper.ValidateStruct(&req); err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	listingRes, err := c.resaleService.CreateListing(userId.(int), &req)
+	if err != nil {
+		handleResaleError(ctx, "Failed to list ticket for resale", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusCreated, "Ticket listed for resale successfully", listingRes)
+}
+
+func (c *ResaleController) FindActiveListings(ctx *gin.Context) {
+	var eventID int
+	if eventIDStr := ctx.Query("event_id"); eventIDStr != "" {
+		var err error
+		eventID, err = strconv.Atoi(eventIDStr)
+		if err != nil {
+			helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid event ID", err)
+			return
+		}
+	}
+
+	listingsRes, err := c.resaleService.FindActiveListings(eventID)
+	if err != nil {
+		helper.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve resale listings", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusOK, "Resale listings retrieved successfully", listingsRes)
+}
+
+func (c *ResaleController) WithdrawListing(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid listing ID", err)
+		return
+	}
+
+	// ambil user id dari token
+	userId, exists := ctx.Get("user_id")
+	if !exists {
+		helper.SendErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized", errors.New("user id not found in token"))
+		return
+	}
+
+	if err := c.resaleService.WithdrawListing(id, userId.(int)); err != nil {
+		handleResaleError(ctx, "Failed to withdraw resale listing", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusOK, "Resale listing withdrawn successfully", nil)
+}
+
+func (c *ResaleController) BuyListing(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid listing ID", err)
+		return
+	}
+
+	// ambil user id dari token
+	userId, exists := ctx.Get("user_id")
+	if !exists {
+		helper.SendErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized", errors.New("user id not found in token"))
+		return
+	}
+
+	orderRes, err := c.resaleService.BuyListing(id, userId.(int))
+	if err != nil {
+		handleResaleError(ctx, "Failed to buy resale listing", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusCreated, "Resale order created successfully", orderRes)
+}
+
+func (c *ResaleController) FindPayouts(ctx *gin.Context) {
+	userID, role, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	payoutsRes, err := c.resaleService.FindPayouts(userID, role)
+	if err != nil {
+		helper.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve resale payouts", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusOK, "Resale payouts retrieved successfully", payoutsRes)
+}
+
+func (c *ResaleController) UpdatePayout(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid payout ID", err)
+		return
+	}
+
+	var req entity.UpdatePayoutReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	// Perform validation
+	if err := helper.ValidateStruct(&req); err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if err := c.resaleService.UpdatePayout(id, &req); err != nil {
+		handleResaleError(ctx, "Failed to update resale payout", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusOK, "Resale payout updated successfully", nil)
+}
+
+func handleResaleError(ctx *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, service.ErrNotTicketOwner):
+		helper.SendErrorResponse(ctx, http.StatusForbidden, message, err)
+	case errors.Is(err, service.ErrResalePriceTooHigh):
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, message, err)
+	case errors.Is(err, repository.ErrListingNotActive), errors.Is(err, repository.ErrEventNotOnSale),
+		errors.Is(err, repository.ErrPayoutNotPending):
+		helper.SendErrorResponse(ctx, http.StatusConflict, message, err)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		helper.SendErrorResponse(ctx, http.StatusNotFound, message, err)
+	default:
+		helper.SendErrorResponse(ctx, http.StatusInternalServerError, message, err)
+	}
+}
@@ -0,0 +1,153 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"github.com/Ayyasy123/dibimbing-take-home-test/helper"
+	"github.com/Ayyasy123/dibimbing-take-home-test/service"
+	"github.com/Ayyasy123/dibimbing-take-home-test/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type SeriesController struct {
+	seriesService service.SeriesService
+}
+
+func NewSeriesController(seriesService service.SeriesService) *SeriesController {
+	return &SeriesController{seriesService: seriesService}
+}
+
+func (c *SeriesController) CreateSeries(ctx *gin.Context) {
+	var req entity.CreateSeriesReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	// Perform validation
+	if err := helper.ValidateStruct(&req); err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	userID, role, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	seriesRes, err := c.seriesService.CreateSeries(&req, userID, role)
+	if err != nil {
+		handleSeriesError(ctx, "Failed to create event series", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusCreated, "Event series created successfully", seriesRes)
+}
+
+func (c *SeriesController) FindSeriesByID(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid series ID", err)
+		return
+	}
+
+	seriesRes, err := c.seriesService.FindSeriesByID(id)
+	if err != nil {
+		handleSeriesError(ctx, "Failed to retrieve event series", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusOK, "Event series retrieved successfully", seriesRes)
+}
+
+func (c *SeriesController) UpdateSeries(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid series ID", err)
+		return
+	}
+
+	var req entity.UpdateSeriesReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	// Perform validation
+	if err := helper.ValidateStruct(&req); err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	userID, role, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	seriesRes, err := c.seriesService.UpdateSeries(id, &req, userID, role)
+	if err != nil {
+		handleSeriesError(ctx, "Failed to update event series", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusOK, "Event series and upcoming occurrences updated successfully", seriesRes)
+}
+
+func (c *SeriesController) GenerateOccurrences(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid series ID", err)
+		return
+	}
+
+	userID, role, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	created, err := c.seriesService.GenerateOccurrences(id, userID, role)
+	if err != nil {
+		handleSeriesError(ctx, "Failed to generate occurrences", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusOK, "Occurrences generated successfully", gin.H{"created": created})
+}
+
+func (c *SeriesController) GetSeriesReport(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid series ID", err)
+		return
+	}
+
+	userID, role, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	report, err := c.seriesService.GetSeriesReport(id, userID, role)
+	if err != nil {
+		handleSeriesError(ctx, "Failed to generate series report", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusOK, "Series report generated successfully", report)
+}
+
+func handleSeriesError(ctx *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, utils.ErrInvalidRRule), errors.Is(err, service.ErrInvalidOrganizer):
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, message, err)
+	case errors.Is(err, service.ErrNotEventOrganizer):
+		helper.SendErrorResponse(ctx, http.StatusForbidden, message, err)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		helper.SendErrorResponse(ctx, http.StatusNotFound, message, err)
+	default:
+		helper.SendErrorResponse(ctx, http.StatusInternalServerError, message, err)
+	}
+}
@@ -1,14 +1,18 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
 	"github.com/Ayyasy123/dibimbing-take-home-test/helper"
+	"github.com/Ayyasy123/dibimbing-take-home-test/repository"
 	"github.com/Ayyasy123/dibimbing-take-home-test/service"
+	"github.com/Ayyasy123/dibimbing-take-home-test/utils"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type TicketController struct {
@@ -20,6 +24,12 @@ func NewTicketController(ticketService service.TicketService) *TicketController
 }
 
 func (c *TicketController) CreateTicket(ctx *gin.Context) {
+	// Order selalu dibuat atas nama user yang login
+	userID, _, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
 	var req entity.CreateTicketReq
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
@@ -32,13 +42,114 @@ func (c *TicketController) CreateTicket(ctx *gin.Context) {
 		return
 	}
 
-	ticketRes, err := c.ticketService.CreateTicket(&req)
+	orderRes, err := c.ticketService.CreateTicket(&req, userID)
 	if err != nil {
+		// Event sudah habis, arahkan pembeli ke waitlist
+		if errors.Is(err, repository.ErrNoAvailableTickets) {
+			helper.SendErrorResponse(ctx, http.StatusConflict, "Event is sold out, join the waitlist via POST /events/:id/waitlist", err)
+			return
+		}
+		if errors.Is(err, repository.ErrEventNotOnSale) {
+			helper.SendErrorResponse(ctx, http.StatusConflict, "Tickets for this event are no longer on sale", err)
+			return
+		}
+		if errors.Is(err, repository.ErrInvalidPromoCode) {
+			helper.SendErrorResponse(ctx, http.StatusBadRequest, "Promo code cannot be used", err)
+			return
+		}
 		helper.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to create ticket", err)
 		return
 	}
 
-	helper.SendSuccessResponse(ctx, http.StatusCreated, "Ticket created successfully", ticketRes)
+	helper.SendSuccessResponse(ctx, http.StatusCreated, "Ticket created successfully", orderRes)
+}
+
+func (c *TicketController) CreateHold(ctx *gin.Context) {
+	// Hold selalu dibuat atas nama user yang login
+	userID, _, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req entity.CreateHoldReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	// Perform validation
+	if err := helper.ValidateStruct(&req); err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	holdRes, err := c.ticketService.CreateHold(&req, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrEventNotOnSale) {
+			helper.SendErrorResponse(ctx, http.StatusConflict, "Tickets for this event are no longer on sale", err)
+			return
+		}
+		helper.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to hold tickets", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusCreated, "Tickets held successfully", holdRes)
+}
+
+func (c *TicketController) ConfirmHold(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid hold ID", err)
+		return
+	}
+
+	// ambil user id dari token
+	userId, exists := ctx.Get("user_id")
+	if !exists {
+		helper.SendErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized", errors.New("user id not found in token"))
+		return
+	}
+
+	orderRes, err := c.ticketService.ConfirmHold(id, userId.(int))
+	if err != nil {
+		if errors.Is(err, repository.ErrEventNotOnSale) {
+			helper.SendErrorResponse(ctx, http.StatusConflict, "Tickets for this event are no longer on sale", err)
+			return
+		}
+		helper.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to confirm hold", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusCreated, "Hold confirmed successfully", orderRes)
+}
+
+func (c *TicketController) FindOrderByID(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid order ID", err)
+		return
+	}
+
+	userID, role, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	orderRes, err := c.ticketService.FindOrderByID(id, userID, role)
+	if err != nil {
+		if errors.Is(err, service.ErrNotOrderOwner) {
+			helper.SendErrorResponse(ctx, http.StatusForbidden, "Failed to retrieve order", err)
+			return
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			helper.SendErrorResponse(ctx, http.StatusNotFound, "Order not found", err)
+			return
+		}
+		helper.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve order", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusOK, "Order retrieved successfully", orderRes)
 }
 
 func (c *TicketController) FindTicketByID(ctx *gin.Context) {
@@ -109,6 +220,14 @@ func (c *TicketController) UpdateTicket(ctx *gin.Context) {
 
 	err = c.ticketService.UpdateTicket(id, &req)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidStatusTransition) {
+			helper.SendErrorResponse(ctx, http.StatusConflict, "Failed to update ticket", err)
+			return
+		}
+		if errors.Is(err, service.ErrInvalidTicketStatus) {
+			helper.SendErrorResponse(ctx, http.StatusBadRequest, "Failed to update ticket", err)
+			return
+		}
 		helper.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to update ticket", err)
 		return
 	}
@@ -178,7 +297,13 @@ func (c *TicketController) GetTicketSalesReport(ctx *gin.Context) {
 		}
 	}
 
-	ticketsRes, err := c.ticketService.GetTicketReport(startDate, endDate)
+	userID, role, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	// Organizer hanya melihat penjualan event miliknya
+	ticketsRes, err := c.ticketService.GetTicketReport(startDate, endDate, userID, role)
 	if err != nil {
 		helper.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve ticket sales report", err)
 		return
@@ -222,8 +347,13 @@ func (c *TicketController) GetTicketsSoldPerEvent(ctx *gin.Context) {
 		}
 	}
 
+	userID, role, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
 	// Panggil service dengan parameter yang sesuai
-	ticketsSoldPerEvent, err := c.ticketService.GetTicketsSoldPerEvent(startDate, endDate, eventID)
+	ticketsSoldPerEvent, err := c.ticketService.GetTicketsSoldPerEvent(startDate, endDate, eventID, userID, role)
 	if err != nil {
 		helper.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve tickets sold per event", err)
 		return
@@ -240,12 +370,107 @@ func (c *TicketController) CancelTicket(ctx *gin.Context) {
 		return
 	}
 
+	userID, role, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	// Alasan pembatalan bersifat opsional
+	var req entity.CancelTicketReq
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+			return
+		}
+	}
+
 	// Panggil service untuk membatalkan tiket
-	if err := c.ticketService.CancelTicket(id); err != nil {
+	refundRes, err := c.ticketService.CancelTicket(id, userID, role, &req)
+	if err != nil {
+		if errors.Is(err, service.ErrNotTicketOwner) {
+			helper.SendErrorResponse(ctx, http.StatusForbidden, "Failed to cancel ticket", err)
+			return
+		}
+		if errors.Is(err, service.ErrInvalidStatusTransition) || errors.Is(err, repository.ErrTicketAlreadyCheckedIn) ||
+			errors.Is(err, repository.ErrTicketNotRefundable) {
+			helper.SendErrorResponse(ctx, http.StatusConflict, "Failed to cancel ticket", err)
+			return
+		}
 		helper.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to cancel ticket", err)
 		return
 	}
 
-	// Kirim response sukses
-	helper.SendSuccessResponse(ctx, http.StatusOK, "Ticket cancelled successfully", nil)
+	// Kirim response sukses beserta refund yang dibuat
+	helper.SendSuccessResponse(ctx, http.StatusOK, "Ticket cancelled successfully", refundRes)
+}
+
+func (c *TicketController) GetTicketQR(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid ticket ID", err)
+		return
+	}
+
+	// ambil user id dan role dari token
+	userId, exists := ctx.Get("user_id")
+	if !exists {
+		helper.SendErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized", errors.New("user id not found in token"))
+		return
+	}
+	role, _ := ctx.Get("role")
+	roleStr, _ := role.(string)
+
+	png, err := c.ticketService.GenerateTicketQR(id, userId.(int), roleStr)
+	if err != nil {
+		if errors.Is(err, service.ErrNotTicketOwner) {
+			helper.SendErrorResponse(ctx, http.StatusForbidden, "Ticket does not belong to this user", err)
+			return
+		}
+		if errors.Is(err, service.ErrTicketNotValidForEntry) {
+			helper.SendErrorResponse(ctx, http.StatusConflict, "Ticket is not valid for entry", err)
+			return
+		}
+		helper.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to generate ticket QR code", err)
+		return
+	}
+
+	ctx.Data(http.StatusOK, "image/png", png)
+}
+
+func (c *TicketController) CheckIn(ctx *gin.Context) {
+	var req entity.CheckInReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	// Perform validation
+	if err := helper.ValidateStruct(&req); err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	// ambil user id staff dari token
+	userId, exists := ctx.Get("user_id")
+	if !exists {
+		helper.SendErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized", errors.New("user id not found in token"))
+		return
+	}
+
+	checkInRes, err := c.ticketService.CheckIn(&req, userId.(int))
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrInvalidTicketCode), errors.Is(err, gorm.ErrRecordNotFound):
+			helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid ticket code", err)
+		case errors.Is(err, service.ErrTicketNotValidForEntry):
+			helper.SendErrorResponse(ctx, http.StatusConflict, "Ticket is not valid for entry", err)
+		case errors.Is(err, repository.ErrTicketAlreadyCheckedIn):
+			helper.SendErrorResponse(ctx, http.StatusConflict, "Ticket has already been used", err)
+		default:
+			helper.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to check in ticket", err)
+		}
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusOK, "Ticket checked in successfully", checkInRes)
 }
@@ -0,0 +1,148 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"github.com/Ayyasy123/dibimbing-take-home-test/helper"
+	"github.com/Ayyasy123/dibimbing-take-home-test/repository"
+	"github.com/Ayyasy123/dibimbing-take-home-test/service"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type TransferController struct {
+	transferService service.TransferService
+}
+
+func NewTransferController(transferService service.TransferService) *TransferController {
+	return &TransferController{transferService: transferService}
+}
+
+func (c *TransferController) CreateTransfer(ctx *gin.Context) {
+	ticketID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid ticket ID", err)
+		return
+	}
+
+	// ambil user id dari token
+	userId, exists := ctx.Get("user_id")
+	if !exists {
+		helper.SendErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized", errors.New("user id not found in token"))
+		return
+	}
+
+	var req entity.CreateTransferReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	// Perform validation
+	if err := helper.ValidateStruct(&req); err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	transferRes, err := c.transferService.CreateTransfer(ticketID, userId.(int), &req)
+	if err != nil {
+		handleTransferError(ctx, "Failed to transfer ticket", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusCreated, "Ticket transfer offered successfully", transferRes)
+}
+
+func (c *TransferController) FindTransferHistory(ctx *gin.Context) {
+	ticketID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid ticket ID", err)
+		return
+	}
+
+	// ambil user id dan role dari token
+	userId, exists := ctx.Get("user_id")
+	if !exists {
+		helper.SendErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized", errors.New("user id not found in token"))
+		return
+	}
+	role, _ := ctx.Get("role")
+	roleStr, _ := role.(string)
+
+	transfersRes, err := c.transferService.FindTransferHistory(ticketID, userId.(int), roleStr)
+	if err != nil {
+		handleTransferError(ctx, "Failed to retrieve transfer history", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusOK, "Transfer history retrieved successfully", transfersRes)
+}
+
+func (c *TransferController) FindIncomingTransfers(ctx *gin.Context) {
+	// ambil user id dari token
+	userId, exists := ctx.Get("user_id")
+	if !exists {
+		helper.SendErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized", errors.New("user id not found in token"))
+		return
+	}
+
+	transfersRes, err := c.transferService.FindIncomingTransfers(userId.(int))
+	if err != nil {
+		handleTransferError(ctx, "Failed to retrieve incoming transfers", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusOK, "Incoming transfers retrieved successfully", transfersRes)
+}
+
+func (c *TransferController) AcceptTransfer(ctx *gin.Context) {
+	c.respondToTransfer(ctx, c.transferService.AcceptTransfer, "Ticket transfer accepted successfully", "Failed to accept ticket transfer")
+}
+
+func (c *TransferController) DeclineTransfer(ctx *gin.Context) {
+	c.respondToTransfer(ctx, c.transferService.DeclineTransfer, "Ticket transfer declined successfully", "Failed to decline ticket transfer")
+}
+
+func (c *TransferController) CancelTransfer(ctx *gin.Context) {
+	c.respondToTransfer(ctx, c.transferService.CancelTransfer, "Ticket transfer cancelled successfully", "Failed to cancel ticket transfer")
+}
+
+// respondToTransfer menangani accept, decline dan cancel yang sama-sama hanya
+// membutuhkan ID transfer dan user id dari token
+func (c *TransferController) respondToTransfer(ctx *gin.Context, action func(id, userID int) error, successMessage, errorMessage string) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid transfer ID", err)
+		return
+	}
+
+	// ambil user id dari token
+	userId, exists := ctx.Get("user_id")
+	if !exists {
+		helper.SendErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized", errors.New("user id not found in token"))
+		return
+	}
+
+	if err := action(id, userId.(int)); err != nil {
+		handleTransferError(ctx, errorMessage, err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusOK, successMessage, nil)
+}
+
+func handleTransferError(ctx *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, service.ErrNotTicketOwner), errors.Is(err, service.ErrNotTransferRecipient):
+		helper.SendErrorResponse(ctx, http.StatusForbidden, message, err)
+	case errors.Is(err, repository.ErrTransferNotPending):
+		helper.SendErrorResponse(ctx, http.StatusConflict, message, err)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		helper.SendErrorResponse(ctx, http.StatusNotFound, message, err)
+	default:
+		helper.SendErrorResponse(ctx, http.StatusInternalServerError, message, err)
+	}
+}
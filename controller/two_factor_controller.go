@@ -0,0 +1,146 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"github.com/Ayyasy123/dibimbing-take-home-test/helper"
+	"github.com/Ayyasy123/dibimbing-take-home-test/repository"
+	"github.com/Ayyasy123/dibimbing-take-home-test/service"
+	"github.com/gin-gonic/gin"
+)
+
+func (c *UserController) GetTwoFactorStatus(ctx *gin.Context) {
+	userID, _, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	status, err := c.userService.GetTwoFactorStatus(userID)
+	if err != nil {
+		handleTwoFactorError(ctx, "Failed to retrieve two-factor status", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusOK, "Two-factor status retrieved successfully", status)
+}
+
+func (c *UserController) SetupTwoFactor(ctx *gin.Context) {
+	userID, _, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	setup, err := c.userService.SetupTwoFactor(userID)
+	if err != nil {
+		handleTwoFactorError(ctx, "Failed to set up two-factor authentication", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusOK, "Scan the otpauth URI with an authenticator app, then confirm with a code", setup)
+}
+
+func (c *UserController) ConfirmTwoFactor(ctx *gin.Context) {
+	userID, _, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req entity.TwoFactorCodeReq
+	if !bindTwoFactorReq(ctx, &req) {
+		return
+	}
+
+	codes, err := c.userService.ConfirmTwoFactor(userID, &req)
+	if err != nil {
+		handleTwoFactorError(ctx, "Failed to enable two-factor authentication", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusOK, "Two-factor authentication enabled, store the recovery codes somewhere safe", codes)
+}
+
+func (c *UserController) VerifyTwoFactor(ctx *gin.Context) {
+	var req entity.TwoFactorVerifyReq
+	if !bindTwoFactorReq(ctx, &req) {
+		return
+	}
+
+	userRes, err := c.userService.VerifyLoginChallenge(&req)
+	if err != nil {
+		handleTwoFactorError(ctx, "Failed to login", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusOK, "Login successful", userRes)
+}
+
+func (c *UserController) DisableTwoFactor(ctx *gin.Context) {
+	userID, _, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req entity.TwoFactorCodeReq
+	if !bindTwoFactorReq(ctx, &req) {
+		return
+	}
+
+	if err := c.userService.DisableTwoFactor(userID, &req); err != nil {
+		handleTwoFactorError(ctx, "Failed to disable two-factor authentication", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusOK, "Two-factor authentication disabled", nil)
+}
+
+func (c *UserController) RegenerateRecoveryCodes(ctx *gin.Context) {
+	userID, _, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req entity.TwoFactorCodeReq
+	if !bindTwoFactorReq(ctx, &req) {
+		return
+	}
+
+	codes, err := c.userService.RegenerateRecoveryCodes(userID, &req)
+	if err != nil {
+		handleTwoFactorError(ctx, "Failed to regenerate recovery codes", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusOK, "Recovery codes regenerated, the previous codes no longer work", codes)
+}
+
+func bindTwoFactorReq(ctx *gin.Context, req interface{}) bool {
+	if err := ctx.ShouldBindJSON(req); err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return false
+	}
+
+	// Perform validation
+	if err := helper.ValidateStruct(req); err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return false
+	}
+
+	return true
+}
+
+func handleTwoFactorError(ctx *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidTwoFactorCode), errors.Is(err, repository.ErrInvalidAccountToken):
+		helper.SendErrorResponse(ctx, http.StatusUnauthorized, message, err)
+	case errors.Is(err, service.ErrTwoFactorAlreadyEnabled):
+		helper.SendErrorResponse(ctx, http.StatusConflict, message, err)
+	case errors.Is(err, service.ErrTwoFactorNotEnabled), errors.Is(err, service.ErrTwoFactorNotSetup):
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, message, err)
+	case errors.Is(err, service.ErrTwoFactorRequired):
+		helper.SendErrorResponse(ctx, http.StatusForbidden, message, err)
+	default:
+		helper.SendErrorResponse(ctx, http.StatusInternalServerError, message, err)
+	}
+}
@@ -1,14 +1,18 @@
 package controller
 
 import (
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
 	"github.com/Ayyasy123/dibimbing-take-home-test/helper"
+	"github.com/Ayyasy123/dibimbing-take-home-test/repository"
 	"github.com/Ayyasy123/dibimbing-take-home-test/service"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type UserController struct {
@@ -60,6 +64,11 @@ func (c *UserController) LoginUser(ctx *gin.Context) {
 		return
 	}
 
+	if userRes.ChallengeToken != "" {
+		helper.SendSuccessResponse(ctx, http.StatusOK, "Two-factor code required, send it with the challenge token to /auth/2fa/verify", userRes)
+		return
+	}
+
 	helper.SendSuccessResponse(ctx, http.StatusOK, "Login successful", userRes)
 }
 
@@ -111,6 +120,11 @@ func (c *UserController) FindAllUsers(ctx *gin.Context) {
 }
 
 func (c *UserController) UpdateUser(ctx *gin.Context) {
+	userID, role, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
 	id, err := strconv.Atoi(ctx.Param("id"))
 	if err != nil {
 		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid user ID", err)
@@ -129,8 +143,20 @@ func (c *UserController) UpdateUser(ctx *gin.Context) {
 		return
 	}
 
-	err = c.userService.UpdateUser(id, &req)
+	err = c.userService.UpdateUser(id, &req, userID, role)
 	if err != nil {
+		if errors.Is(err, service.ErrNotAccountOwner) || errors.Is(err, service.ErrRoleChangeNotAllowed) {
+			helper.SendErrorResponse(ctx, http.StatusForbidden, "Failed to update user", err)
+			return
+		}
+		if errors.Is(err, service.ErrEmailAlreadyExists) {
+			helper.SendErrorResponse(ctx, http.StatusConflict, "Failed to update user", err)
+			return
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			helper.SendErrorResponse(ctx, http.StatusNotFound, "Failed to update user", err)
+			return
+		}
 		helper.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to update user", err)
 		return
 	}
@@ -154,28 +180,6 @@ func (c *UserController) DeleteUser(ctx *gin.Context) {
 	helper.SendSuccessResponse(ctx, http.StatusOK, "User deleted successfully", nil)
 }
 
-func (c *UserController) RegisterAsAdmin(ctx *gin.Context) {
-	var req entity.RegisterReq
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
-		return
-	}
-
-	// Perform validation
-	if err := helper.ValidateStruct(&req); err != nil {
-		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
-		return
-	}
-
-	admin, err := c.userService.RegisterAsAdmin(&req)
-	if err != nil {
-		helper.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to register as admin", err)
-		return
-	}
-
-	helper.SendSuccessResponse(ctx, http.StatusCreated, "Admin registered successfully", admin)
-}
-
 func (c *UserController) GetUserReport(ctx *gin.Context) {
 	// Ambil start_date dan end_date dari query string (opsional)
 	startDateStr := ctx.Query("start_date")
@@ -217,3 +221,139 @@ func (c *UserController) GetUserReport(ctx *gin.Context) {
 
 	helper.SendSuccessResponse(ctx, http.StatusOK, "User report generated successfully", report)
 }
+
+func (c *UserController) RefreshToken(ctx *gin.Context) {
+	var req entity.RefreshTokenReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	// Perform validation
+	if err := helper.ValidateStruct(&req); err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	tokenRes, err := c.userService.RefreshSession(req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidRefreshToken) || errors.Is(err, repository.ErrRefreshTokenReused) || errors.Is(err, gorm.ErrRecordNotFound) {
+			helper.SendErrorResponse(ctx, http.StatusUnauthorized, "Failed to refresh token", err)
+			return
+		}
+		helper.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to refresh token", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusOK, "Token refreshed successfully", tokenRes)
+}
+
+func (c *UserController) Logout(ctx *gin.Context) {
+	userID, _, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	// Body boleh kosong jika hanya access token yang ingin dicabut
+	var req entity.LogoutReq
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	err := c.userService.Logout(userID, ctx.GetString("jti"), ctx.GetTime("token_expires_at"), &req)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidRefreshToken) {
+			helper.SendErrorResponse(ctx, http.StatusBadRequest, "Failed to logout", err)
+			return
+		}
+		helper.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to logout", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusOK, "Logged out successfully", nil)
+}
+
+func (c *UserController) ForgotPassword(ctx *gin.Context) {
+	var req entity.ForgotPasswordReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	// Perform validation
+	if err := helper.ValidateStruct(&req); err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if err := c.userService.ForgotPassword(&req); err != nil {
+		helper.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to send password reset email", err)
+		return
+	}
+
+	// Respons selalu sama baik email terdaftar maupun tidak
+	helper.SendSuccessResponse(ctx, http.StatusOK, "If the email is registered, a password reset link has been sent", nil)
+}
+
+func (c *UserController) ResetPassword(ctx *gin.Context) {
+	var req entity.ResetPasswordReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	// Perform validation
+	if err := helper.ValidateStruct(&req); err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if err := c.userService.ResetPassword(&req); err != nil {
+		if errors.Is(err, repository.ErrInvalidAccountToken) {
+			helper.SendErrorResponse(ctx, http.StatusBadRequest, "Failed to reset password", err)
+			return
+		}
+		helper.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to reset password", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusOK, "Password reset successfully, please login again", nil)
+}
+
+func (c *UserController) VerifyEmail(ctx *gin.Context) {
+	token := ctx.Query("token")
+	if token == "" {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Failed to verify email", errors.New("token is required"))
+		return
+	}
+
+	if err := c.userService.VerifyEmail(token); err != nil {
+		if errors.Is(err, repository.ErrInvalidAccountToken) {
+			helper.SendErrorResponse(ctx, http.StatusBadRequest, "Failed to verify email", err)
+			return
+		}
+		helper.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to verify email", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusOK, "Email verified successfully", nil)
+}
+
+func (c *UserController) ResendVerificationEmail(ctx *gin.Context) {
+	userID, _, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.userService.ResendVerificationEmail(userID); err != nil {
+		if errors.Is(err, service.ErrEmailAlreadyVerified) {
+			helper.SendErrorResponse(ctx, http.StatusConflict, "Failed to send verification email", err)
+			return
+		}
+		helper.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to send verification email", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusOK, "Verification email sent", nil)
+}
@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"github.com/Ayyasy123/dibimbing-take-home-test/helper"
+	"github.com/Ayyasy123/dibimbing-take-home-test/service"
+	"github.com/gin-gonic/gin"
+)
+
+type VenueController struct {
+	venueService service.VenueService
+}
+
+func NewVenueController(venueService service.VenueService) *VenueController {
+	return &VenueController{venueService: venueService}
+}
+
+func (c *VenueController) CreateVenue(ctx *gin.Context) {
+	var req entity.CreateVenueReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	// Perform validation
+	if err := helper.ValidateStruct(&req); err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	venue, err := c.venueService.CreateVenue(&req)
+	if err != nil {
+		helper.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to create venue", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusCreated, "Venue created successfully", venue)
+}
+
+func (c *VenueController) FindVenueByID(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid venue ID", err)
+		return
+	}
+
+	venue, err := c.venueService.FindVenueByID(id)
+	if err != nil {
+		helper.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve venue", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusOK, "Venue retrieved successfully", venue)
+}
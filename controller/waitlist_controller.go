@@ -0,0 +1,95 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"github.com/Ayyasy123/dibimbing-take-home-test/helper"
+	"github.com/Ayyasy123/dibimbing-take-home-test/repository"
+	"github.com/Ayyasy123/dibimbing-take-home-test/service"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type WaitlistController struct {
+	waitlistService service.WaitlistService
+}
+
+func NewWaitlistController(waitlistService service.WaitlistService) *WaitlistController {
+	return &WaitlistController{waitlistService: waitlistService}
+}
+
+func (c *WaitlistController) JoinWaitlist(ctx *gin.Context) {
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid event ID", err)
+		return
+	}
+
+	// ambil user id dari token
+	userId, exists := ctx.Get("user_id")
+	if !exists {
+		helper.SendErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized", errors.New("user id not found in token"))
+		return
+	}
+
+	// Body bersifat opsional, default satu tiket tanpa tier
+	var req entity.JoinWaitlistReq
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+			return
+		}
+	}
+
+	// Perform validation
+	if err := helper.ValidateStruct(&req); err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	waitlistRes, err := c.waitlistService.JoinWaitlist(eventID, userId.(int), &req)
+	if err != nil {
+		if errors.Is(err, repository.ErrEventNotOnSale) {
+			helper.SendErrorResponse(ctx, http.StatusConflict, "Tickets for this event are no longer on sale", err)
+			return
+		}
+		if errors.Is(err, service.ErrInvalidWaitlistTier) {
+			helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid ticket tier", err)
+			return
+		}
+		helper.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to join waitlist", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusCreated, "Joined waitlist successfully", waitlistRes)
+}
+
+func (c *WaitlistController) FindMyWaitlistEntry(ctx *gin.Context) {
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		helper.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid event ID", err)
+		return
+	}
+
+	// ambil user id dari token
+	userId, exists := ctx.Get("user_id")
+	if !exists {
+		helper.SendErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized", errors.New("user id not found in token"))
+		return
+	}
+
+	waitlistRes, err := c.waitlistService.FindMyWaitlistEntry(eventID, userId.(int))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			helper.SendErrorResponse(ctx, http.StatusNotFound, "User is not on the waitlist for this event", err)
+			return
+		}
+		helper.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve waitlist entry", err)
+		return
+	}
+
+	helper.SendSuccessResponse(ctx, http.StatusOK, "Waitlist entry retrieved successfully", waitlistRes)
+}
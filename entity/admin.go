@@ -0,0 +1,54 @@
+package entity
+
+import "time"
+
+// Asal perubahan role yang dicatat di RoleGrant
+const (
+	RoleGrantSourceAdmin      = "admin"      // Admin membuat akun admin atau mengubah role user
+	RoleGrantSourceBootstrap  = "bootstrap"  // Admin pertama dibuat dengan bootstrap token
+	RoleGrantSourceInvitation = "invitation" // User menerima undangan
+)
+
+// RoleGrant adalah catatan audit setiap kali user mendapatkan role baru. Baris tidak
+// pernah diubah atau dihapus.
+type RoleGrant struct {
+	ID           int       `json:"id" gorm:"primary_key,auto_increment" `
+	UserID       int       `json:"user_id" gorm:"not null;index" `
+	OldRole      string    `json:"old_role" ` // Kosong untuk akun baru
+	NewRole      string    `json:"new_role" gorm:"not null" `
+	GrantedBy    *int      `json:"granted_by" ` // Admin yang memberi role, kosong untuk bootstrap
+	Source       string    `json:"source" gorm:"type:varchar(32);not null;index" `
+	InvitationID *int      `json:"invitation_id" `
+	CreatedAt    time.Time `json:"created_at" `
+}
+
+// Invitation adalah undangan untuk menjadi admin, organizer atau staff. Link di email
+// berisi token yang ditandatangani kunci JWT dan hanya bisa dipakai sekali.
+type Invitation struct {
+	ID             int        `json:"id" gorm:"primary_key,auto_increment" `
+	Email          string     `json:"email" gorm:"not null;index" `
+	Role           string     `json:"role" gorm:"not null" `
+	InvitedBy      int        `json:"invited_by" gorm:"not null" `
+	ExpiresAt      time.Time  `json:"expires_at" `
+	AcceptedAt     *time.Time `json:"accepted_at" `
+	AcceptedUserID *int       `json:"accepted_user_id" `
+	RevokedAt      *time.Time `json:"revoked_at" `
+	CreatedAt      time.Time  `json:"created_at" `
+}
+
+type CreateInvitationReq struct {
+	Email string `json:"email" validate:"required,email"`
+	Role  string `json:"role" validate:"required,oneof=admin organizer staff"`
+}
+
+// AcceptInvitationReq membuat akun baru. Jika email undangan sudah terdaftar, password
+// akun tersebut harus dikirim dan name diabaikan.
+type AcceptInvitationReq struct {
+	Token    string `json:"token" validate:"required"`
+	Name     string `json:"name"`
+	Password string `json:"password" validate:"required,min=8"`
+}
+
+type UpdateRoleReq struct {
+	Role string `json:"role" validate:"required,oneof=user admin staff organizer"`
+}
@@ -0,0 +1,12 @@
+package entity
+
+// AuthPolicy mengatur aturan akun yang bisa diubah lewat environment
+type AuthPolicy struct {
+	BaseURL                  string // URL publik API, dipakai untuk link verifikasi email
+	PasswordResetURL         string // Halaman frontend reset password, token ditambahkan sebagai query
+	InvitationURL            string // Halaman frontend untuk menerima undangan admin
+	BootstrapToken           string // Token sekali pakai untuk membuat admin pertama, kosong berarti nonaktif
+	RequireEmailVerification bool   // User harus memverifikasi email sebelum membeli tiket
+	RequireAdminTwoFactor    bool   // Endpoint admin hanya bisa diakses dari login yang memakai 2FA
+	TOTPIssuer               string // Nama aplikasi yang tampil di aplikasi authenticator
+}
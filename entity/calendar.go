@@ -0,0 +1,5 @@
+package entity
+
+type CalendarSubscriptionRes struct {
+	URL string `json:"url"` // Tambahkan ke aplikasi kalender sebagai kalender langganan
+}
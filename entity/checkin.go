@@ -0,0 +1,82 @@
+package entity
+
+import "time"
+
+// CheckInScan mencatat setiap pemindaian tiket, termasuk yang dilakukan scanner
+// secara offline dan diunggah belakangan.
+type CheckInScan struct {
+	ID            int       `json:"id" gorm:"primary_key,auto_increment" `
+	EventID       int       `json:"event_id" gorm:"not null;index" `
+	TicketID      int       `json:"ticket_id" gorm:"index" ` // 0 jika kode tidak dikenali
+	Code          string    `json:"-" gorm:"type:varchar(128)" `
+	Gate          string    `json:"gate" `
+	DeviceID      string    `json:"device_id" gorm:"type:varchar(100);index" `
+	BundleVersion int64     `json:"bundle_version" `
+	ScannedAt     time.Time `json:"scanned_at" `
+	UploadedBy    int       `json:"uploaded_by" `
+	Result        string    `json:"result" ` // Accepted, Duplicate, Conflict, Invalid
+	Note          string    `json:"note" `
+	CreatedAt     time.Time `json:"created_at" `
+}
+
+// CheckInPolicy mengatur sejak kapan scan offline diterima
+type CheckInPolicy struct {
+	DoorsOpenBefore time.Duration // Pintu dibuka sejak durasi ini sebelum event dimulai
+}
+
+type BundleTicket struct {
+	TicketID    int    `json:"ticket_id"`
+	Code        string `json:"code"`
+	Status      string `json:"status"`
+	HolderName  string `json:"holder_name"`
+	SeatID      int    `json:"seat_id,omitempty"`
+	CheckedInAt string `json:"checked_in_at,omitempty"`
+}
+
+// CheckInBundle adalah daftar kode tiket sebuah event untuk scanner yang bekerja offline.
+// Version berubah setiap kali ada tiket event yang berubah.
+type CheckInBundle struct {
+	EventID     int            `json:"event_id"`
+	EventName   string         `json:"event_name"`
+	Version     int64          `json:"version"`
+	GeneratedAt string         `json:"generated_at"`
+	Tickets     []BundleTicket `json:"tickets"`
+}
+
+// SignedCheckInBundle ditandatangani dengan kunci JWT yang aktif. Scanner memverifikasi
+// signature dengan kunci publik dari /.well-known/jwks.json sesuai key_id.
+type SignedCheckInBundle struct {
+	Bundle    CheckInBundle `json:"bundle"`
+	KeyID     string        `json:"key_id"`
+	Algorithm string        `json:"alg"`       // RS256 atau EdDSA
+	Signature string        `json:"signature"` // Signature base64url dari JSON field bundle
+}
+
+type OfflineScanReq struct {
+	Code      string `json:"code" validate:"required"`
+	Gate      string `json:"gate" validate:"required"`
+	ScannedAt string `json:"scanned_at" validate:"required"` // Format RFC 3339
+}
+
+type UploadScansReq struct {
+	DeviceID          string           `json:"device_id" validate:"required,max=100"`
+	BundleVersion     int64            `json:"bundle_version"`
+	BundleGeneratedAt string           `json:"bundle_generated_at" validate:"required"` // generated_at dari bundle yang dipakai scanner
+	Scans             []OfflineScanReq `json:"scans" validate:"required,min=1,dive"`
+}
+
+type ScanResultRes struct {
+	TicketID  int    `json:"ticket_id,omitempty"`
+	Gate      string `json:"gate"`
+	ScannedAt string `json:"scanned_at"`
+	Result    string `json:"result"`
+	Note      string `json:"note,omitempty"`
+}
+
+type UploadScansRes struct {
+	Accepted  int             `json:"accepted"`
+	Duplicate int             `json:"duplicate"`
+	Conflict  int             `json:"conflict"`
+	Invalid   int             `json:"invalid"`
+	Results   []ScanResultRes `json:"results"`
+}
@@ -3,30 +3,45 @@ package entity
 import "time"
 
 type Event struct {
-	ID                 int       `json:"id" gorm:"primary_key,auto_increment" `
-	Name               string    `json:"name"`
-	Description        string    `json:"description"`
-	Location           string    `json:"location"`
-	Date               time.Time `json:"date"`
-	Category           string    `json:"category"`
-	Capacity           int       `json:"capacity"`
-	Price              int       `json:"price"`
-	Status             string    `json:"status" gorm:"default:active"`
-	AvailableTickets   int       `json:"available_tickets"`
-	TicketAvailability string    `json:"ticket_availability"`
-	CreatedAt          time.Time `json:"created_at"`
-	UpdatedAt          time.Time `json:"updated_at"`
-	Tickets            []Ticket  `json:"tickets,omitempty" gorm:"foreignKey:EventID"`
+	ID                 int                `json:"id" gorm:"primary_key,auto_increment" `
+	Name               string             `json:"name"`
+	Description        string             `json:"description"`
+	Location           string             `json:"location"`
+	Date               time.Time          `json:"date"`
+	EndDate            time.Time          `json:"end_date"`                     // Setelah waktu ini event otomatis menjadi Selesai
+	TimeZone           string             `json:"time_zone" gorm:"default:UTC"` // Zona waktu IANA, misalnya Asia/Jakarta
+	Category           string             `json:"category"`
+	Capacity           int                `json:"capacity"`
+	Price              int                `json:"price"`
+	Status             EventStatus        `json:"status" gorm:"default:Aktif"`
+	AvailableTickets   int                `json:"available_tickets"`
+	TicketAvailability TicketAvailability `json:"ticket_availability" gorm:"default:Tersedia"`
+	MaxTicketsPerUser  int                `json:"max_tickets_per_user"`      // 0 berarti tidak ada batas
+	VenueID            int                `json:"venue_id"`                  // 0 berarti event tanpa seat map
+	SeriesID           int                `json:"series_id" gorm:"index"`    // 0 berarti event tunggal
+	OrganizerID        int                `json:"organizer_id" gorm:"index"` // 0 berarti hanya bisa dikelola admin
+	CreatedAt          time.Time          `json:"created_at"`
+	UpdatedAt          time.Time          `json:"updated_at"`
+	Tickets            []Ticket           `json:"tickets,omitempty" gorm:"foreignKey:EventID"`
+	Tiers              []TicketTier       `json:"tiers,omitempty" gorm:"foreignKey:EventID"`
 }
 
 type CreateEventReq struct {
 	Name        string `json:"name" validate:"required"`
 	Description string `json:"description" validate:"required"`
 	Location    string `json:"location" validate:"required"`
-	Date        string `json:"date" validate:"required"`
+	Date        string `json:"date" validate:"required"` // RFC 3339 atau YYYY-MM-DD
+	EndDate     string `json:"end_date"`                 // RFC 3339 atau YYYY-MM-DD, kosongkan jika event selesai di hari yang sama
+	TimeZone    string `json:"time_zone"`                // Zona waktu IANA, default UTC
 	Category    string `json:"category" validate:"required"`
 	Capacity    int    `json:"capacity" validate:"required,gte=0"`
 	Price       int    `json:"price" validate:"required,gte=0"`
+	// Batas pembelian tiket per user untuk event ini, 0 berarti tidak ada batas
+	MaxTicketsPerUser int `json:"max_tickets_per_user" validate:"gte=0"`
+	// Venue dengan seat map, kosongkan jika event tidak memakai kursi bernomor
+	VenueID int `json:"venue_id" validate:"gte=0"`
+	// Hanya dipakai admin untuk menunjuk organizer, event buatan organizer selalu menjadi miliknya
+	OrganizerID int `json:"organizer_id" validate:"gte=0"`
 }
 
 type UpdateEventReq struct {
@@ -34,12 +49,16 @@ type UpdateEventReq struct {
 	Description        string `json:"description"`
 	Location           string `json:"location"`
 	Date               string `json:"date"`
+	EndDate            string `json:"end_date"`
+	TimeZone           string `json:"time_zone"`
 	Category           string `json:"category"`
 	Capacity           int    `json:"capacity" validate:"gte=0"`
 	Price              int    `json:"price" validate:"gte=0"`
-	Status             string `json:"status"`
-	AvailableTickets   int    `json:"available_tickets"`
-	TicketAvailability string `json:"ticket_availability"`
+	Status             string `json:"status" validate:"omitempty,oneof=Aktif Berlangsung Selesai"` // Pembatalan lewat PATCH /events/:id/cancel
+	TicketAvailability string `json:"ticket_availability" validate:"omitempty,oneof=Tersedia Habis"`
+	MaxTicketsPerUser  int    `json:"max_tickets_per_user" validate:"gte=0"`
+	VenueID            int    `json:"venue_id" validate:"gte=0"`
+	OrganizerID        int    `json:"organizer_id" validate:"gte=0"` // Hanya admin yang bisa memindahkan event ke organizer lain
 }
 
 type EventRes struct {
@@ -48,12 +67,18 @@ type EventRes struct {
 	Description        string `json:"description"`
 	Location           string `json:"location"`
 	Date               string `json:"date"`
+	EndDate            string `json:"end_date"`
+	TimeZone           string `json:"time_zone"`
 	Category           string `json:"category"`
 	Capacity           int    `json:"capacity"`
 	Price              int    `json:"price"`
 	Status             string `json:"status"`
 	AvailableTickets   int    `json:"available_tickets"`
 	TicketAvailability string `json:"ticket_availability"`
+	MaxTicketsPerUser  int    `json:"max_tickets_per_user"`
+	VenueID            int    `json:"venue_id"`
+	SeriesID           int    `json:"series_id,omitempty"`
+	OrganizerID        int    `json:"organizer_id,omitempty"`
 	CreatedAt          string `json:"created_at"`
 	UpdatedAt          string `json:"updated_at"`
 }
@@ -0,0 +1,42 @@
+package entity
+
+import "time"
+
+type TicketHold struct {
+	ID        int       `json:"id" gorm:"primary_key,auto_increment" `
+	EventID   int       `json:"event_id" gorm:"not null" `
+	TierID    int       `json:"tier_id" `
+	UserID    int       `json:"user_id" gorm:"not null" `
+	OrderID   int       `json:"order_id" `
+	Quantity  int       `json:"quantity" gorm:"not null" `
+	UnitPrice int       `json:"unit_price" ` // Harga dikunci saat hold dibuat
+	Currency  string    `json:"currency" `
+	Status    string    `json:"status" gorm:"default:Reserved;index" ` // Reserved, Confirmed, Expired
+	ExpiresAt time.Time `json:"expires_at" gorm:"index" `
+	CreatedAt time.Time `json:"created_at" `
+	UpdatedAt time.Time `json:"updated_at" `
+}
+
+type CreateHoldReq struct {
+	EventID  int   `json:"event_id" validate:"required"`
+	TierID   int   `json:"tier_id"`
+	SeatIDs  []int `json:"seat_ids" validate:"max=10"`                 // Wajib diisi jika event memakai seat map
+	Quantity int   `json:"quantity" validate:"omitempty,gte=1,lte=10"` // Default 1, maksimal 10
+	Minutes  int   `json:"minutes" validate:"omitempty,gte=1,lte=30"`  // Lama hold dalam menit, default 10
+}
+
+type HoldRes struct {
+	ID        int    `json:"id"`
+	EventID   int    `json:"event_id"`
+	TierID    int    `json:"tier_id"`
+	UserID    int    `json:"user_id"`
+	OrderID   int    `json:"order_id"`
+	Quantity  int    `json:"quantity"`
+	UnitPrice int    `json:"unit_price"`
+	Currency  string `json:"currency"`
+	Status    string `json:"status"`
+	SeatIDs   []int  `json:"seat_ids,omitempty"`
+	ExpiresAt string `json:"expires_at"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
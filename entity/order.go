@@ -0,0 +1,38 @@
+package entity
+
+import "time"
+
+type Order struct {
+	ID              int          `json:"id" gorm:"primary_key,auto_increment" `
+	UserID          int          `json:"user_id" gorm:"not null" `
+	EventID         int          `json:"event_id" gorm:"not null" `
+	TierID          int          `json:"tier_id" `
+	Quantity        int          `json:"quantity" gorm:"not null" `
+	Status          TicketStatus `json:"status" gorm:"default:Dibeli" `
+	TotalPrice      int          `json:"total_price" `
+	Discount        int          `json:"discount" ` // Total potongan dari promo code
+	PromoCodeID     int          `json:"promo_code_id" `
+	ResaleListingID int          `json:"resale_listing_id" gorm:"index" ` // Diisi jika order membeli tiket dari resale marketplace
+	Currency        string       `json:"currency" `
+	CreatedAt       time.Time    `json:"created_at" `
+	UpdatedAt       time.Time    `json:"updated_at" `
+	Tickets         []Ticket     `json:"tickets,omitempty" gorm:"foreignKey:OrderID" `
+}
+
+type OrderRes struct {
+	ID              int         `json:"id"`
+	UserID          int         `json:"user_id"`
+	EventID         int         `json:"event_id"`
+	TierID          int         `json:"tier_id"`
+	Quantity        int         `json:"quantity"`
+	Status          string      `json:"status"`
+	TotalPrice      int         `json:"total_price"`
+	Discount        int         `json:"discount"`
+	PromoCodeID     int         `json:"promo_code_id,omitempty"`
+	ResaleListingID int         `json:"resale_listing_id,omitempty"`
+	Currency        string      `json:"currency"`
+	Tickets         []TicketRes `json:"tickets"`
+	Payment         *PaymentRes `json:"payment,omitempty"`
+	CreatedAt       string      `json:"created_at"`
+	UpdatedAt       string      `json:"updated_at"`
+}
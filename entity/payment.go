@@ -0,0 +1,32 @@
+package entity
+
+import "time"
+
+type Payment struct {
+	ID             int       `json:"id" gorm:"primary_key,auto_increment" `
+	OrderID        int       `json:"order_id" gorm:"not null;index" `
+	Provider       string    `json:"provider" `
+	ChargeID       string    `json:"charge_id" gorm:"index" ` // ID charge dari penyedia pembayaran
+	Amount         int       `json:"amount" `
+	RefundedAmount int       `json:"refunded_amount" `
+	Currency       string    `json:"currency" `
+	Status         string    `json:"status" gorm:"default:Pending;index" ` // Pending, Paid, Failed, Refunded
+	CheckoutURL    string    `json:"checkout_url" `
+	CreatedAt      time.Time `json:"created_at" `
+	UpdatedAt      time.Time `json:"updated_at" `
+}
+
+type PaymentRes struct {
+	ID             int    `json:"id"`
+	Provider       string `json:"provider"`
+	ChargeID       string `json:"charge_id"`
+	Amount         int    `json:"amount"`
+	RefundedAmount int    `json:"refunded_amount"`
+	Currency       string `json:"currency"`
+	Status         string `json:"status"`
+	CheckoutURL    string `json:"checkout_url,omitempty"`
+}
+
+type SimulatePaymentReq struct {
+	Event string `json:"event" validate:"required,oneof=charge.authorized charge.succeeded charge.failed"`
+}
@@ -0,0 +1,82 @@
+package entity
+
+import "time"
+
+type PromoCode struct {
+	ID            int        `json:"id" gorm:"primary_key,auto_increment" `
+	Code          string     `json:"code" gorm:"type:varchar(50);uniqueIndex;not null" `
+	DiscountType  string     `json:"discount_type" `         // percentage atau fixed
+	DiscountValue int        `json:"discount_value" `        // Persen potongan atau nominal potongan per tiket
+	EventID       int        `json:"event_id" gorm:"index" ` // 0 berarti berlaku untuk semua event
+	UsageLimit    int        `json:"usage_limit" `           // Batas jumlah order yang memakai kode ini, 0 berarti tidak ada batas
+	PerUserLimit  int        `json:"per_user_limit" `        // Batas pemakaian per user, 0 berarti tidak ada batas
+	UsedCount     int        `json:"used_count" `
+	ValidFrom     *time.Time `json:"valid_from" `
+	ValidUntil    *time.Time `json:"valid_until" `
+	CreatedAt     time.Time  `json:"created_at" `
+	UpdatedAt     time.Time  `json:"updated_at" `
+}
+
+// PromoRedemption mencatat setiap order yang memakai promo code
+type PromoRedemption struct {
+	ID          int       `json:"id" gorm:"primary_key,auto_increment" `
+	PromoCodeID int       `json:"promo_code_id" gorm:"not null;index" `
+	OrderID     int       `json:"order_id" gorm:"not null;uniqueIndex" `
+	UserID      int       `json:"user_id" gorm:"not null;index" `
+	EventID     int       `json:"event_id" gorm:"not null" `
+	Discount    int       `json:"discount" ` // Total potongan untuk seluruh tiket dalam order
+	CreatedAt   time.Time `json:"created_at" `
+}
+
+type CreatePromoCodeReq struct {
+	Code          string `json:"code" validate:"required,max=50"`
+	DiscountType  string `json:"discount_type" validate:"required,oneof=percentage fixed"`
+	DiscountValue int    `json:"discount_value" validate:"required,gte=1"`
+	EventID       int    `json:"event_id" validate:"gte=0"`
+	UsageLimit    int    `json:"usage_limit" validate:"gte=0"`
+	PerUserLimit  int    `json:"per_user_limit" validate:"gte=0"`
+	ValidFrom     string `json:"valid_from"`  // Format: 2006-01-02 15:04:05
+	ValidUntil    string `json:"valid_until"` // Format: 2006-01-02 15:04:05
+}
+
+type ValidatePromoCodeReq struct {
+	Code     string `json:"code" validate:"required"`
+	EventID  int    `json:"event_id" validate:"required"`
+	TierID   int    `json:"tier_id"`
+	Quantity int    `json:"quantity" validate:"omitempty,gte=1"`
+}
+
+type PromoCodeRes struct {
+	ID            int    `json:"id"`
+	Code          string `json:"code"`
+	DiscountType  string `json:"discount_type"`
+	DiscountValue int    `json:"discount_value"`
+	EventID       int    `json:"event_id"`
+	UsageLimit    int    `json:"usage_limit"`
+	PerUserLimit  int    `json:"per_user_limit"`
+	UsedCount     int    `json:"used_count"`
+	ValidFrom     string `json:"valid_from,omitempty"`
+	ValidUntil    string `json:"valid_until,omitempty"`
+	CreatedAt     string `json:"created_at"`
+	UpdatedAt     string `json:"updated_at"`
+}
+
+// PromoPreviewRes adalah perkiraan harga jika promo code dipakai, tanpa memesan tiket
+type PromoPreviewRes struct {
+	Code              string `json:"code"`
+	EventID           int    `json:"event_id"`
+	TierID            int    `json:"tier_id"`
+	Quantity          int    `json:"quantity"`
+	UnitPrice         int    `json:"unit_price"`
+	DiscountPerTicket int    `json:"discount_per_ticket"`
+	TotalDiscount     int    `json:"total_discount"`
+	TotalPrice        int    `json:"total_price"`
+	Currency          string `json:"currency"`
+}
+
+type PromoRedemptionSummary struct {
+	PromoCodeID      int    `json:"promo_code_id"`
+	Code             string `json:"code"`
+	TotalRedemptions int    `json:"total_redemptions"` // Jumlah order yang memakai promo code
+	TotalDiscount    int    `json:"total_discount"`    // Total potongan harga yang diberikan
+}
@@ -0,0 +1,61 @@
+package entity
+
+import "time"
+
+type Refund struct {
+	ID         int       `json:"id" gorm:"primary_key,auto_increment" `
+	TicketID   int       `json:"ticket_id" gorm:"not null;index" `
+	OrderID    int       `json:"order_id" gorm:"index" ` // Pembayaran order ini yang dikembalikan, 0 jika refund diproses manual
+	EventID    int       `json:"event_id" gorm:"not null;index" `
+	UserID     int       `json:"user_id" gorm:"not null;index" `
+	Amount     int       `json:"amount" `
+	Percentage int       `json:"percentage" ` // Persentase harga tiket yang dikembalikan
+	Currency   string    `json:"currency" `
+	Reason     string    `json:"reason" `
+	Status     string    `json:"status" gorm:"default:Pending" ` // Pending, Completed, Failed
+	CreatedAt  time.Time `json:"created_at" `
+	UpdatedAt  time.Time `json:"updated_at" `
+}
+
+// RefundRule berarti: pembatalan paling lambat DaysBeforeEvent hari sebelum event
+// mendapat pengembalian sebesar Percentage persen dari harga tiket.
+type RefundRule struct {
+	ID              int       `json:"id" gorm:"primary_key,auto_increment" `
+	EventID         int       `json:"event_id" gorm:"not null;index" `
+	DaysBeforeEvent int       `json:"days_before_event" `
+	Percentage      int       `json:"percentage" `
+	CreatedAt       time.Time `json:"created_at" `
+	UpdatedAt       time.Time `json:"updated_at" `
+}
+
+type CancelTicketReq struct {
+	Reason string `json:"reason"`
+}
+
+type RefundRuleReq struct {
+	DaysBeforeEvent int `json:"days_before_event" validate:"gte=0"`
+	Percentage      int `json:"percentage" validate:"gte=0,lte=100"`
+}
+
+type SetRefundPolicyReq struct {
+	Rules []RefundRuleReq `json:"rules" validate:"required,min=1,dive"`
+}
+
+type UpdateRefundReq struct {
+	Status string `json:"status" validate:"required,oneof=Completed Failed"`
+}
+
+type RefundRes struct {
+	ID         int    `json:"id"`
+	TicketID   int    `json:"ticket_id"`
+	OrderID    int    `json:"order_id"`
+	EventID    int    `json:"event_id"`
+	UserID     int    `json:"user_id"`
+	Amount     int    `json:"amount"`
+	Percentage int    `json:"percentage"`
+	Currency   string `json:"currency"`
+	Reason     string `json:"reason"`
+	Status     string `json:"status"`
+	CreatedAt  string `json:"created_at"`
+	UpdatedAt  string `json:"updated_at"`
+}
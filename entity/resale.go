@@ -0,0 +1,76 @@
+package entity
+
+import "time"
+
+type ResaleListing struct {
+	ID           int        `json:"id" gorm:"primary_key,auto_increment" `
+	TicketID     int        `json:"ticket_id" gorm:"not null;index" `
+	EventID      int        `json:"event_id" gorm:"not null;index" `
+	SellerID     int        `json:"seller_id" gorm:"not null;index" `
+	BuyerID      int        `json:"buyer_id" `
+	OrderID      int        `json:"order_id" gorm:"index" ` // Order pembeli yang sedang atau sudah membayar listing ini
+	Price        int        `json:"price" `                 // Harga yang dibayar pembeli
+	FeeAmount    int        `json:"fee_amount" `            // Biaya platform yang dipotong dari harga
+	SellerPayout int        `json:"seller_payout" `         // Dana yang diterima penjual
+	Currency     string     `json:"currency" `
+	Status       string     `json:"status" gorm:"default:Active;index" ` // Active, Reserved, Sold, Withdrawn
+	SoldAt       *time.Time `json:"sold_at" `
+	CreatedAt    time.Time  `json:"created_at" `
+	UpdatedAt    time.Time  `json:"updated_at" `
+}
+
+// ResalePayout mencatat dana yang harus dibayarkan ke penjual setelah listing terjual.
+// Dibuat dalam transaksi yang sama dengan perpindahan tiket ke pembeli dan dibayarkan oleh admin.
+type ResalePayout struct {
+	ID        int        `json:"id" gorm:"primary_key,auto_increment" `
+	ListingID int        `json:"listing_id" gorm:"not null;uniqueIndex" `
+	SellerID  int        `json:"seller_id" gorm:"not null;index" `
+	OrderID   int        `json:"order_id" gorm:"index" ` // Order pembeli yang membayar listing
+	Amount    int        `json:"amount" `
+	Currency  string     `json:"currency" `
+	Status    string     `json:"status" gorm:"default:Pending;index" ` // Pending, Paid, Cancelled
+	PaidAt    *time.Time `json:"paid_at" `
+	CreatedAt time.Time  `json:"created_at" `
+	UpdatedAt time.Time  `json:"updated_at" `
+}
+
+// ResalePolicy mengatur batas harga jual kembali dan biaya platform
+type ResalePolicy struct {
+	MaxPricePercent int // Harga jual maksimal dalam persen dari harga tiket yang dibayar
+	FeePercent      int // Biaya platform dalam persen dari harga jual
+}
+
+type CreateListingReq struct {
+	TicketID int `json:"ticket_id" validate:"required"`
+	Price    int `json:"price" validate:"required,gte=1"`
+}
+
+type ListingRes struct {
+	ID           int    `json:"id"`
+	TicketID     int    `json:"ticket_id"`
+	EventID      int    `json:"event_id"`
+	SellerID     int    `json:"seller_id"`
+	Price        int    `json:"price"`
+	FeeAmount    int    `json:"fee_amount"`
+	SellerPayout int    `json:"seller_payout"`
+	Currency     string `json:"currency"`
+	Status       string `json:"status"`
+	SoldAt       string `json:"sold_at,omitempty"`
+	CreatedAt    string `json:"created_at"`
+}
+
+type UpdatePayoutReq struct {
+	Status string `json:"status" validate:"required,oneof=Paid Cancelled"`
+}
+
+type PayoutRes struct {
+	ID        int    `json:"id"`
+	ListingID int    `json:"listing_id"`
+	SellerID  int    `json:"seller_id"`
+	OrderID   int    `json:"order_id"`
+	Amount    int    `json:"amount"`
+	Currency  string `json:"currency"`
+	Status    string `json:"status"`
+	PaidAt    string `json:"paid_at,omitempty"`
+	CreatedAt string `json:"created_at"`
+}
@@ -0,0 +1,78 @@
+package entity
+
+import "time"
+
+// EventSeries adalah event yang berulang, misalnya workshop setiap Sabtu. Setiap
+// kemunculan disimpan sebagai Event tersendiri dengan SeriesID yang sama.
+type EventSeries struct {
+	ID                int       `json:"id" gorm:"primary_key,auto_increment" `
+	Name              string    `json:"name" gorm:"not null" `
+	Description       string    `json:"description" `
+	Location          string    `json:"location" `
+	Category          string    `json:"category" `
+	Capacity          int       `json:"capacity" `
+	Price             int       `json:"price" `
+	MaxTicketsPerUser int       `json:"max_tickets_per_user" `
+	RRule             string    `json:"rrule" gorm:"not null" `        // Misalnya FREQ=WEEKLY;BYDAY=SA;COUNT=10
+	StartDate         time.Time `json:"start_date" `                   // Waktu mulai kemunculan pertama
+	EndDate           time.Time `json:"end_date" `                     // Waktu selesai kemunculan pertama, menentukan durasi setiap kemunculan
+	TimeZone          string    `json:"time_zone" gorm:"default:UTC" ` // Pengulangan mengikuti jam dinding zona ini
+	OrganizerID       int       `json:"organizer_id" gorm:"index" `    // Diturunkan ke setiap kemunculan
+	CreatedAt         time.Time `json:"created_at" `
+	UpdatedAt         time.Time `json:"updated_at" `
+	Events            []Event   `json:"events,omitempty" gorm:"foreignKey:SeriesID" `
+}
+
+type CreateSeriesReq struct {
+	Name              string `json:"name" validate:"required"`
+	Description       string `json:"description" validate:"required"`
+	Location          string `json:"location" validate:"required"`
+	Category          string `json:"category" validate:"required"`
+	Capacity          int    `json:"capacity" validate:"required,gte=0"`
+	Price             int    `json:"price" validate:"required,gte=0"`
+	MaxTicketsPerUser int    `json:"max_tickets_per_user" validate:"gte=0"`
+	RRule             string `json:"rrule" validate:"required"`
+	StartDate         string `json:"start_date" validate:"required"` // RFC 3339 atau YYYY-MM-DD, awal kemunculan pertama
+	EndDate           string `json:"end_date"`                       // RFC 3339 atau YYYY-MM-DD, kosongkan jika setiap kemunculan selesai di hari yang sama
+	TimeZone          string `json:"time_zone"`                      // Zona waktu IANA, default UTC
+	OrganizerID       int    `json:"organizer_id" validate:"gte=0"`  // Hanya dipakai admin untuk menunjuk organizer
+}
+
+// UpdateSeriesReq mengubah series dan semua kemunculan yang belum dimulai
+type UpdateSeriesReq struct {
+	Name              string `json:"name"`
+	Description       string `json:"description"`
+	Location          string `json:"location"`
+	Category          string `json:"category"`
+	Capacity          int    `json:"capacity" validate:"gte=0"`
+	Price             int    `json:"price" validate:"gte=0"`
+	MaxTicketsPerUser int    `json:"max_tickets_per_user" validate:"gte=0"`
+}
+
+type SeriesRes struct {
+	ID                int        `json:"id"`
+	Name              string     `json:"name"`
+	Description       string     `json:"description"`
+	Location          string     `json:"location"`
+	Category          string     `json:"category"`
+	Capacity          int        `json:"capacity"`
+	Price             int        `json:"price"`
+	MaxTicketsPerUser int        `json:"max_tickets_per_user"`
+	RRule             string     `json:"rrule"`
+	StartDate         string     `json:"start_date"`
+	EndDate           string     `json:"end_date"`
+	TimeZone          string     `json:"time_zone"`
+	OrganizerID       int        `json:"organizer_id,omitempty"`
+	Occurrences       []EventRes `json:"occurrences"`
+	CreatedAt         string     `json:"created_at"`
+	UpdatedAt         string     `json:"updated_at"`
+}
+
+type SeriesReport struct {
+	SeriesID         int                   `json:"series_id"`
+	SeriesName       string                `json:"series_name"`
+	TotalOccurrences int                   `json:"total_occurrences"`
+	TotalTickets     int                   `json:"total_tickets"` // Tiket berstatus Dibeli di semua kemunculan
+	TotalRevenue     int                   `json:"total_revenue"`
+	Occurrences      []TicketsSoldPerEvent `json:"occurrences"`
+}
@@ -0,0 +1,21 @@
+package entity
+
+import "time"
+
+// SigningKey adalah kunci penandatangan JWT. Hanya ada satu kunci aktif (RetiredAt
+// kosong); kunci yang sudah dirotasi tetap dipakai untuk verifikasi selama masa tenggang.
+type SigningKey struct {
+	KID        string     `json:"kid" gorm:"type:varchar(64);primaryKey" `
+	Algorithm  string     `json:"algorithm" gorm:"type:varchar(16);not null" `
+	PrivateKey string     `json:"-" gorm:"type:text;not null" ` // PEM PKCS#8
+	PublicKey  string     `json:"public_key" gorm:"type:text;not null" `
+	RetiredAt  *time.Time `json:"retired_at" gorm:"index" `
+	CreatedAt  time.Time  `json:"created_at" `
+}
+
+// KeyPolicy mengatur algoritma dan jadwal rotasi kunci JWT
+type KeyPolicy struct {
+	Algorithm        string        // RS256 atau EdDSA
+	RotationInterval time.Duration // Umur kunci aktif sebelum diganti
+	GracePeriod      time.Duration // Lama kunci lama masih diterima setelah dirotasi
+}
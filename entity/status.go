@@ -0,0 +1,85 @@
+package entity
+
+// EventStatus adalah tahap siklus hidup sebuah event
+type EventStatus string
+
+const (
+	EventStatusActive    EventStatus = "Aktif"       // Event belum dimulai dan tiketnya masih dijual
+	EventStatusOngoing   EventStatus = "Berlangsung" // Event sedang berjalan
+	EventStatusFinished  EventStatus = "Selesai"
+	EventStatusCancelled EventStatus = "Dibatalkan"
+)
+
+// eventStatusTransitions berisi perpindahan status event yang diperbolehkan.
+// Selesai dan Dibatalkan adalah status akhir.
+var eventStatusTransitions = map[EventStatus][]EventStatus{
+	EventStatusActive:  {EventStatusOngoing, EventStatusCancelled},
+	EventStatusOngoing: {EventStatusFinished, EventStatusCancelled},
+}
+
+// EventStatuses berisi semua status event, urutannya dipakai pada laporan
+var EventStatuses = []EventStatus{EventStatusActive, EventStatusOngoing, EventStatusFinished, EventStatusCancelled}
+
+func (s EventStatus) IsValid() bool {
+	for _, status := range EventStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+func (s EventStatus) CanTransitionTo(next EventStatus) bool {
+	for _, status := range eventStatusTransitions[s] {
+		if status == next {
+			return true
+		}
+	}
+	return false
+}
+
+// TicketStatus adalah status sebuah tiket. Order memakai status yang sama dengan tiketnya.
+type TicketStatus string
+
+const (
+	TicketStatusPendingPayment TicketStatus = "Menunggu Pembayaran"
+	TicketStatusPurchased      TicketStatus = "Dibeli"
+	TicketStatusCancelled      TicketStatus = "Dibatalkan"
+	TicketStatusFailed         TicketStatus = "Gagal" // Pembayaran gagal atau kedaluwarsa
+)
+
+// ticketStatusTransitions berisi perpindahan status tiket yang diperbolehkan.
+// Dibatalkan dan Gagal adalah status akhir.
+var ticketStatusTransitions = map[TicketStatus][]TicketStatus{
+	TicketStatusPendingPayment: {TicketStatusPurchased, TicketStatusFailed},
+	TicketStatusPurchased:      {TicketStatusCancelled},
+}
+
+var TicketStatuses = []TicketStatus{TicketStatusPendingPayment, TicketStatusPurchased, TicketStatusCancelled, TicketStatusFailed}
+
+func (s TicketStatus) IsValid() bool {
+	for _, status := range TicketStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+func (s TicketStatus) CanTransitionTo(next TicketStatus) bool {
+	for _, status := range ticketStatusTransitions[s] {
+		if status == next {
+			return true
+		}
+	}
+	return false
+}
+
+// TicketAvailability menunjukkan apakah stok tiket sebuah event masih ada.
+// Ketersediaan terpisah dari status sehingga event yang habis tetap Aktif.
+type TicketAvailability string
+
+const (
+	TicketAvailabilityAvailable TicketAvailability = "Tersedia"
+	TicketAvailabilitySoldOut   TicketAvailability = "Habis"
+)
@@ -0,0 +1,51 @@
+package entity
+
+import "testing"
+
+func TestEventStatusTransitions(t *testing.T) {
+	tests := []struct {
+		from, to EventStatus
+		want     bool
+	}{
+		{EventStatusActive, EventStatusOngoing, true},
+		{EventStatusActive, EventStatusCancelled, true},
+		{EventStatusOngoing, EventStatusFinished, true},
+		{EventStatusOngoing, EventStatusCancelled, true},
+		{EventStatusActive, EventStatusFinished, false},
+		{EventStatusOngoing, EventStatusActive, false},
+		{EventStatusFinished, EventStatusCancelled, false},
+		{EventStatusCancelled, EventStatusActive, false},
+		{EventStatusCancelled, EventStatusCancelled, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.from.CanTransitionTo(tt.to); got != tt.want {
+			t.Errorf("%s -> %s = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestTicketStatusTransitions(t *testing.T) {
+	tests := []struct {
+		from, to TicketStatus
+		want     bool
+	}{
+		{TicketStatusPendingPayment, TicketStatusPurchased, true},
+		{TicketStatusPendingPayment, TicketStatusFailed, true},
+		{TicketStatusPurchased, TicketStatusCancelled, true},
+		{TicketStatusPendingPayment, TicketStatusCancelled, false},
+		{TicketStatusPurchased, TicketStatusFailed, false},
+		{TicketStatusFailed, TicketStatusPurchased, false},
+		{TicketStatusCancelled, TicketStatusPurchased, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.from.CanTransitionTo(tt.to); got != tt.want {
+			t.Errorf("%s -> %s = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+
+	if TicketStatus("Unknown").IsValid() || !TicketStatusFailed.IsValid() {
+		t.Error("IsValid does not match TicketStatuses")
+	}
+}
@@ -6,19 +6,34 @@ import (
 )
 
 type Ticket struct {
-	ID        int       `json:"id" gorm:"primary_key,auto_increment" `
-	EventID   int       `json:"event_id" gorm:"not null" `
-	UserID    int       `json:"user_id" gorm:"not null" `
-	Status    string    `json:"status" gorm:"default:Dibeli" `
-	CreatedAt time.Time `json:"created_at" `
-	UpdatedAt time.Time `json:"updated_at" `
-	Event     Event     `json:"event" gorm:"foreignKey:EventID" `
-	User      User      `json:"user" gorm:"foreignKey:UserID" `
+	ID          int          `json:"id" gorm:"primary_key,auto_increment" `
+	OrderID     int          `json:"order_id" gorm:"index" `
+	EventID     int          `json:"event_id" gorm:"not null" `
+	TierID      int          `json:"tier_id" gorm:"index" `
+	SeatID      int          `json:"seat_id" `
+	UserID      int          `json:"user_id" gorm:"not null" `
+	Status      TicketStatus `json:"status" gorm:"default:Dibeli" `
+	UnitPrice   int          `json:"unit_price" `                       // Harga tiket saat dibeli
+	Discount    int          `json:"discount" `                         // Potongan harga yang diberikan saat dibeli
+	Currency    string       `json:"currency" `                         // Mata uang harga tiket, misalnya IDR
+	Code        string       `json:"-" gorm:"type:varchar(128);index" ` // Kode bertanda tangan untuk QR check-in
+	CheckedInAt *time.Time   `json:"checked_in_at" `                    // Diisi saat tiket dipindai di pintu masuk
+	CheckInGate string       `json:"check_in_gate" `
+	CheckedInBy int          `json:"checked_in_by" `
+	CreatedAt   time.Time    `json:"created_at" `
+	UpdatedAt   time.Time    `json:"updated_at" `
+	Event       Event        `json:"event" gorm:"foreignKey:EventID" `
+	User        User         `json:"user" gorm:"foreignKey:UserID" `
 }
 
 type CreateTicketReq struct {
 	EventID int `json:"event_id" validate:"required"`
-	UserID  int `json:"user_id" validate:"required"`
+	TierID  int `json:"tier_id"` // Wajib diisi jika event memiliki ticket tier
+	// Wajib diisi jika event memakai seat map, jumlahnya menentukan jumlah tiket
+	SeatIDs  []int `json:"seat_ids" validate:"max=10"`
+	Quantity int   `json:"quantity" validate:"omitempty,gte=1,lte=10"` // Jumlah tiket dalam satu order, default 1, maksimal 10
+	// Kode promo opsional untuk mendapatkan potongan harga
+	PromoCode string `json:"promo_code"`
 	// Status  string `json:"status" validate:"required"`
 }
 
@@ -27,12 +42,34 @@ type UpdateTicketReq struct {
 }
 
 type TicketRes struct {
-	ID        int    `json:"id"`
-	EventID   int    `json:"event_id"`
-	UserID    int    `json:"user_id"`
-	Status    string `json:"status"`
-	CreatedAt string `json:"created_at"`
-	UpdatedAt string `json:"updated_at"`
+	ID          int    `json:"id"`
+	OrderID     int    `json:"order_id"`
+	EventID     int    `json:"event_id"`
+	TierID      int    `json:"tier_id"`
+	SeatID      int    `json:"seat_id,omitempty"`
+	UserID      int    `json:"user_id"`
+	Status      string `json:"status"`
+	UnitPrice   int    `json:"unit_price"`
+	Discount    int    `json:"discount"`
+	Currency    string `json:"currency"`
+	CheckedInAt string `json:"checked_in_at,omitempty"`
+	CheckInGate string `json:"check_in_gate,omitempty"`
+	CreatedAt   string `json:"created_at"`
+	UpdatedAt   string `json:"updated_at"`
+}
+
+type CheckInReq struct {
+	Code string `json:"code" validate:"required"`
+	Gate string `json:"gate" validate:"required"`
+}
+
+type CheckInRes struct {
+	TicketID    int    `json:"ticket_id"`
+	EventID     int    `json:"event_id"`
+	EventName   string `json:"event_name"`
+	SeatID      int    `json:"seat_id,omitempty"`
+	Gate        string `json:"gate"`
+	CheckedInAt string `json:"checked_in_at"`
 }
 
 type TicketStatusDistribution struct {
@@ -44,11 +81,14 @@ type TicketStatusDistribution struct {
 type TicketReport struct {
 	TotalTickets             int                        `json:"total_tickets"`              // Total tiket yang terjual
 	TotalRevenue             int                        `json:"total_revenue"`              // Total pendapatan dari tiket yang terjual
+	TotalRefunds             int                        `json:"total_refunds"`              // Jumlah pengembalian dana yang tidak gagal
+	TotalRefunded            int                        `json:"total_refunded"`             // Total dana yang dikembalikan ke pembeli
+	PromoRedemptions         []PromoRedemptionSummary   `json:"promo_redemptions"`          // Pemakaian promo code per kode
 	TicketStatusDistribution []TicketStatusDistribution `json:"ticket_status_distribution"` // Distribusi status tiket
 }
 
 type TicketStatusDistributionResult struct {
-	TotalTickets sql.NullInt64 `gorm:"column:total_tickets" json:"total_tickets`
+	TotalTickets sql.NullInt64 `gorm:"column:total_tickets" json:"total_tickets"`
 	TotalRevenue sql.NullInt64 `gorm:"column:total_revenue" json:"total_revenue"`
 }
 
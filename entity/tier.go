@@ -0,0 +1,44 @@
+package entity
+
+import "time"
+
+type TicketTier struct {
+	ID               int        `json:"id" gorm:"primary_key,auto_increment" `
+	EventID          int        `json:"event_id" gorm:"not null;index" `
+	Name             string     `json:"name" gorm:"not null" ` // Misalnya VIP, Regular, Early-Bird
+	Price            int        `json:"price" `
+	Capacity         int        `json:"capacity" `
+	AvailableTickets int        `json:"available_tickets" `
+	SaleStart        *time.Time `json:"sale_start" ` // Kosong berarti bisa dibeli sejak tier dibuat
+	SaleEnd          *time.Time `json:"sale_end" `   // Kosong berarti bisa dibeli sampai stok habis
+	CreatedAt        time.Time  `json:"created_at" `
+	UpdatedAt        time.Time  `json:"updated_at" `
+}
+
+type CreateTierReq struct {
+	Name      string `json:"name" validate:"required"`
+	Price     int    `json:"price" validate:"gte=0"`
+	Capacity  int    `json:"capacity" validate:"required,gte=1"`
+	SaleStart string `json:"sale_start"` // Format: YYYY-MM-DD HH:MM:SS
+	SaleEnd   string `json:"sale_end"`   // Format: YYYY-MM-DD HH:MM:SS
+}
+
+type UpdateTierReq struct {
+	Name      string `json:"name"`
+	Price     int    `json:"price" validate:"gte=0"`
+	SaleStart string `json:"sale_start"`
+	SaleEnd   string `json:"sale_end"`
+}
+
+type TierRes struct {
+	ID               int    `json:"id"`
+	EventID          int    `json:"event_id"`
+	Name             string `json:"name"`
+	Price            int    `json:"price"`
+	Capacity         int    `json:"capacity"`
+	AvailableTickets int    `json:"available_tickets"`
+	SaleStart        string `json:"sale_start,omitempty"`
+	SaleEnd          string `json:"sale_end,omitempty"`
+	CreatedAt        string `json:"created_at"`
+	UpdatedAt        string `json:"updated_at"`
+}
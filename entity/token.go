@@ -0,0 +1,72 @@
+package entity
+
+import "time"
+
+// RefreshToken hanya disimpan dalam bentuk hash SHA-256. Setiap refresh menerbitkan
+// token baru dengan FamilyID yang sama, sehingga pemakaian ulang token lama bisa
+// mencabut seluruh keluarga token tersebut.
+type RefreshToken struct {
+	ID        int        `json:"id" gorm:"primary_key,auto_increment" `
+	UserID    int        `json:"user_id" gorm:"not null;index" `
+	FamilyID  string     `json:"family_id" gorm:"type:varchar(64);not null;index" `
+	TokenHash string     `json:"-" gorm:"type:varchar(64);not null;uniqueIndex" `
+	ExpiresAt time.Time  `json:"expires_at" gorm:"index" `
+	UsedAt    *time.Time `json:"used_at" ` // Diisi saat token sudah ditukar dengan token baru
+	RevokedAt *time.Time `json:"revoked_at" `
+	MFA       bool       `json:"mfa" ` // Sesi dibuat lewat login 2FA, ikut dibawa ke access token hasil refresh
+	CreatedAt time.Time  `json:"created_at" `
+}
+
+// RevokedToken adalah denylist jti access token yang sudah logout. Baris boleh
+// dihapus setelah ExpiresAt karena token tersebut sudah kedaluwarsa dengan sendirinya.
+type RevokedToken struct {
+	JTI       string    `json:"jti" gorm:"type:varchar(64);primaryKey" `
+	UserID    int       `json:"user_id" gorm:"not null" `
+	ExpiresAt time.Time `json:"expires_at" gorm:"index" `
+	CreatedAt time.Time `json:"created_at" `
+}
+
+type RefreshTokenReq struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+type LogoutReq struct {
+	RefreshToken string `json:"refresh_token"` // Sesi yang ikut dicabut, kosongkan jika hanya access token
+	AllSessions  bool   `json:"all_sessions"`  // Cabut refresh token di semua perangkat
+}
+
+type TokenRes struct {
+	Token        string    `json:"token"`
+	RefreshToken string    `json:"refresh_token"`
+	ExpiresAt    time.Time `json:"expires_at"` // Waktu kedaluwarsa access token
+}
+
+// Tujuan AccountToken, token satu tujuan tidak bisa dipakai untuk tujuan lain
+const (
+	AccountTokenPasswordReset     = "password_reset"
+	AccountTokenEmailVerification = "email_verification"
+	AccountTokenLoginChallenge    = "login_challenge" // Langkah kedua login untuk user dengan 2FA
+)
+
+// AccountToken adalah token sekali pakai untuk reset password dan verifikasi email yang
+// dikirim lewat email, serta challenge token login 2FA. Sama seperti refresh token,
+// hanya hash SHA-256 yang disimpan.
+type AccountToken struct {
+	ID        int        `json:"id" gorm:"primary_key,auto_increment" `
+	UserID    int        `json:"user_id" gorm:"not null;index" `
+	Purpose   string     `json:"purpose" gorm:"type:varchar(32);not null" `
+	Email     string     `json:"email" ` // Alamat tujuan, verifikasi batal jika email user sudah berubah
+	TokenHash string     `json:"-" gorm:"type:varchar(64);not null;uniqueIndex" `
+	ExpiresAt time.Time  `json:"expires_at" gorm:"index" `
+	UsedAt    *time.Time `json:"used_at" `
+	CreatedAt time.Time  `json:"created_at" `
+}
+
+type ForgotPasswordReq struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordReq struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=8"`
+}
@@ -0,0 +1,30 @@
+package entity
+
+import "time"
+
+type TicketTransfer struct {
+	ID          int        `json:"id" gorm:"primary_key,auto_increment" `
+	TicketID    int        `json:"ticket_id" gorm:"not null;index" `
+	FromUserID  int        `json:"from_user_id" gorm:"not null" `
+	ToEmail     string     `json:"to_email" gorm:"type:varchar(255);index" `
+	ToUserID    int        `json:"to_user_id" `                          // Diisi saat penerima menerima tiket
+	Status      string     `json:"status" gorm:"default:Pending;index" ` // Pending, Accepted, Declined, Cancelled
+	RespondedAt *time.Time `json:"responded_at" `
+	CreatedAt   time.Time  `json:"created_at" `
+	UpdatedAt   time.Time  `json:"updated_at" `
+}
+
+type CreateTransferReq struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type TransferRes struct {
+	ID          int    `json:"id"`
+	TicketID    int    `json:"ticket_id"`
+	FromUserID  int    `json:"from_user_id"`
+	ToEmail     string `json:"to_email"`
+	ToUserID    int    `json:"to_user_id,omitempty"`
+	Status      string `json:"status"`
+	RespondedAt string `json:"responded_at,omitempty"`
+	CreatedAt   string `json:"created_at"`
+}
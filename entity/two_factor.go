@@ -0,0 +1,38 @@
+package entity
+
+import "time"
+
+// RecoveryCode dipakai menggantikan kode authenticator jika ponsel user hilang.
+// Setiap kode hanya bisa dipakai sekali dan hanya hash-nya yang disimpan.
+type RecoveryCode struct {
+	ID        int        `json:"id" gorm:"primary_key,auto_increment" `
+	UserID    int        `json:"user_id" gorm:"not null;index" `
+	CodeHash  string     `json:"-" gorm:"type:varchar(64);not null;index" `
+	UsedAt    *time.Time `json:"used_at" `
+	CreatedAt time.Time  `json:"created_at" `
+}
+
+type TwoFactorSetupRes struct {
+	Secret     string `json:"secret"`      // Untuk diketik manual jika QR code tidak bisa dipindai
+	OTPAuthURI string `json:"otpauth_uri"` // Tampilkan sebagai QR code
+}
+
+type TwoFactorCodeReq struct {
+	Code string `json:"code" validate:"required"` // Kode authenticator atau recovery code
+}
+
+type TwoFactorVerifyReq struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"required"` // Kode authenticator atau recovery code
+}
+
+type RecoveryCodesRes struct {
+	RecoveryCodes []string `json:"recovery_codes"` // Hanya ditampilkan sekali, simpan di tempat aman
+}
+
+type TwoFactorStatusRes struct {
+	Enabled                bool       `json:"enabled"`
+	EnabledAt              *time.Time `json:"enabled_at"`
+	RecoveryCodesRemaining int        `json:"recovery_codes_remaining"`
+	Required               bool       `json:"required"` // Role user mewajibkan 2FA
+}
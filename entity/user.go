@@ -3,19 +3,24 @@ package entity
 import "time"
 
 type User struct {
-	ID        int       `gorm:"primary_key,auto_increment" json:"id"`
-	Name      string    `json:"username"`
-	Email     string    `json:"email"`
-	Password  string    `json:"password"`
-	Role      string    `json:"role"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Tickets   []Ticket  `json:"tickets" gorm:"foreignKey:UserID"`
+	ID              int        `gorm:"primary_key,auto_increment" json:"id"`
+	Name            string     `json:"username"`
+	Email           string     `json:"email"`
+	Password        string     `json:"password"`
+	Role            string     `json:"role"`
+	CalendarToken   string     `json:"-" gorm:"type:varchar(64);index"`              // Token rahasia URL langganan kalender, kosong sampai user memintanya
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`                            // Kosong sampai user membuka link verifikasi
+	TOTPSecret      string     `json:"-" gorm:"column:totp_secret;type:varchar(64)"` // Secret authenticator, aktif setelah dikonfirmasi
+	TOTPEnabledAt   *time.Time `json:"totp_enabled_at" gorm:"column:totp_enabled_at"`
+	TOTPLastStep    int64      `json:"-" gorm:"column:totp_last_step"` // Time step kode terakhir yang dipakai, mencegah kode dipakai ulang
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	Tickets         []Ticket   `json:"tickets" gorm:"foreignKey:UserID"`
 }
 
 type RegisterReq struct {
 	Name     string `json:"name" validate:"required"`
-	Email    string `json:"email" validate:"required"`
+	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required"`
 }
 
@@ -26,23 +31,28 @@ type LoginReq struct {
 
 type UpdateUserReq struct {
 	Name     string `json:"name" validate:"required"`
-	Email    string `json:"email" validate:"required"`
+	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required"`
-	Role     string `json:"role" validate:"required"`
+	Role     string `json:"role" validate:"omitempty,oneof=user admin staff organizer"` // Hanya boleh sama dengan role saat ini, ganti role lewat PUT /admin/users/:id/role
 }
 
 type UserRes struct {
-	ID        int       `json:"id"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	Role      string    `json:"role"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Token     string    `json:"token,omitempty"`
+	ID                     int        `json:"id"`
+	Name                   string     `json:"name"`
+	Email                  string     `json:"email"`
+	Role                   string     `json:"role"`
+	EmailVerifiedAt        *time.Time `json:"email_verified_at"`
+	CreatedAt              time.Time  `json:"created_at"`
+	UpdatedAt              time.Time  `json:"updated_at"`
+	Token                  string     `json:"token,omitempty"`
+	RefreshToken           string     `json:"refresh_token,omitempty"`
+	TwoFactorEnabled       bool       `json:"two_factor_enabled"`
+	ChallengeToken         string     `json:"challenge_token,omitempty"`           // Diisi saat login jika 2FA aktif, tukar di POST /auth/2fa/verify
+	TwoFactorSetupRequired bool       `json:"two_factor_setup_required,omitempty"` // Role user mewajibkan 2FA tetapi belum diaktifkan
 }
 
 type UserRoleDistribution struct {
-	Role      string `json:"role"`       // Role user (admin, user)
+	Role      string `json:"role"`       // Role user (admin, organizer, staff, user)
 	TotalUser int    `json:"total_user"` // Total user dengan role tersebut
 }
 
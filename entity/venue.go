@@ -0,0 +1,65 @@
+package entity
+
+import "time"
+
+type Venue struct {
+	ID        int       `json:"id" gorm:"primary_key,auto_increment" `
+	Name      string    `json:"name" gorm:"not null" `
+	Address   string    `json:"address" `
+	CreatedAt time.Time `json:"created_at" `
+	UpdatedAt time.Time `json:"updated_at" `
+	Sections  []Section `json:"sections,omitempty" gorm:"foreignKey:VenueID" `
+}
+
+type Section struct {
+	ID        int       `json:"id" gorm:"primary_key,auto_increment" `
+	VenueID   int       `json:"venue_id" gorm:"not null;index" `
+	Name      string    `json:"name" gorm:"not null" `
+	CreatedAt time.Time `json:"created_at" `
+	UpdatedAt time.Time `json:"updated_at" `
+	Seats     []Seat    `json:"seats,omitempty" gorm:"foreignKey:SectionID" `
+}
+
+type Seat struct {
+	ID        int       `json:"id" gorm:"primary_key,auto_increment" `
+	SectionID int       `json:"section_id" gorm:"not null;index" `
+	Row       string    `json:"row" `
+	Number    int       `json:"number" `
+	Label     string    `json:"label" ` // Misalnya A12
+	CreatedAt time.Time `json:"created_at" `
+	UpdatedAt time.Time `json:"updated_at" `
+}
+
+// EventSeat mencatat kursi yang sedang di-hold atau sudah terjual untuk sebuah event.
+// Unique index pada (event_id, seat_id) menjamin satu kursi hanya bisa dipegang satu kali per event.
+type EventSeat struct {
+	ID        int       `json:"id" gorm:"primary_key,auto_increment" `
+	EventID   int       `json:"event_id" gorm:"not null;uniqueIndex:idx_event_seat" `
+	SeatID    int       `json:"seat_id" gorm:"not null;uniqueIndex:idx_event_seat" `
+	HoldID    int       `json:"hold_id" gorm:"index" `
+	TicketID  int       `json:"ticket_id" gorm:"index" `
+	Status    string    `json:"status" ` // Held, Sold
+	CreatedAt time.Time `json:"created_at" `
+	UpdatedAt time.Time `json:"updated_at" `
+}
+
+type CreateVenueReq struct {
+	Name     string             `json:"name" validate:"required"`
+	Address  string             `json:"address"`
+	Sections []CreateSectionReq `json:"sections" validate:"required,min=1,dive"`
+}
+
+type CreateSectionReq struct {
+	Name        string   `json:"name" validate:"required"`
+	Rows        []string `json:"rows" validate:"required,min=1"`          // Nama baris, misalnya ["A", "B", "C"]
+	SeatsPerRow int      `json:"seats_per_row" validate:"required,gte=1"` // Jumlah kursi di setiap baris
+}
+
+type SeatAvailability struct {
+	SeatID  int    `json:"seat_id"`
+	Section string `json:"section"`
+	Row     string `json:"row"`
+	Number  int    `json:"number"`
+	Label   string `json:"label"`
+	Status  string `json:"status"` // Tersedia, Held, Sold
+}
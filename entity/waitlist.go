@@ -0,0 +1,34 @@
+package entity
+
+import "time"
+
+type WaitlistEntry struct {
+	ID             int        `json:"id" gorm:"primary_key,auto_increment" `
+	EventID        int        `json:"event_id" gorm:"not null;index" `
+	UserID         int        `json:"user_id" gorm:"not null;index" `
+	TierID         int        `json:"tier_id" `
+	Quantity       int        `json:"quantity" gorm:"not null" `
+	Status         string     `json:"status" gorm:"default:Waiting;index" ` // Waiting, Offered, Claimed, Expired
+	HoldID         int        `json:"hold_id" gorm:"index" `                // Hold yang dibuat saat user mendapat penawaran
+	OfferExpiresAt *time.Time `json:"offer_expires_at" `
+	CreatedAt      time.Time  `json:"created_at" `
+	UpdatedAt      time.Time  `json:"updated_at" `
+}
+
+type JoinWaitlistReq struct {
+	TierID   int `json:"tier_id"`
+	Quantity int `json:"quantity" validate:"omitempty,gte=1,lte=10"` // Default 1, maksimal 10
+}
+
+type WaitlistRes struct {
+	ID             int    `json:"id"`
+	EventID        int    `json:"event_id"`
+	UserID         int    `json:"user_id"`
+	TierID         int    `json:"tier_id"`
+	Quantity       int    `json:"quantity"`
+	Status         string `json:"status"`
+	Position       int    `json:"position"` // Posisi dalam antrean, 0 jika sudah mendapat penawaran
+	HoldID         int    `json:"hold_id,omitempty"`
+	OfferExpiresAt string `json:"offer_expires_at,omitempty"`
+	CreatedAt      string `json:"created_at"`
+}
@@ -0,0 +1,54 @@
+package mail
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FileSender menyimpan setiap email sebagai file .eml di satu folder. Dipakai untuk
+// development lokal agar link verifikasi dan reset password bisa dibuka tanpa SMTP.
+type FileSender struct {
+	dir string
+}
+
+func NewFileSender(dir string) (*FileSender, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &FileSender{dir: dir}, nil
+}
+
+func (s *FileSender) Send(msg Message) error {
+	// Nama file diawali waktu kirim agar urut, alamat tujuan dibersihkan dari karakter path
+	recipient := strings.NewReplacer("/", "_", "\\", "_", "..", "_").Replace(msg.To)
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102T150405.000000000"), recipient)
+
+	return os.WriteFile(filepath.Join(s.dir, name), []byte(formatMessage(msg)), 0o600)
+}
+
+// LogSender menulis email ke log aplikasi, dipakai jika folder outbox tidak diatur
+type LogSender struct{}
+
+func NewLogSender() *LogSender {
+	return &LogSender{}
+}
+
+func (s *LogSender) Send(msg Message) error {
+	log.Printf("Email not delivered (log sender):\n%s", formatMessage(msg))
+	return nil
+}
+
+func formatMessage(msg Message) string {
+	var b strings.Builder
+	b.WriteString("To: " + msg.To + "\r\n")
+	b.WriteString("Subject: " + msg.Subject + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(msg.Body)
+	return b.String()
+}
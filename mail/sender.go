@@ -0,0 +1,14 @@
+package mail
+
+// Sender adalah kontrak pengirim email. Service hanya bergantung pada interface ini
+// sehingga pengiriman lewat SMTP atau penyedia email bisa ditambahkan tanpa mengubah
+// alur verifikasi email dan reset password.
+type Sender interface {
+	Send(msg Message) error
+}
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string // Teks biasa
+}
@@ -3,13 +3,21 @@ package main
 import (
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/Ayyasy123/dibimbing-take-home-test/config"
+	"github.com/Ayyasy123/dibimbing-take-home-test/middleware"
+	"github.com/Ayyasy123/dibimbing-take-home-test/repository"
 	"github.com/Ayyasy123/dibimbing-take-home-test/routes"
+	"github.com/Ayyasy123/dibimbing-take-home-test/scheduler"
+	"github.com/Ayyasy123/dibimbing-take-home-test/service"
+	"github.com/Ayyasy123/dibimbing-take-home-test/utils"
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
+	// Secret kode tiket dibutuhkan oleh migrasi data yang membuat kode tiket lama
+	config.LoadTicketCodeSecret()
 	config.ConnectDatabase()
 
 	r := gin.Default()
@@ -20,9 +28,62 @@ func main() {
 		})
 	})
 
-	routes.SetupUserRoutes(config.DB, r)
-	routes.SetupEventRoutes(config.DB, r)
-	routes.SetupTicketRoutes(config.DB, r)
+	gateway := config.LoadPaymentGateway()
+
+	// Pastikan ada kunci JWT aktif sebelum server menerima request. Kunci yang belum
+	// dikenal (hasil rotasi instance lain) dimuat ulang paling sering sekali per 10 detik.
+	keyPolicy := config.LoadKeyPolicy()
+	keyService := service.NewKeyService(repository.NewSigningKeyRepository(config.DB), utils.DefaultKeyRing(), keyPolicy)
+	if err := keyService.RotateKeys(time.Now()); err != nil {
+		log.Fatal("Failed to load JWT signing keys: ", err)
+	}
+	utils.DefaultKeyRing().SetRefresher(keyService.ReloadKeys, 10*time.Second)
+
+	// User yang belum verifikasi email ditolak saat membeli tiket jika policy mewajibkannya
+	authPolicy := config.LoadAuthPolicy()
+	if authPolicy.RequireEmailVerification {
+		middleware.UseEmailVerification(repository.NewUserRepository(config.DB))
+	}
+
+	// Endpoint admin hanya bisa dipakai dari login dengan 2FA jika policy mewajibkannya
+	if authPolicy.RequireAdminTwoFactor {
+		middleware.RequireTwoFactorForRoles("admin")
+	}
+
+	// Access token yang sudah logout ditolak oleh JWTAuth
+	middleware.UseTokenDenylist(repository.NewTokenRepository(config.DB))
+
+	mailer := config.LoadMailSender()
+
+	routes.SetupUserRoutes(config.DB, r, mailer, authPolicy)
+	routes.SetupAdminRoutes(config.DB, r, mailer, authPolicy)
+	routes.SetupEventRoutes(config.DB, r, gateway, config.LoadCheckInPolicy())
+	routes.SetupTicketRoutes(config.DB, r, gateway)
+	routes.SetupVenueRoutes(config.DB, r)
+	routes.SetupPaymentRoutes(config.DB, r, gateway)
+	routes.SetupPromoRoutes(config.DB, r)
+	routes.SetupResaleRoutes(config.DB, r, gateway, config.LoadResalePolicy())
+	routes.SetupSeriesRoutes(config.DB, r)
+	routes.SetupCalendarRoutes(config.DB, r)
+	routes.SetupKeyRoutes(config.DB, r, keyPolicy)
+
+	// Lepas hold tiket yang kedaluwarsa setiap menit
+	scheduler.StartHoldSweeper(config.DB, time.Minute)
+
+	// Pindahkan status event sesuai jadwalnya dan tutup penjualan saat event dimulai
+	scheduler.StartEventLifecycle(config.DB, time.Minute, scheduler.LogEventTransition, scheduler.CloseEventSales(config.DB))
+
+	// Buat kemunculan baru untuk event series setiap hari
+	scheduler.StartSeriesGenerator(config.DB, 24*time.Hour)
+
+	// Hapus refresh token dan denylist access token yang sudah kedaluwarsa
+	scheduler.StartTokenSweeper(config.DB, time.Hour)
+
+	// Rotasi kunci JWT yang sudah jatuh tempo dan buang kunci yang masa tenggangnya habis
+	scheduler.StartKeyRotation(keyService, time.Hour)
+
+	// Batalkan pembayaran yang tidak diselesaikan dalam 30 menit
+	scheduler.StartPaymentSweeper(config.DB, time.Minute, 30*time.Minute)
 
 	log.Println("Server running on port 8080")
 
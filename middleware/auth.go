@@ -8,6 +8,18 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// TokenDenylist memeriksa apakah access token sudah dicabut, misalnya karena logout
+type TokenDenylist interface {
+	IsAccessTokenRevoked(jti string) (bool, error)
+}
+
+var tokenDenylist TokenDenylist
+
+// UseTokenDenylist dipanggil sekali saat aplikasi mulai agar JWTAuth menolak token yang sudah logout
+func UseTokenDenylist(denylist TokenDenylist) {
+	tokenDenylist = denylist
+}
+
 func JWTAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
@@ -25,9 +37,27 @@ func JWTAuth() gin.HandlerFunc {
 			return
 		}
 
+		if tokenDenylist != nil {
+			revoked, err := tokenDenylist.IsAccessTokenRevoked(claims.ID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify token"})
+				c.Abort()
+				return
+			}
+
+			if revoked {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Token has been revoked"})
+				c.Abort()
+				return
+			}
+		}
+
 		// Simpan claims ke context agar bisa diakses di handler
 		c.Set("user_id", claims.UserID)
 		c.Set("role", claims.Role)
+		c.Set("mfa", claims.MFA)
+		c.Set("jti", claims.ID)
+		c.Set("token_expires_at", claims.ExpiresAt.Time)
 
 		c.Next()
 	}
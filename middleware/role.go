@@ -6,6 +6,18 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// Role yang hanya boleh memakai hak aksesnya dari login yang melewati 2FA
+var twoFactorRoles = map[string]bool{}
+
+// RequireTwoFactorForRoles dipanggil sekali saat aplikasi mulai. RoleAuth lalu menolak
+// token role tersebut yang tidak memiliki claim mfa, sementara endpoint tanpa RoleAuth
+// seperti setup 2FA tetap bisa diakses.
+func RequireTwoFactorForRoles(roles ...string) {
+	for _, role := range roles {
+		twoFactorRoles[role] = true
+	}
+}
+
 func RoleAuth(allowedRoles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userRole, exists := c.Get("role")
@@ -18,6 +30,12 @@ func RoleAuth(allowedRoles ...string) gin.HandlerFunc {
 		// Cek apakah role user ada di daftar allowedRoles
 		for _, role := range allowedRoles {
 			if role == userRole {
+				if twoFactorRoles[role] && !c.GetBool("mfa") {
+					c.JSON(http.StatusForbidden, gin.H{"error": "Two-factor authentication is required for this role, enable it via /auth/2fa/setup and login again"})
+					c.Abort()
+					return
+				}
+
 				c.Next()
 				return
 			}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EmailVerificationChecker memeriksa apakah user sudah memverifikasi emailnya
+type EmailVerificationChecker interface {
+	IsEmailVerified(userID int) (bool, error)
+}
+
+var emailVerification EmailVerificationChecker
+
+// UseEmailVerification dipanggil sekali saat aplikasi mulai jika policy mewajibkan
+// verifikasi email. Tanpa checker, RequireVerifiedEmail tidak memblokir apa pun.
+func UseEmailVerification(checker EmailVerificationChecker) {
+	emailVerification = checker
+}
+
+// RequireVerifiedEmail dipasang setelah JWTAuth pada endpoint pembelian tiket
+func RequireVerifiedEmail() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if emailVerification == nil {
+			c.Next()
+			return
+		}
+
+		verified, err := emailVerification.IsEmailVerified(c.GetInt("user_id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify email status"})
+			c.Abort()
+			return
+		}
+
+		if !verified {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Please verify your email address before buying tickets"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
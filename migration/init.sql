@@ -28,7 +28,7 @@ CREATE TABLE `ticket` (
   `id` int PRIMARY KEY,
   `user_id` int,
   `event_id` int,
-  `status` ENUM ('Dibeli', 'Dibatalkan'),
+  `status` ENUM ('Menunggu Pembayaran', 'Dibeli', 'Dibatalkan', 'Gagal') DEFAULT 'Dibeli',
   `created_at` timestamp,
   `updated_at` timestamp
 );
@@ -0,0 +1,45 @@
+package payment
+
+// PaymentGateway adalah kontrak yang harus dipenuhi setiap penyedia pembayaran.
+// Ticket service hanya bergantung pada interface ini sehingga penyedia bisa diganti
+// tanpa mengubah alur checkout.
+type PaymentGateway interface {
+	Name() string
+	CreateCharge(req ChargeRequest) (*Charge, error)
+	Capture(chargeID string) (*Charge, error)
+	Refund(chargeID string, amount int) (*RefundResult, error)
+	VerifyWebhook(payload []byte, signature string) (*WebhookEvent, error)
+}
+
+type ChargeRequest struct {
+	OrderID     int
+	Amount      int
+	Currency    string
+	Description string
+}
+
+type Charge struct {
+	ID          string `json:"id"`
+	Amount      int    `json:"amount"`
+	Currency    string `json:"currency"`
+	Status      string `json:"status"` // requires_capture, captured
+	CheckoutURL string `json:"checkout_url"`
+}
+
+type RefundResult struct {
+	ID       string `json:"id"`
+	ChargeID string `json:"charge_id"`
+	Amount   int    `json:"amount"`
+}
+
+// Jenis event webhook yang dikirim penyedia pembayaran
+const (
+	EventChargeAuthorized = "charge.authorized" // Dana sudah diotorisasi dan perlu di-capture
+	EventChargeSucceeded  = "charge.succeeded"  // Dana sudah diterima
+	EventChargeFailed     = "charge.failed"
+)
+
+type WebhookEvent struct {
+	Type     string `json:"type"`
+	ChargeID string `json:"charge_id"`
+}
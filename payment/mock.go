@@ -0,0 +1,138 @@
+package payment
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sync"
+)
+
+// MockGateway adalah penyedia pembayaran palsu yang menyimpan charge di memori.
+// Dipakai untuk development lokal dan pengujian alur pembayaran tanpa penyedia asli.
+type MockGateway struct {
+	secret  []byte
+	mu      sync.Mutex
+	charges map[string]*mockCharge
+}
+
+type mockCharge struct {
+	charge   Charge
+	refunded int
+}
+
+func NewMockGateway(secret string) *MockGateway {
+	return &MockGateway{
+		secret:  []byte(secret),
+		charges: make(map[string]*mockCharge),
+	}
+}
+
+func (g *MockGateway) Name() string {
+	return "mock"
+}
+
+func (g *MockGateway) CreateCharge(req ChargeRequest) (*Charge, error) {
+	if req.Amount <= 0 {
+		return nil, errors.New("charge amount must be greater than zero")
+	}
+
+	id, err := randomID("mock_ch_")
+	if err != nil {
+		return nil, err
+	}
+
+	charge := Charge{
+		ID:          id,
+		Amount:      req.Amount,
+		Currency:    req.Currency,
+		Status:      "requires_capture",
+		CheckoutURL: "/payments/mock/" + id,
+	}
+
+	g.mu.Lock()
+	g.charges[id] = &mockCharge{charge: charge}
+	g.mu.Unlock()
+
+	return &charge, nil
+}
+
+func (g *MockGateway) Capture(chargeID string) (*Charge, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	stored, ok := g.charges[chargeID]
+	if !ok {
+		return nil, errors.New("charge not found")
+	}
+
+	if stored.charge.Status == "captured" {
+		return nil, errors.New("charge already captured")
+	}
+
+	stored.charge.Status = "captured"
+	charge := stored.charge
+	return &charge, nil
+}
+
+func (g *MockGateway) Refund(chargeID string, amount int) (*RefundResult, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	stored, ok := g.charges[chargeID]
+	if !ok {
+		return nil, errors.New("charge not found")
+	}
+
+	if stored.charge.Status != "captured" {
+		return nil, errors.New("only captured charges can be refunded")
+	}
+
+	if stored.refunded+amount > stored.charge.Amount {
+		return nil, errors.New("refund amount exceeds captured amount")
+	}
+
+	id, err := randomID("mock_re_")
+	if err != nil {
+		return nil, err
+	}
+
+	stored.refunded += amount
+	return &RefundResult{ID: id, ChargeID: chargeID, Amount: amount}, nil
+}
+
+func (g *MockGateway) VerifyWebhook(payload []byte, signature string) (*WebhookEvent, error) {
+	expected, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(expected, g.sign(payload)) {
+		return nil, errors.New("invalid webhook signature")
+	}
+
+	var event WebhookEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, err
+	}
+
+	return &event, nil
+}
+
+// Sign menghasilkan signature webhook seperti yang dikirim penyedia asli,
+// sehingga webhook bisa disimulasikan secara lokal.
+func (g *MockGateway) Sign(payload []byte) string {
+	return hex.EncodeToString(g.sign(payload))
+}
+
+func (g *MockGateway) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, g.secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+func randomID(prefix string) (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return prefix + hex.EncodeToString(b), nil
+}
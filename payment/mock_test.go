@@ -0,0 +1,89 @@
+package payment
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMockGatewayChargeLifecycle(t *testing.T) {
+	gateway := NewMockGateway("test-secret")
+
+	if _, err := gateway.CreateCharge(ChargeRequest{OrderID: 1, Amount: 0, Currency: "IDR"}); err == nil {
+		t.Fatal("expected a zero amount charge to be rejected")
+	}
+
+	charge, err := gateway.CreateCharge(ChargeRequest{OrderID: 1, Amount: 150000, Currency: "IDR"})
+	if err != nil {
+		t.Fatalf("CreateCharge returned an error: %v", err)
+	}
+	if charge.Status != "requires_capture" {
+		t.Fatalf("new charge status = %q, want requires_capture", charge.Status)
+	}
+
+	// Charge yang belum di-capture belum bisa di-refund
+	if _, err := gateway.Refund(charge.ID, 1000); err == nil {
+		t.Fatal("expected refund of an uncaptured charge to fail")
+	}
+
+	captured, err := gateway.Capture(charge.ID)
+	if err != nil {
+		t.Fatalf("Capture returned an error: %v", err)
+	}
+	if captured.Status != "captured" {
+		t.Fatalf("captured charge status = %q, want captured", captured.Status)
+	}
+
+	if _, err := gateway.Capture(charge.ID); err == nil {
+		t.Fatal("expected a second capture to fail")
+	}
+
+	if _, err := gateway.Refund(charge.ID, 100000); err != nil {
+		t.Fatalf("partial refund returned an error: %v", err)
+	}
+	if _, err := gateway.Refund(charge.ID, 60000); err == nil {
+		t.Fatal("expected refunds above the captured amount to fail")
+	}
+	if _, err := gateway.Refund(charge.ID, 50000); err != nil {
+		t.Fatalf("refund of the remaining amount returned an error: %v", err)
+	}
+
+	if _, err := gateway.Capture("mock_ch_unknown"); err == nil {
+		t.Fatal("expected capture of an unknown charge to fail")
+	}
+}
+
+func TestMockGatewayWebhookSignature(t *testing.T) {
+	gateway := NewMockGateway("test-secret")
+
+	payload, err := json.Marshal(WebhookEvent{Type: EventChargeSucceeded, ChargeID: "mock_ch_1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	event, err := gateway.VerifyWebhook(payload, gateway.Sign(payload))
+	if err != nil {
+		t.Fatalf("VerifyWebhook rejected a valid signature: %v", err)
+	}
+	if event.Type != EventChargeSucceeded || event.ChargeID != "mock_ch_1" {
+		t.Fatalf("VerifyWebhook returned %+v", event)
+	}
+
+	tests := []struct {
+		name      string
+		payload   []byte
+		signature string
+	}{
+		{"tampered payload", []byte(`{"type":"charge.succeeded","charge_id":"mock_ch_2"}`), gateway.Sign(payload)},
+		{"signature from another secret", payload, NewMockGateway("other-secret").Sign(payload)},
+		{"not hex", payload, "not-a-signature"},
+		{"empty", payload, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := gateway.VerifyWebhook(tt.payload, tt.signature); err == nil {
+				t.Fatal("expected the webhook to be rejected")
+			}
+		})
+	}
+}
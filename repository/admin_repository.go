@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrBootstrapUsed     = errors.New("bootstrap token has already been used")
+	ErrInvalidInvitation = errors.New("invitation is no longer valid")
+)
+
+// AdminRepository mengubah role user selalu bersama catatan RoleGrant dalam satu
+// transaksi, sehingga tidak ada perubahan role tanpa jejak audit
+type AdminRepository interface {
+	CreateUserWithGrant(user *entity.User, grant *entity.RoleGrant) error
+	CreateBootstrapAdmin(user *entity.User, grant *entity.RoleGrant) error
+	ChangeRole(userID int, grant *entity.RoleGrant) error
+	FindAllRoleGrants(userID int) ([]entity.RoleGrant, error)
+	CreateInvitation(invitation *entity.Invitation) error
+	FindInvitationByID(id int) (*entity.Invitation, error)
+	FindAllInvitations() ([]entity.Invitation, error)
+	RevokeInvitation(id int, now time.Time) error
+	AcceptInvitation(id int, user *entity.User, grant *entity.RoleGrant, now time.Time) error
+}
+
+type adminRepository struct {
+	db *gorm.DB
+}
+
+func NewAdminRepository(db *gorm.DB) *adminRepository {
+	return &adminRepository{db: db}
+}
+
+func (r *adminRepository) CreateUserWithGrant(user *entity.User, grant *entity.RoleGrant) error {
+	tx := r.db.Begin()
+
+	if err := createUserWithGrant(tx, user, grant); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// CreateBootstrapAdmin hanya berhasil jika belum pernah ada admin yang dibuat dengan
+// bootstrap token. Baris bootstrap dikunci agar dua request bersamaan tidak sama-sama lolos.
+func (r *adminRepository) CreateBootstrapAdmin(user *entity.User, grant *entity.RoleGrant) error {
+	tx := r.db.Begin()
+
+	var count int64
+	if err := tx.Model(&entity.RoleGrant{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("source = ?", entity.RoleGrantSourceBootstrap).Count(&count).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if count > 0 {
+		tx.Rollback()
+		return ErrBootstrapUsed
+	}
+
+	if err := createUserWithGrant(tx, user, grant); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// ChangeRole mengganti role user. OldRole pada grant diisi dari database.
+func (r *adminRepository) ChangeRole(userID int, grant *entity.RoleGrant) error {
+	tx := r.db.Begin()
+
+	if err := changeRole(tx, userID, grant); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+func (r *adminRepository) FindAllRoleGrants(userID int) ([]entity.RoleGrant, error) {
+	var grants []entity.RoleGrant
+	query := r.db.Order("created_at DESC")
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	err := query.Find(&grants).Error
+	return grants, err
+}
+
+func (r *adminRepository) CreateInvitation(invitation *entity.Invitation) error {
+	return r.db.Create(invitation).Error
+}
+
+func (r *adminRepository) FindInvitationByID(id int) (*entity.Invitation, error) {
+	var invitation entity.Invitation
+	err := r.db.Where("id = ?", id).First(&invitation).Error
+	return &invitation, err
+}
+
+func (r *adminRepository) FindAllInvitations() ([]entity.Invitation, error) {
+	var invitations []entity.Invitation
+	err := r.db.Order("created_at DESC").Find(&invitations).Error
+	return invitations, err
+}
+
+// RevokeInvitation membatalkan undangan yang belum diterima
+func (r *adminRepository) RevokeInvitation(id int, now time.Time) error {
+	result := r.db.Model(&entity.Invitation{}).
+		Where("id = ? AND accepted_at IS NULL AND revoked_at IS NULL", id).
+		Update("revoked_at", now)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrInvalidInvitation
+	}
+	return nil
+}
+
+// AcceptInvitation menandai undangan diterima lalu membuat user baru (user.ID kosong)
+// atau mengganti role user yang sudah ada, semuanya dalam satu transaksi
+func (r *adminRepository) AcceptInvitation(id int, user *entity.User, grant *entity.RoleGrant, now time.Time) error {
+	tx := r.db.Begin()
+
+	var invitation entity.Invitation
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", id).First(&invitation).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidInvitation
+		}
+		return err
+	}
+
+	if invitation.AcceptedAt != nil || invitation.RevokedAt != nil || !now.Before(invitation.ExpiresAt) {
+		tx.Rollback()
+		return ErrInvalidInvitation
+	}
+
+	var err error
+	if user.ID == 0 {
+		err = createUserWithGrant(tx, user, grant)
+	} else {
+		err = changeRole(tx, user.ID, grant)
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Model(&entity.Invitation{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"accepted_at": now, "accepted_user_id": grant.UserID}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+func createUserWithGrant(tx *gorm.DB, user *entity.User, grant *entity.RoleGrant) error {
+	if err := tx.Create(user).Error; err != nil {
+		return err
+	}
+
+	grant.UserID = user.ID
+	grant.NewRole = user.Role
+	return tx.Create(grant).Error
+}
+
+func changeRole(tx *gorm.DB, userID int, grant *entity.RoleGrant) error {
+	var user entity.User
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", userID).First(&user).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Model(&entity.User{}).Where("id = ?", userID).
+		Update("role", grant.NewRole).Error; err != nil {
+		return err
+	}
+
+	grant.UserID = userID
+	grant.OldRole = user.Role
+	return tx.Create(grant).Error
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"gorm.io/gorm"
+)
+
+func createTestInvitation(t *testing.T, db *gorm.DB, invitedBy int) *entity.Invitation {
+	t.Helper()
+
+	invitation := &entity.Invitation{
+		Email:     fmt.Sprintf("invite-%d@example.com", time.Now().UnixNano()),
+		Role:      "organizer",
+		InvitedBy: invitedBy,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	if err := NewAdminRepository(db).CreateInvitation(invitation); err != nil {
+		t.Fatalf("failed to create invitation: %v", err)
+	}
+
+	t.Cleanup(func() {
+		userIDs := db.Model(&entity.User{}).Select("id").Where("email = ?", invitation.Email)
+		db.Where("user_id IN (?)", userIDs).Delete(&entity.RoleGrant{})
+		db.Where("email = ?", invitation.Email).Delete(&entity.User{})
+		db.Delete(invitation)
+	})
+
+	return invitation
+}
+
+func TestConcurrentAcceptInvitationSucceedsOnce(t *testing.T) {
+	db := openTestDB(t)
+	admin := createTestUser(t, db)
+	invitation := createTestInvitation(t, db, admin.ID)
+
+	adminRepo := NewAdminRepository(db)
+
+	// Link undangan yang sama dibuka berkali-kali secara bersamaan
+	const attempts = 5
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	accepted := 0
+	start := make(chan struct{})
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+
+			user := &entity.User{Name: "Invited", Email: invitation.Email, Role: invitation.Role}
+			grant := &entity.RoleGrant{Source: entity.RoleGrantSourceInvitation, InvitationID: &invitation.ID}
+			if err := adminRepo.AcceptInvitation(invitation.ID, user, grant, time.Now()); err == nil {
+				mu.Lock()
+				accepted++
+				mu.Unlock()
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	if accepted != 1 {
+		t.Fatalf("invitation was accepted %d times, want 1", accepted)
+	}
+
+	var users int64
+	db.Model(&entity.User{}).Where("email = ?", invitation.Email).Count(&users)
+	if users != 1 {
+		t.Fatalf("%d users created from the invitation, want 1", users)
+	}
+
+	// Undangan yang sudah diterima tidak bisa dibatalkan lagi
+	if err := adminRepo.RevokeInvitation(invitation.ID, time.Now()); !errors.Is(err, ErrInvalidInvitation) {
+		t.Fatalf("RevokeInvitation err = %v, want ErrInvalidInvitation", err)
+	}
+}
+
+func TestAcceptRevokedOrExpiredInvitationFails(t *testing.T) {
+	db := openTestDB(t)
+	admin := createTestUser(t, db)
+	adminRepo := NewAdminRepository(db)
+
+	accept := func(invitation *entity.Invitation, now time.Time) error {
+		user := &entity.User{Name: "Invited", Email: invitation.Email, Role: invitation.Role}
+		grant := &entity.RoleGrant{Source: entity.RoleGrantSourceInvitation, InvitationID: &invitation.ID}
+		return adminRepo.AcceptInvitation(invitation.ID, user, grant, now)
+	}
+
+	revoked := createTestInvitation(t, db, admin.ID)
+	if err := adminRepo.RevokeInvitation(revoked.ID, time.Now()); err != nil {
+		t.Fatalf("RevokeInvitation returned an error: %v", err)
+	}
+	if err := accept(revoked, time.Now()); !errors.Is(err, ErrInvalidInvitation) {
+		t.Fatalf("revoked invitation err = %v, want ErrInvalidInvitation", err)
+	}
+
+	expired := createTestInvitation(t, db, admin.ID)
+	if err := accept(expired, expired.ExpiresAt.Add(time.Second)); !errors.Is(err, ErrInvalidInvitation) {
+		t.Fatalf("expired invitation err = %v, want ErrInvalidInvitation", err)
+	}
+
+	var users int64
+	db.Model(&entity.User{}).Where("email IN ?", []string{revoked.Email, expired.Email}).Count(&users)
+	if users != 0 {
+		t.Fatalf("%d users created from invalid invitations", users)
+	}
+}
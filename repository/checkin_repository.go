@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CheckInRepository interface {
+	FindBundleTickets(eventID int) ([]entity.Ticket, error)
+	RecordScans(eventID int, scans []entity.CheckInScan) error
+}
+
+type checkInRepository struct {
+	db *gorm.DB
+}
+
+func NewCheckInRepository(db *gorm.DB) *checkInRepository {
+	return &checkInRepository{db: db}
+}
+
+// FindBundleTickets mengambil tiket yang perlu dikenali scanner. Tiket yang dibatalkan
+// ikut disertakan agar scanner bisa menolaknya dengan alasan yang jelas.
+func (r *checkInRepository) FindBundleTickets(eventID int) ([]entity.Ticket, error) {
+	var tickets []entity.Ticket
+	err := r.db.Preload("User").
+		Where("event_id = ? AND status IN ?", eventID, []entity.TicketStatus{entity.TicketStatusPurchased, entity.TicketStatusCancelled}).
+		Order("id").Find(&tickets).Error
+	return tickets, err
+}
+
+// RecordScans mencocokkan hasil pemindaian offline dengan data check-in tiket lalu
+// menyimpannya sebagai log. Scan harus sudah diurutkan berdasarkan ScannedAt agar
+// pemindaian paling awal yang dianggap sebagai check-in yang sah.
+func (r *checkInRepository) RecordScans(eventID int, scans []entity.CheckInScan) error {
+	tx := r.db.Begin()
+
+	for i := range scans {
+		scan := &scans[i]
+
+		// Scan dengan kode yang signature-nya tidak valid sudah ditandai oleh service
+		if scan.Result == "" {
+			uploaded, err := reconcileScan(tx, eventID, scan)
+			if err != nil {
+				tx.Rollback()
+				return err
+			}
+
+			// Scan yang sama sudah pernah diunggah, tidak perlu dicatat lagi
+			if uploaded {
+				continue
+			}
+		}
+
+		if err := tx.Create(scan).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit().Error
+}
+
+// reconcileScan menentukan hasil sebuah scan dan memperbarui check-in tiket jika perlu.
+// Nilai true dikembalikan jika scan yang sama sudah pernah diunggah sebelumnya.
+func reconcileScan(tx *gorm.DB, eventID int, scan *entity.CheckInScan) (bool, error) {
+	var uploaded int64
+	if err := tx.Model(&entity.CheckInScan{}).
+		Where("device_id = ? AND code = ? AND scanned_at = ?", scan.DeviceID, scan.Code, scan.ScannedAt).
+		Count(&uploaded).Error; err != nil {
+		return false, err
+	}
+
+	if uploaded > 0 {
+		scan.Result = "Duplicate"
+		scan.Note = "scan was already uploaded"
+		return true, nil
+	}
+
+	var ticket entity.Ticket
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", scan.TicketID).First(&ticket).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		scan.Result = "Invalid"
+		scan.Note = "ticket not found"
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	switch {
+	case ticket.EventID != eventID:
+		scan.Result = "Invalid"
+		scan.Note = "ticket belongs to another event"
+	case ticket.Code != scan.Code:
+		scan.Result = "Invalid"
+		scan.Note = "ticket code has been replaced"
+	case ticket.Status != entity.TicketStatusPurchased:
+		// Tiket dibatalkan setelah bundle diunduh tetapi tetap dipindai di pintu
+		scan.Result = "Conflict"
+		scan.Note = fmt.Sprintf("ticket status is %s", ticket.Status)
+	case ticket.CheckedInAt == nil:
+		scan.Result = "Accepted"
+
+		if err := tx.Model(&entity.Ticket{}).Where("id = ?", ticket.ID).
+			Updates(map[string]interface{}{
+				"checked_in_at": scan.ScannedAt,
+				"check_in_gate": scan.Gate,
+				"checked_in_by": scan.UploadedBy,
+			}).Error; err != nil {
+			return false, err
+		}
+	case scan.ScannedAt.Before(*ticket.CheckedInAt):
+		// Check-in yang sudah tercatat tidak ditimpa oleh scan yang mengaku lebih awal,
+		// scan ini dicatat sebagai konflik untuk diperiksa petugas
+		scan.Result = "Conflict"
+		scan.Note = fmt.Sprintf("scanned before the recorded check-in at gate %s at %s", ticket.CheckInGate, ticket.CheckedInAt.Format("2006-01-02 15:04:05"))
+	case ticket.CheckInGate == scan.Gate:
+		scan.Result = "Duplicate"
+		scan.Note = fmt.Sprintf("already checked in at %s", ticket.CheckedInAt.Format("2006-01-02 15:04:05"))
+	default:
+		// Tiket yang sama dipakai di dua gate berbeda, kemungkinan kode digandakan
+		scan.Result = "Conflict"
+		scan.Note = fmt.Sprintf("already checked in at gate %s at %s", ticket.CheckInGate, ticket.CheckedInAt.Format("2006-01-02 15:04:05"))
+	}
+
+	return false, nil
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+)
+
+func TestRecordScansReconcilesOfflineScans(t *testing.T) {
+	db := openTestDB(t)
+	event := createTestEvent(t, db, 10)
+	ticket := createPaidTicket(t, db, event, createTestUser(t, db).ID)
+
+	scannedAt := time.Now().Truncate(time.Second)
+	scan := func(device, gate string, at time.Time, code string) entity.CheckInScan {
+		return entity.CheckInScan{
+			EventID:   event.ID,
+			TicketID:  ticket.ID,
+			Code:      code,
+			Gate:      gate,
+			DeviceID:  device,
+			ScannedAt: at,
+		}
+	}
+
+	checkInRepo := NewCheckInRepository(db)
+
+	first := []entity.CheckInScan{scan("device-1", "A", scannedAt, ticket.Code)}
+	if err := checkInRepo.RecordScans(event.ID, first); err != nil {
+		t.Fatalf("RecordScans returned an error: %v", err)
+	}
+	if first[0].Result != "Accepted" {
+		t.Fatalf("first scan result = %q, want Accepted", first[0].Result)
+	}
+
+	scans := []entity.CheckInScan{
+		scan("device-1", "A", scannedAt, ticket.Code),                      // Unggahan ulang scan yang sama
+		scan("device-2", "A", scannedAt.Add(time.Minute), ticket.Code),     // Dipindai lagi di gate yang sama
+		scan("device-3", "B", scannedAt.Add(2*time.Minute), ticket.Code),   // Dipakai di gate lain
+		scan("device-4", "C", scannedAt.Add(-time.Minute), ticket.Code),    // Mengaku lebih awal dari check-in
+		scan("device-5", "A", scannedAt.Add(3*time.Minute), "forged-code"), // Kode sudah diganti
+	}
+	if err := checkInRepo.RecordScans(event.ID, scans); err != nil {
+		t.Fatalf("RecordScans returned an error: %v", err)
+	}
+
+	want := []string{"Duplicate", "Duplicate", "Conflict", "Conflict", "Invalid"}
+	for i, result := range want {
+		if scans[i].Result != result {
+			t.Fatalf("scan %d result = %q, want %q (%s)", i, scans[i].Result, result, scans[i].Note)
+		}
+	}
+
+	// Unggahan ulang tidak dicatat dua kali
+	var stored int64
+	db.Model(&entity.CheckInScan{}).Where("event_id = ?", event.ID).Count(&stored)
+	if stored != 5 {
+		t.Fatalf("%d scans stored, want 5", stored)
+	}
+
+	var checkedIn entity.Ticket
+	if err := db.First(&checkedIn, ticket.ID).Error; err != nil {
+		t.Fatalf("failed to reload ticket: %v", err)
+	}
+	if checkedIn.CheckedInAt == nil || !checkedIn.CheckedInAt.Equal(scannedAt) || checkedIn.CheckInGate != "A" {
+		t.Fatalf("ticket checked in at %v gate %q, want %v gate A", checkedIn.CheckedInAt, checkedIn.CheckInGate, scannedAt)
+	}
+}
+
+func TestRecordScansFlagsCancelledTicket(t *testing.T) {
+	db := openTestDB(t)
+	event := createTestEvent(t, db, 10)
+	ticket := createPaidTicket(t, db, event, createTestUser(t, db).ID)
+
+	// Tiket dibatalkan setelah bundle diunduh oleh scanner
+	db.Model(&entity.Ticket{}).Where("id = ?", ticket.ID).Update("status", entity.TicketStatusCancelled)
+
+	scans := []entity.CheckInScan{{
+		EventID:   event.ID,
+		TicketID:  ticket.ID,
+		Code:      ticket.Code,
+		Gate:      "A",
+		DeviceID:  "device-1",
+		ScannedAt: time.Now(),
+	}}
+	if err := NewCheckInRepository(db).RecordScans(event.ID, scans); err != nil {
+		t.Fatalf("RecordScans returned an error: %v", err)
+	}
+
+	if scans[0].Result != "Conflict" {
+		t.Fatalf("scan result = %q, want Conflict", scans[0].Result)
+	}
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"errors"
+	"fmt"
 	"strings"
 	"time"
 
@@ -8,17 +10,27 @@ import (
 	"gorm.io/gorm"
 )
 
+var (
+	// ErrEventStatusChanged dikembalikan ketika status event berubah sebelum update ditulis
+	ErrEventStatusChanged = errors.New("event status changed while updating")
+	// ErrCapacityBelowSold dikembalikan ketika kapasitas baru lebih kecil dari tiket yang sudah terjual
+	ErrCapacityBelowSold = errors.New("capacity cannot be lower than the number of tickets already sold")
+)
+
 type EventRepository interface {
 	CreateEvent(event *entity.Event) error
 	FindEventByID(id int) (*entity.Event, error)
 	FindAllEvents() ([]entity.Event, error)
-	UpdateEvent(id int, event *entity.Event) error
+	UpdateEvent(id int, updates map[string]interface{}, capacity int) (bool, error)
 	DeleteEvent(id int) error
 	IsEventNameExists(name string) (bool, error)
 	SearchEvents(searchQuery string, minPrice, maxPrice int, category, status string, startDate, endDate time.Time) ([]entity.Event, error)
-	GetTotalEvents(startDate, endDate time.Time) (int64, error)
-	GetEventStatusDistribution(status string, startDate, endDate time.Time) (entity.EventStatusDistribution, error)
-	CancelEvent(eventID int) error
+	GetTotalEvents(startDate, endDate time.Time, organizerID int) (int64, error)
+	GetEventStatusDistribution(status entity.EventStatus, startDate, endDate time.Time, organizerID int) (entity.EventStatusDistribution, error)
+	CancelEvent(eventID int) ([]entity.Refund, error)
+	FindEventsDueForTransition(now time.Time) ([]entity.Event, error)
+	TransitionEventStatus(id int, from, to entity.EventStatus) (bool, error)
+	FindCalendarEventsByUserID(userID int) ([]entity.Event, error)
 }
 
 type eventRepository struct {
@@ -45,12 +57,49 @@ func (r *eventRepository) FindAllEvents() ([]entity.Event, error) {
 	return events, err
 }
 
-func (r *eventRepository) UpdateEvent(id int, event *entity.Event) error {
-	result := r.db.Model(&entity.Event{}).Where("id = ?", id).Updates(event)
-	if result.Error != nil {
-		return result.Error
+// UpdateEvent menulis kolom yang diubah di bawah lock event. Kapasitas baru (0 berarti
+// tidak berubah) mengubah stok secara relatif sehingga tiket yang terjual bersamaan
+// tetap terhitung. Nilai true dikembalikan jika kapasitas bertambah.
+func (r *eventRepository) UpdateEvent(id int, updates map[string]interface{}, capacity int) (bool, error) {
+	tx := r.db.Begin()
+
+	event, err := lockEvent(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	// Status bisa saja sudah diubah scheduler sejak dibaca oleh service
+	if status, ok := updates["status"].(entity.EventStatus); ok && status != event.Status && !event.Status.CanTransitionTo(status) {
+		tx.Rollback()
+		return false, fmt.Errorf("%w: event is now '%s'", ErrEventStatusChanged, event.Status)
 	}
-	return nil
+
+	capacityIncreased := false
+	if capacity != 0 && capacity != event.Capacity {
+		delta := capacity - event.Capacity
+		if event.AvailableTickets+delta < 0 {
+			tx.Rollback()
+			return false, ErrCapacityBelowSold
+		}
+
+		capacityIncreased = delta > 0
+		updates["capacity"] = capacity
+		updates["available_tickets"] = gorm.Expr("available_tickets + ?", delta)
+		updates["ticket_availability"] = entity.TicketAvailabilityAvailable
+		if event.AvailableTickets+delta == 0 {
+			updates["ticket_availability"] = entity.TicketAvailabilitySoldOut
+		}
+	}
+
+	if len(updates) > 0 {
+		if err := tx.Model(&entity.Event{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+			tx.Rollback()
+			return false, err
+		}
+	}
+
+	return capacityIncreased, tx.Commit().Error
 }
 
 func (r *eventRepository) DeleteEvent(id int) error {
@@ -101,10 +150,15 @@ func (r *eventRepository) SearchEvents(searchQuery string, minPrice, maxPrice in
 	return events, nil
 }
 
-func (r *eventRepository) GetTotalEvents(startDate, endDate time.Time) (int64, error) {
+func (r *eventRepository) GetTotalEvents(startDate, endDate time.Time, organizerID int) (int64, error) {
 	var totalEvent int64
 	query := r.db.Model(&entity.Event{})
 
+	// Batasi ke event milik organizer jika organizerID tidak nol
+	if organizerID != 0 {
+		query = query.Where("organizer_id = ?", organizerID)
+	}
+
 	// Tambahkan filter tanggal jika startDate atau endDate tidak kosong
 	if !startDate.IsZero() {
 		query = query.Where("date >= ?", startDate)
@@ -117,10 +171,15 @@ func (r *eventRepository) GetTotalEvents(startDate, endDate time.Time) (int64, e
 	return totalEvent, err
 }
 
-func (r *eventRepository) GetEventStatusDistribution(status string, startDate, endDate time.Time) (entity.EventStatusDistribution, error) {
+func (r *eventRepository) GetEventStatusDistribution(status entity.EventStatus, startDate, endDate time.Time, organizerID int) (entity.EventStatusDistribution, error) {
 	var distribution entity.EventStatusDistribution
 	query := r.db.Model(&entity.Event{}).Where("status = ?", status)
 
+	// Batasi ke event milik organizer jika organizerID tidak nol
+	if organizerID != 0 {
+		query = query.Where("organizer_id = ?", organizerID)
+	}
+
 	// Tambahkan filter tanggal jika startDate atau endDate tidak kosong
 	if !startDate.IsZero() {
 		query = query.Where("date >= ?", startDate)
@@ -134,24 +193,142 @@ func (r *eventRepository) GetEventStatusDistribution(status string, startDate, e
 	return distribution, err
 }
 
-func (r *eventRepository) CancelEvent(eventID int) error {
+func (r *eventRepository) CancelEvent(eventID int) ([]entity.Refund, error) {
 	// Mulai transaksi database
 	tx := r.db.Begin()
 
+	// Kunci event agar pembelian, hold dan update event tidak berjalan bersamaan dengan pembatalan
+	event, err := lockEvent(tx, eventID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Cek ulang di bawah lock karena status event bisa berubah sejak dibaca oleh service
+	if !event.Status.CanTransitionTo(entity.EventStatusCancelled) {
+		tx.Rollback()
+		return nil, ErrEventStatusChanged
+	}
+
 	// Update status event menjadi "cancelled"
 	if err := tx.Model(&entity.Event{}).Where("id = ?", eventID).
-		Update("status", "Dibatalkan").Error; err != nil {
+		Update("status", entity.EventStatusCancelled).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Event dibatalkan oleh penyelenggara, jadi semua tiket yang sudah dibeli dikembalikan penuh
+	var tickets []entity.Ticket
+	if err := tx.Where("event_id = ? AND status = ?", eventID, entity.TicketStatusPurchased).Find(&tickets).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	var orders []entity.Order
+	if err := tx.Where("event_id = ? AND resale_listing_id = ?", eventID, 0).Find(&orders).Error; err != nil {
 		tx.Rollback()
-		return err
+		return nil, err
+	}
+
+	buyers := make(map[int]int)
+	for _, order := range orders {
+		buyers[order.ID] = order.UserID
+	}
+
+	var refunds []entity.Refund
+	for _, ticket := range tickets {
+		refund := entity.Refund{
+			TicketID:   ticket.ID,
+			EventID:    ticket.EventID,
+			UserID:     ticket.UserID,
+			Amount:     ticket.UnitPrice - ticket.Discount,
+			Percentage: 100,
+			Currency:   ticket.Currency,
+			Reason:     "Event dibatalkan",
+			Status:     "Pending",
+		}
+
+		// Dana hanya dikembalikan ke pembayaran order jika tiket masih dipegang pembelinya.
+		// Refund tiket hasil transfer atau resale diproses manual oleh admin.
+		if ticket.OrderID != 0 && buyers[ticket.OrderID] == ticket.UserID {
+			refund.OrderID = ticket.OrderID
+		}
+
+		if err := tx.Create(&refund).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		refunds = append(refunds, refund)
 	}
 
-	// Update status semua tiket terkait menjadi "cancelled"
-	if err := tx.Model(&entity.Ticket{}).Where("event_id = ?", eventID).
-		Update("status", "Dibatalkan").Error; err != nil {
+	// Order yang belum dibayar digagalkan. Pembayaran yang masuk setelah ini
+	// ditolak oleh MarkPaid dan dananya dikembalikan.
+	if err := tx.Model(&entity.Order{}).
+		Where("event_id = ? AND resale_listing_id = ? AND status = ?", eventID, 0, entity.TicketStatusPendingPayment).
+		Update("status", entity.TicketStatusFailed).Error; err != nil {
 		tx.Rollback()
-		return err
+		return nil, err
+	}
+
+	// Tarik semua listing resale event ini dari marketplace
+	if err := withdrawEventListings(tx, eventID); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Hanya tiket yang sudah dibeli atau masih menunggu pembayaran yang dibatalkan,
+	// tiket yang sudah gagal atau dibatalkan sebelumnya tetap dengan statusnya
+	if err := tx.Model(&entity.Ticket{}).
+		Where("event_id = ? AND status IN ?", eventID,
+			[]entity.TicketStatus{entity.TicketStatusPurchased, entity.TicketStatusPendingPayment}).
+		Update("status", entity.TicketStatusCancelled).Error; err != nil {
+		tx.Rollback()
+		return nil, err
 	}
 
 	// Commit transaksi
-	return tx.Commit().Error
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return refunds, nil
+}
+
+// FindEventsDueForTransition mengambil event yang sudah dimulai tetapi masih dijual
+// dan event berlangsung yang waktu selesainya sudah lewat
+func (r *eventRepository) FindEventsDueForTransition(now time.Time) ([]entity.Event, error) {
+	var events []entity.Event
+	err := r.db.Where("status = ? AND date <= ?", entity.EventStatusActive, now).
+		Or("status = ? AND end_date <= ?", entity.EventStatusOngoing, now).
+		Order("date").Find(&events).Error
+	return events, err
+}
+
+// TransitionEventStatus mengubah status event hanya jika statusnya masih sama dengan
+// from, sehingga perubahan manual atau pembatalan yang terjadi bersamaan tidak tertimpa
+func (r *eventRepository) TransitionEventStatus(id int, from, to entity.EventStatus) (bool, error) {
+	result := r.db.Model(&entity.Event{}).
+		Where("id = ? AND status = ?", id, from).
+		Update("status", to)
+	return result.RowsAffected > 0, result.Error
+}
+
+// FindCalendarEventsByUserID mengambil event yang tiketnya dimiliki user. Event yang
+// dibatalkan tetap diikutkan walaupun tiketnya ikut dibatalkan, supaya kalender
+// pelanggan menerima status CANCELLED dan menghapus event tersebut.
+func (r *eventRepository) FindCalendarEventsByUserID(userID int) ([]entity.Event, error) {
+	var events []entity.Event
+	err := r.db.Distinct("events.*").
+		Joins("JOIN tickets ON tickets.event_id = events.id").
+		Where("tickets.user_id = ?", userID).
+		Where("tickets.status = ? OR (tickets.status = ? AND events.status = ?)",
+			entity.TicketStatusPurchased, entity.TicketStatusCancelled, entity.EventStatusCancelled).
+		Order("events.date").Find(&events).Error
+	return events, err
+}
+
+// organizerEventIDs adalah subquery ID event milik organizer, dipakai untuk membatasi
+// laporan pada tabel yang hanya menyimpan event_id
+func organizerEventIDs(db *gorm.DB, organizerID int) *gorm.DB {
+	return db.Model(&entity.Event{}).Select("id").Where("organizer_id = ?", organizerID)
 }
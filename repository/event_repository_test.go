@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+)
+
+func TestCancelEventRefundsOnlyPurchasedTickets(t *testing.T) {
+	db := openTestDB(t)
+	event := createTestEvent(t, db, 10)
+
+	buyer := createTestUser(t, db)
+	purchased := createPaidTicket(t, db, event, buyer.ID)
+
+	// Tiket yang sudah ditransfer ke user lain direfund manual, bukan ke pembayaran order
+	recipient := createTestUser(t, db)
+	transferred := createPaidTicket(t, db, event, buyer.ID)
+	db.Model(&entity.Ticket{}).Where("id = ?", transferred.ID).Update("user_id", recipient.ID)
+
+	pending := createTestOrder(t, db, event, createTestUser(t, db).ID, 1, "")
+
+	eventRepo := NewEventRepository(db)
+	refunds, err := eventRepo.CancelEvent(event.ID)
+	if err != nil {
+		t.Fatalf("CancelEvent returned an error: %v", err)
+	}
+
+	if len(refunds) != 2 {
+		t.Fatalf("created %d refunds, want 2", len(refunds))
+	}
+	for _, refund := range refunds {
+		switch refund.TicketID {
+		case purchased.ID:
+			if refund.OrderID != purchased.OrderID {
+				t.Fatalf("refund of the buyer's ticket has order %d, want %d", refund.OrderID, purchased.OrderID)
+			}
+		case transferred.ID:
+			if refund.OrderID != 0 {
+				t.Fatalf("refund of a transferred ticket has order %d, want a manual refund", refund.OrderID)
+			}
+		default:
+			t.Fatalf("unexpected refund for ticket %d", refund.TicketID)
+		}
+		if refund.Percentage != 100 {
+			t.Fatalf("refund percentage = %d, want 100", refund.Percentage)
+		}
+	}
+
+	if status := orderStatus(t, db, pending.ID); status != entity.TicketStatusFailed {
+		t.Fatalf("pending order status = %q, want %q", status, entity.TicketStatusFailed)
+	}
+
+	var active int64
+	db.Model(&entity.Ticket{}).Where("event_id = ? AND status IN ?", event.ID,
+		[]entity.TicketStatus{entity.TicketStatusPurchased, entity.TicketStatusPendingPayment}).Count(&active)
+	if active != 0 {
+		t.Fatalf("%d tickets are still active after the event was cancelled", active)
+	}
+
+	// Pembatalan kedua tidak boleh membuat refund lagi
+	if _, err := eventRepo.CancelEvent(event.ID); !errors.Is(err, ErrEventStatusChanged) {
+		t.Fatalf("second CancelEvent err = %v, want ErrEventStatusChanged", err)
+	}
+
+	var refundCount int64
+	db.Model(&entity.Refund{}).Where("event_id = ?", event.ID).Count(&refundCount)
+	if refundCount != 2 {
+		t.Fatalf("%d refunds stored, want 2", refundCount)
+	}
+}
+
+func TestUpdateEventCapacityIsRelativeToSoldTickets(t *testing.T) {
+	db := openTestDB(t)
+	event := createTestEvent(t, db, 10)
+	createTestOrder(t, db, event, createTestUser(t, db).ID, 4, "")
+
+	eventRepo := NewEventRepository(db)
+
+	// 4 tiket sudah terjual, kapasitas 3 tidak mungkin
+	if _, err := eventRepo.UpdateEvent(event.ID, map[string]interface{}{}, 3); !errors.Is(err, ErrCapacityBelowSold) {
+		t.Fatalf("UpdateEvent err = %v, want ErrCapacityBelowSold", err)
+	}
+
+	increased, err := eventRepo.UpdateEvent(event.ID, map[string]interface{}{}, 12)
+	if err != nil {
+		t.Fatalf("UpdateEvent returned an error: %v", err)
+	}
+	if !increased {
+		t.Fatal("expected the capacity increase to be reported")
+	}
+
+	stored := reloadEvent(t, db, event.ID)
+	if stored.Capacity != 12 || stored.AvailableTickets != 8 {
+		t.Fatalf("capacity = %d, available = %d, want 12 and 8", stored.Capacity, stored.AvailableTickets)
+	}
+
+	if _, err := eventRepo.UpdateEvent(event.ID, map[string]interface{}{}, 4); err != nil {
+		t.Fatalf("UpdateEvent to the sold count returned an error: %v", err)
+	}
+	stored = reloadEvent(t, db, event.ID)
+	if stored.AvailableTickets != 0 || stored.TicketAvailability != entity.TicketAvailabilitySoldOut {
+		t.Fatalf("available = %d, availability = %q, want 0 and sold out", stored.AvailableTickets, stored.TicketAvailability)
+	}
+}
@@ -0,0 +1,259 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type HoldRepository interface {
+	CreateHold(hold *entity.TicketHold, seatIDs []int) error
+	FindHoldByID(id int) (*entity.TicketHold, error)
+	ConfirmHold(holdID int, order *entity.Order) error
+	ReleaseExpiredHolds(now time.Time) (int, error)
+}
+
+type holdRepository struct {
+	db *gorm.DB
+}
+
+func NewHoldRepository(db *gorm.DB) *holdRepository {
+	return &holdRepository{db: db}
+}
+
+func (r *holdRepository) CreateHold(hold *entity.TicketHold, seatIDs []int) error {
+	tx := r.db.Begin()
+
+	event, err := lockEvent(tx, hold.EventID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := checkEventOnSale(event); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := checkPurchaseLimit(tx, event, hold.UserID, hold.Quantity); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := checkSeats(tx, event, seatIDs, hold.Quantity); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Stok langsung dikurangi saat hold dibuat dan dikembalikan jika hold kedaluwarsa
+	unitPrice, err := reserveTickets(tx, event, hold.TierID, hold.Quantity)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	hold.UnitPrice = unitPrice
+	hold.Currency = defaultCurrency
+
+	if err := tx.Create(hold).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Tahan kursi yang dipilih selama hold masih berlaku
+	for _, seatID := range seatIDs {
+		eventSeat := &entity.EventSeat{
+			EventID: hold.EventID,
+			SeatID:  seatID,
+			HoldID:  hold.ID,
+			Status:  "Held",
+		}
+		if err := tx.Create(eventSeat).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit().Error
+}
+
+func (r *holdRepository) FindHoldByID(id int) (*entity.TicketHold, error) {
+	var hold entity.TicketHold
+	err := r.db.Where("id = ?", id).First(&hold).Error
+	return &hold, err
+}
+
+func (r *holdRepository) ConfirmHold(holdID int, order *entity.Order) error {
+	// Event hold tidak pernah berubah, jadi bisa dibaca tanpa lock untuk mengetahui
+	// event mana yang harus dikunci lebih dulu
+	var eventID int
+	if err := r.db.Model(&entity.TicketHold{}).Where("id = ?", holdID).
+		Pluck("event_id", &eventID).Error; err != nil {
+		return err
+	}
+	if eventID == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	tx := r.db.Begin()
+
+	// Event selalu dikunci sebelum hold, sama seperti CreateHold dan sweeper,
+	// agar transaksi yang berjalan bersamaan tidak saling menunggu (deadlock).
+	// Hold tidak bisa dikonfirmasi lagi setelah event dimulai atau dibatalkan.
+	event, err := lockEvent(tx, eventID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Kunci hold agar tidak dikonfirmasi dua kali atau dilepas sweeper secara bersamaan
+	var hold entity.TicketHold
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", holdID).First(&hold).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if hold.Status != "Reserved" {
+		tx.Rollback()
+		return errors.New("hold is no longer reserved")
+	}
+
+	if !hold.ExpiresAt.After(time.Now()) {
+		tx.Rollback()
+		return errors.New("hold has expired")
+	}
+
+	if err := checkEventOnSale(event); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Pindahkan kursi yang ditahan ke tiket yang akan dibuat
+	var heldSeats []entity.EventSeat
+	if err := tx.Where("hold_id = ? AND status = ?", holdID, "Held").
+		Order("id").Find(&heldSeats).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for i := range heldSeats {
+		if i < len(order.Tickets) {
+			order.Tickets[i].SeatID = heldSeats[i].SeatID
+		}
+	}
+
+	// Stok sudah dikurangi dan harga sudah dikunci saat hold dibuat,
+	// jadi cukup buat order dan tiketnya
+	applyOrderPrice(order, hold.UnitPrice)
+	if err := tx.Create(order).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := issueTicketCodes(tx, order.Tickets); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for i := range heldSeats {
+		if i >= len(order.Tickets) {
+			break
+		}
+
+		if err := tx.Model(&entity.EventSeat{}).Where("id = ?", heldSeats[i].ID).
+			Updates(map[string]interface{}{"status": "Sold", "ticket_id": order.Tickets[i].ID}).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Model(&entity.TicketHold{}).Where("id = ?", holdID).
+		Updates(map[string]interface{}{"status": "Confirmed", "order_id": order.ID}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Jika hold berasal dari penawaran waitlist, tandai penawarannya sudah diklaim
+	if err := tx.Model(&entity.WaitlistEntry{}).Where("hold_id = ?", holdID).
+		Update("status", "Claimed").Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+func (r *holdRepository) ReleaseExpiredHolds(now time.Time) (int, error) {
+	// Cari event yang memiliki hold kedaluwarsa tanpa lock, lalu lepas hold per event.
+	// Event dikunci sebelum hold seperti di CreateHold dan ConfirmHold agar sweeper
+	// tidak deadlock dengan pembelian yang berjalan bersamaan.
+	var eventIDs []int
+	if err := r.db.Model(&entity.TicketHold{}).
+		Where("status = ? AND expires_at <= ?", "Reserved", now).
+		Distinct().Order("event_id").Pluck("event_id", &eventIDs).Error; err != nil {
+		return 0, err
+	}
+
+	released := 0
+	for _, eventID := range eventIDs {
+		count, err := r.releaseExpiredEventHolds(eventID, now)
+		if err != nil {
+			return released, err
+		}
+		released += count
+	}
+
+	return released, nil
+}
+
+func (r *holdRepository) releaseExpiredEventHolds(eventID int, now time.Time) (int, error) {
+	tx := r.db.Begin()
+
+	if _, err := lockEvent(tx, eventID); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	// Hold dibaca ulang di bawah lock karena bisa saja sudah dikonfirmasi
+	var holds []entity.TicketHold
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("event_id = ? AND status = ? AND expires_at <= ?", eventID, "Reserved", now).
+		Find(&holds).Error; err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	for _, hold := range holds {
+		if err := tx.Model(&entity.TicketHold{}).Where("id = ?", hold.ID).
+			Update("status", "Expired").Error; err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+
+		// Penawaran waitlist yang tidak diklaim ikut kedaluwarsa
+		if err := tx.Model(&entity.WaitlistEntry{}).Where("hold_id = ?", hold.ID).
+			Update("status", "Expired").Error; err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+
+		// Lepas kursi yang ditahan
+		if err := tx.Where("hold_id = ? AND status = ?", hold.ID, "Held").
+			Delete(&entity.EventSeat{}).Error; err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+
+		// Kembalikan stok yang ditahan ke event dan tier
+		if err := releaseTickets(tx, hold.EventID, hold.TierID, hold.Quantity); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+
+	return len(holds), tx.Commit().Error
+}
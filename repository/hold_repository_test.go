@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"gorm.io/gorm"
+)
+
+func createTestHold(t *testing.T, db *gorm.DB, event *entity.Event, userID, quantity int, expiresAt time.Time) *entity.TicketHold {
+	t.Helper()
+
+	hold := &entity.TicketHold{
+		EventID:   event.ID,
+		UserID:    userID,
+		Quantity:  quantity,
+		ExpiresAt: expiresAt,
+	}
+	if err := NewHoldRepository(db).CreateHold(hold, nil); err != nil {
+		t.Fatalf("failed to create hold: %v", err)
+	}
+
+	return hold
+}
+
+func TestConcurrentConfirmHoldConfirmsOnce(t *testing.T) {
+	db := openTestDB(t)
+	user := createTestUser(t, db)
+	event := createTestEvent(t, db, 10)
+	hold := createTestHold(t, db, event, user.ID, 2, time.Now().Add(10*time.Minute))
+
+	holdRepo := NewHoldRepository(db)
+
+	// Request konfirmasi yang sama dikirim berkali-kali secara bersamaan
+	const attempts = 5
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	confirmed := 0
+	start := make(chan struct{})
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+
+			if err := holdRepo.ConfirmHold(hold.ID, newTestOrder(event, user.ID, hold.Quantity)); err == nil {
+				mu.Lock()
+				confirmed++
+				mu.Unlock()
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	if confirmed != 1 {
+		t.Fatalf("hold was confirmed %d times, want 1", confirmed)
+	}
+
+	var orders int64
+	db.Model(&entity.Order{}).Where("event_id = ?", event.ID).Count(&orders)
+	if orders != 1 {
+		t.Fatalf("created %d orders, want 1", orders)
+	}
+
+	// Stok sudah dikurangi saat hold dibuat dan tidak boleh dikurangi lagi
+	if stored := reloadEvent(t, db, event.ID); stored.AvailableTickets != 8 {
+		t.Fatalf("available tickets = %d, want 8", stored.AvailableTickets)
+	}
+}
+
+func TestConfirmExpiredHoldFails(t *testing.T) {
+	db := openTestDB(t)
+	user := createTestUser(t, db)
+	event := createTestEvent(t, db, 10)
+	hold := createTestHold(t, db, event, user.ID, 1, time.Now().Add(time.Minute))
+
+	// Hold kedaluwarsa tetapi belum sempat dilepas sweeper
+	db.Model(&entity.TicketHold{}).Where("id = ?", hold.ID).Update("expires_at", time.Now().Add(-time.Second))
+
+	if err := NewHoldRepository(db).ConfirmHold(hold.ID, newTestOrder(event, user.ID, 1)); err == nil {
+		t.Fatal("expected an expired hold to be rejected")
+	}
+}
+
+func TestReleaseExpiredHoldsRestoresStock(t *testing.T) {
+	db := openTestDB(t)
+	user := createTestUser(t, db)
+	event := createTestEvent(t, db, 10)
+	expired := createTestHold(t, db, event, user.ID, 3, time.Now().Add(time.Minute))
+	active := createTestHold(t, db, event, user.ID, 2, time.Now().Add(time.Hour))
+
+	holdRepo := NewHoldRepository(db)
+	now := time.Now().Add(2 * time.Minute)
+
+	released, err := holdRepo.ReleaseExpiredHolds(now)
+	if err != nil {
+		t.Fatalf("ReleaseExpiredHolds returned an error: %v", err)
+	}
+	if released < 1 {
+		t.Fatalf("released %d holds, want at least 1", released)
+	}
+
+	if stored := reloadEvent(t, db, event.ID); stored.AvailableTickets != 8 {
+		t.Fatalf("available tickets = %d, want 8", stored.AvailableTickets)
+	}
+
+	// Sweeper yang berjalan lagi tidak boleh mengembalikan stok untuk kedua kalinya
+	if _, err := holdRepo.ReleaseExpiredHolds(now); err != nil {
+		t.Fatalf("second ReleaseExpiredHolds returned an error: %v", err)
+	}
+	if stored := reloadEvent(t, db, event.ID); stored.AvailableTickets != 8 {
+		t.Fatalf("available tickets after second sweep = %d, want 8", stored.AvailableTickets)
+	}
+
+	// Hold yang sudah dilepas tidak bisa dikonfirmasi, hold yang masih aktif bisa
+	if err := holdRepo.ConfirmHold(expired.ID, newTestOrder(event, user.ID, 3)); err == nil {
+		t.Fatal("expected a released hold to be rejected")
+	}
+	if err := holdRepo.ConfirmHold(active.ID, newTestOrder(event, user.ID, 2)); err != nil {
+		t.Fatalf("ConfirmHold of an active hold returned an error: %v", err)
+	}
+}
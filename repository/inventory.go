@@ -0,0 +1,234 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Mata uang yang dipakai untuk semua harga tiket
+const defaultCurrency = "IDR"
+
+// ErrNoAvailableTickets dikembalikan ketika stok event sudah habis
+var ErrNoAvailableTickets = errors.New("no available tickets for this event")
+
+// ErrEventNotOnSale dikembalikan ketika tiket dibeli untuk event yang sudah dimulai,
+// selesai atau dibatalkan
+var ErrEventNotOnSale = errors.New("tickets for this event are no longer on sale")
+
+// ErrPurchaseLimitExceeded dikembalikan ketika pembelian melebihi batas tiket per user
+var ErrPurchaseLimitExceeded = errors.New("ticket purchase limit per user exceeded for this event")
+
+// ErrTierSoldOut dikembalikan ketika stok tier tidak cukup untuk jumlah yang diminta
+var ErrTierSoldOut = errors.New("not enough available tickets for this tier")
+
+// lockEvent mengambil event dengan SELECT ... FOR UPDATE sehingga transaksi lain
+// yang ingin mengubah stok event yang sama harus menunggu sampai transaksi ini selesai.
+func lockEvent(tx *gorm.DB, eventID int) (*entity.Event, error) {
+	var event entity.Event
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", eventID).First(&event).Error
+	return &event, err
+}
+
+// checkEventOnSale memastikan event masih menjual tiket. Waktu event ikut dicek agar
+// penjualan langsung ditutup walaupun scheduler belum mengubah status event.
+func checkEventOnSale(event *entity.Event) error {
+	if event.Status != entity.EventStatusActive {
+		return ErrEventNotOnSale
+	}
+
+	if !time.Now().Before(event.Date) {
+		return ErrEventNotOnSale
+	}
+
+	return nil
+}
+
+// reserveEventTickets mengurangi AvailableTickets secara atomik. UPDATE hanya
+// berhasil jika stok masih cukup, sehingga stok tidak pernah bernilai negatif
+// walaupun ada pembelian yang berjalan bersamaan.
+func reserveEventTickets(tx *gorm.DB, eventID, quantity int) error {
+	result := tx.Model(&entity.Event{}).
+		Where("id = ? AND available_tickets >= ?", eventID, quantity).
+		Update("available_tickets", gorm.Expr("available_tickets - ?", quantity))
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New("not enough available tickets for this event")
+	}
+
+	// Jika AvailableTickets menjadi 0, tandai tiket event sudah habis
+	return tx.Model(&entity.Event{}).
+		Where("id = ? AND available_tickets = 0", eventID).
+		Update("ticket_availability", entity.TicketAvailabilitySoldOut).Error
+}
+
+// releaseEventTickets mengembalikan stok ke event, misalnya saat hold kedaluwarsa.
+func releaseEventTickets(tx *gorm.DB, eventID, quantity int) error {
+	if err := tx.Model(&entity.Event{}).Where("id = ?", eventID).
+		Update("available_tickets", gorm.Expr("available_tickets + ?", quantity)).Error; err != nil {
+		return err
+	}
+
+	// Tiket event yang sebelumnya habis kembali tersedia
+	return tx.Model(&entity.Event{}).
+		Where("id = ? AND available_tickets > 0", eventID).
+		Update("ticket_availability", entity.TicketAvailabilityAvailable).Error
+}
+
+// checkPurchaseLimit memastikan total tiket yang dibeli dan ditahan user untuk
+// sebuah event tidak melebihi MaxTicketsPerUser. Harus dipanggil setelah lockEvent.
+func checkPurchaseLimit(tx *gorm.DB, event *entity.Event, userID, quantity int) error {
+	if event.MaxTicketsPerUser <= 0 {
+		return nil
+	}
+
+	var purchased int64
+	if err := tx.Model(&entity.Ticket{}).
+		Where("event_id = ? AND user_id = ? AND status IN ?", event.ID, userID, []entity.TicketStatus{entity.TicketStatusPurchased, entity.TicketStatusPendingPayment}).
+		Count(&purchased).Error; err != nil {
+		return err
+	}
+
+	var held int64
+	if err := tx.Model(&entity.TicketHold{}).
+		Where("event_id = ? AND user_id = ? AND status = ?", event.ID, userID, "Reserved").
+		Select("COALESCE(SUM(quantity), 0)").Scan(&held).Error; err != nil {
+		return err
+	}
+
+	if int(purchased+held)+quantity > event.MaxTicketsPerUser {
+		return ErrPurchaseLimitExceeded
+	}
+
+	return nil
+}
+
+// reserveTickets mengurangi stok event dan, jika event memakai tier, stok tier
+// yang dipilih. Harga satuan yang berlaku saat itu dikembalikan agar bisa
+// disimpan pada tiket. Harus dipanggil setelah lockEvent.
+func reserveTickets(tx *gorm.DB, event *entity.Event, tierID, quantity int) (int, error) {
+	unitPrice := event.Price
+
+	if tierID == 0 {
+		var tierCount int64
+		if err := tx.Model(&entity.TicketTier{}).Where("event_id = ?", event.ID).
+			Count(&tierCount).Error; err != nil {
+			return 0, err
+		}
+
+		if tierCount > 0 {
+			return 0, errors.New("tier_id is required for this event")
+		}
+	} else {
+		tierPrice, err := reserveTierTickets(tx, event.ID, tierID, quantity)
+		if err != nil {
+			return 0, err
+		}
+		unitPrice = tierPrice
+	}
+
+	return unitPrice, reserveEventTickets(tx, event.ID, quantity)
+}
+
+// releaseTickets adalah kebalikan dari reserveTickets.
+func releaseTickets(tx *gorm.DB, eventID, tierID, quantity int) error {
+	if tierID != 0 {
+		if err := tx.Model(&entity.TicketTier{}).Where("id = ?", tierID).
+			Update("available_tickets", gorm.Expr("available_tickets + ?", quantity)).Error; err != nil {
+			return err
+		}
+	}
+
+	return releaseEventTickets(tx, eventID, quantity)
+}
+
+func reserveTierTickets(tx *gorm.DB, eventID, tierID, quantity int) (int, error) {
+	var tier entity.TicketTier
+	if err := tx.Where("id = ? AND event_id = ?", tierID, eventID).First(&tier).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, errors.New("ticket tier not found for this event")
+		}
+		return 0, err
+	}
+
+	// Cek apakah tier sedang dalam masa penjualan
+	now := time.Now()
+	if tier.SaleStart != nil && now.Before(*tier.SaleStart) {
+		return 0, errors.New("ticket tier sale has not started yet")
+	}
+	if tier.SaleEnd != nil && now.After(*tier.SaleEnd) {
+		return 0, errors.New("ticket tier sale has ended")
+	}
+
+	result := tx.Model(&entity.TicketTier{}).
+		Where("id = ? AND available_tickets >= ?", tierID, quantity).
+		Update("available_tickets", gorm.Expr("available_tickets - ?", quantity))
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return 0, ErrTierSoldOut
+	}
+
+	return tier.Price, nil
+}
+
+// checkSeats memastikan kursi yang dipilih milik venue event dan belum di-hold
+// atau terjual. Harus dipanggil setelah lockEvent agar tidak balapan.
+func checkSeats(tx *gorm.DB, event *entity.Event, seatIDs []int, quantity int) error {
+	if event.VenueID == 0 {
+		if len(seatIDs) > 0 {
+			return errors.New("this event does not use assigned seating")
+		}
+		return nil
+	}
+
+	if len(seatIDs) == 0 {
+		return errors.New("seat_ids are required for this event")
+	}
+
+	if len(seatIDs) != quantity {
+		return errors.New("number of seats must match the ticket quantity")
+	}
+
+	seen := make(map[int]bool)
+	for _, seatID := range seatIDs {
+		if seen[seatID] {
+			return errors.New("the same seat cannot be selected twice")
+		}
+		seen[seatID] = true
+	}
+
+	var found int64
+	if err := tx.Model(&entity.Seat{}).
+		Joins("JOIN sections ON seats.section_id = sections.id").
+		Where("seats.id IN ? AND sections.venue_id = ?", seatIDs, event.VenueID).
+		Count(&found).Error; err != nil {
+		return err
+	}
+
+	if int(found) != len(seatIDs) {
+		return errors.New("seat not found in this event's venue")
+	}
+
+	var taken int64
+	if err := tx.Model(&entity.EventSeat{}).
+		Where("event_id = ? AND seat_id IN ?", event.ID, seatIDs).
+		Count(&taken).Error; err != nil {
+		return err
+	}
+
+	if taken > 0 {
+		return errors.New("one or more seats are already taken")
+	}
+
+	return nil
+}
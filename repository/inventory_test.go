@@ -0,0 +1,334 @@
+package repository
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"github.com/Ayyasy123/dibimbing-take-home-test/utils"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Test ini membutuhkan database MySQL sungguhan karena yang diuji adalah row lock.
+// Isi TEST_DATABASE_DSN dengan database khusus test, misalnya
+// root:@tcp(127.0.0.1:3306)/dibimbing_test?charset=utf8mb4&parseTime=True&loc=Local
+func openTestDB(t *testing.T) *gorm.DB {
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN is not set")
+	}
+
+	if err := utils.SetTicketCodeSecret("test-ticket-code-secret-0123456789abcdef"); err != nil {
+		t.Fatalf("failed to set ticket code secret: %v", err)
+	}
+
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	err = db.AutoMigrate(
+		&entity.Event{},
+		&entity.TicketTier{},
+		&entity.Order{},
+		&entity.Ticket{},
+		&entity.TicketHold{},
+		&entity.EventSeat{},
+		&entity.User{},
+		&entity.WaitlistEntry{},
+		&entity.Payment{},
+		&entity.Refund{},
+		&entity.PromoCode{},
+		&entity.PromoRedemption{},
+		&entity.TicketTransfer{},
+		&entity.ResaleListing{},
+		&entity.ResalePayout{},
+		&entity.CheckInScan{},
+		&entity.RefreshToken{},
+		&entity.AccountToken{},
+		&entity.RecoveryCode{},
+		&entity.Invitation{},
+		&entity.RoleGrant{},
+	)
+	if err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	return db
+}
+
+// createTestUser membuat user dengan email unik yang dihapus setelah test selesai
+func createTestUser(t *testing.T, db *gorm.DB) *entity.User {
+	t.Helper()
+
+	user := &entity.User{
+		Name:  "Test User",
+		Email: fmt.Sprintf("test-%d@example.com", time.Now().UnixNano()),
+		Role:  "user",
+	}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	t.Cleanup(func() {
+		db.Where("user_id = ?", user.ID).Delete(&entity.RefreshToken{})
+		db.Where("user_id = ?", user.ID).Delete(&entity.AccountToken{})
+		db.Where("user_id = ?", user.ID).Delete(&entity.RecoveryCode{})
+		db.Where("user_id = ?", user.ID).Delete(&entity.RoleGrant{})
+		db.Delete(user)
+	})
+
+	return user
+}
+
+// createTestEvent membuat event yang sedang dijual tanpa tier. Semua data yang dibuat
+// untuk event ini dihapus setelah test selesai.
+func createTestEvent(t *testing.T, db *gorm.DB, capacity int) *entity.Event {
+	t.Helper()
+
+	event := &entity.Event{
+		Name:             fmt.Sprintf("Test Event %d", time.Now().UnixNano()),
+		Date:             time.Now().Add(24 * time.Hour),
+		EndDate:          time.Now().Add(48 * time.Hour),
+		Capacity:         capacity,
+		AvailableTickets: capacity,
+		Price:            100000,
+		Status:           entity.EventStatusActive,
+	}
+	if err := db.Create(event).Error; err != nil {
+		t.Fatalf("failed to create event: %v", err)
+	}
+
+	t.Cleanup(func() {
+		orderIDs := db.Model(&entity.Order{}).Select("id").Where("event_id = ?", event.ID)
+		ticketIDs := db.Model(&entity.Ticket{}).Select("id").Where("event_id = ?", event.ID)
+
+		db.Where("order_id IN (?)", orderIDs).Delete(&entity.Payment{})
+		db.Where("order_id IN (?)", orderIDs).Delete(&entity.ResalePayout{})
+		db.Where("ticket_id IN (?)", ticketIDs).Delete(&entity.TicketTransfer{})
+		db.Where("event_id = ?", event.ID).Delete(&entity.PromoRedemption{})
+		db.Where("event_id = ?", event.ID).Delete(&entity.PromoCode{})
+		db.Where("event_id = ?", event.ID).Delete(&entity.Refund{})
+		db.Where("event_id = ?", event.ID).Delete(&entity.ResaleListing{})
+		db.Where("event_id = ?", event.ID).Delete(&entity.CheckInScan{})
+		db.Where("event_id = ?", event.ID).Delete(&entity.WaitlistEntry{})
+		db.Where("event_id = ?", event.ID).Delete(&entity.EventSeat{})
+		db.Where("event_id = ?", event.ID).Delete(&entity.TicketHold{})
+		db.Where("event_id = ?", event.ID).Delete(&entity.Ticket{})
+		db.Where("event_id = ?", event.ID).Delete(&entity.Order{})
+		db.Where("event_id = ?", event.ID).Delete(&entity.TicketTier{})
+		db.Delete(event)
+	})
+
+	return event
+}
+
+// createTestOrder memesan tiket lewat CreateOrder seperti pembelian biasa
+func createTestOrder(t *testing.T, db *gorm.DB, event *entity.Event, userID, quantity int, promoCode string) *entity.Order {
+	t.Helper()
+
+	order := newTestOrder(event, userID, quantity)
+	if err := NewOrderRepository(db).CreateOrder(order, promoCode); err != nil {
+		t.Fatalf("failed to create order: %v", err)
+	}
+
+	return order
+}
+
+func newTestOrder(event *entity.Event, userID, quantity int) *entity.Order {
+	order := &entity.Order{
+		EventID:  event.ID,
+		UserID:   userID,
+		Quantity: quantity,
+		Status:   entity.TicketStatusPendingPayment,
+	}
+	for i := 0; i < quantity; i++ {
+		order.Tickets = append(order.Tickets, entity.Ticket{
+			EventID: event.ID,
+			UserID:  userID,
+			Status:  entity.TicketStatusPendingPayment,
+		})
+	}
+	return order
+}
+
+// createTestPayment menyimpan pembayaran Pending untuk order
+func createTestPayment(t *testing.T, db *gorm.DB, order *entity.Order) *entity.Payment {
+	t.Helper()
+
+	payment := &entity.Payment{
+		OrderID:  order.ID,
+		Provider: "mock",
+		ChargeID: fmt.Sprintf("test_ch_%d", order.ID),
+		Amount:   order.TotalPrice,
+		Currency: "IDR",
+		Status:   "Pending",
+	}
+	if err := NewPaymentRepository(db).CreatePayment(payment); err != nil {
+		t.Fatalf("failed to create payment: %v", err)
+	}
+
+	return payment
+}
+
+// createPaidTicket membeli dan membayar satu tiket untuk user
+func createPaidTicket(t *testing.T, db *gorm.DB, event *entity.Event, userID int) *entity.Ticket {
+	t.Helper()
+
+	order := createTestOrder(t, db, event, userID, 1, "")
+	payment := createTestPayment(t, db, order)
+	if err := NewPaymentRepository(db).MarkPaid(payment.ID); err != nil {
+		t.Fatalf("failed to pay order: %v", err)
+	}
+
+	var ticket entity.Ticket
+	if err := db.Where("order_id = ?", order.ID).First(&ticket).Error; err != nil {
+		t.Fatalf("failed to load ticket: %v", err)
+	}
+
+	return &ticket
+}
+
+// reloadEvent membaca ulang stok event dari database
+func reloadEvent(t *testing.T, db *gorm.DB, id int) *entity.Event {
+	t.Helper()
+
+	var event entity.Event
+	if err := db.First(&event, id).Error; err != nil {
+		t.Fatalf("failed to reload event: %v", err)
+	}
+
+	return &event
+}
+
+func TestConcurrentReservationsNeverOversell(t *testing.T) {
+	db := openTestDB(t)
+
+	const capacity = 20
+	const buyers = 60
+
+	event := &entity.Event{
+		Name:             "Concurrency Test",
+		Date:             time.Now().Add(24 * time.Hour),
+		EndDate:          time.Now().Add(48 * time.Hour),
+		Capacity:         capacity,
+		AvailableTickets: capacity,
+		Price:            100000,
+		Status:           entity.EventStatusActive,
+	}
+	if err := db.Create(event).Error; err != nil {
+		t.Fatalf("failed to create event: %v", err)
+	}
+
+	tier := &entity.TicketTier{
+		EventID:          event.ID,
+		Name:             "Regular",
+		Price:            100000,
+		Capacity:         capacity,
+		AvailableTickets: capacity,
+	}
+	if err := db.Create(tier).Error; err != nil {
+		t.Fatalf("failed to create tier: %v", err)
+	}
+
+	t.Cleanup(func() {
+		db.Where("event_id = ?", event.ID).Delete(&entity.Ticket{})
+		db.Where("event_id = ?", event.ID).Delete(&entity.TicketHold{})
+		db.Where("event_id = ?", event.ID).Delete(&entity.Order{})
+		db.Delete(tier)
+		db.Delete(event)
+	})
+
+	orderRepo := NewOrderRepository(db)
+	holdRepo := NewHoldRepository(db)
+
+	// Setengah buyer membeli langsung dan setengah lagi membuat hold, dengan jumlah
+	// tiket yang berbeda-beda agar stok habis di tengah jalan
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i := 0; i < buyers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+
+			userID := 1000 + i
+			quantity := i%3 + 1
+			if i%2 == 0 {
+				order := &entity.Order{
+					EventID:  event.ID,
+					TierID:   tier.ID,
+					UserID:   userID,
+					Quantity: quantity,
+					Status:   entity.TicketStatusPendingPayment,
+				}
+				for j := 0; j < quantity; j++ {
+					order.Tickets = append(order.Tickets, entity.Ticket{
+						EventID: event.ID,
+						TierID:  tier.ID,
+						UserID:  userID,
+						Status:  entity.TicketStatusPendingPayment,
+					})
+				}
+				_ = orderRepo.CreateOrder(order, "")
+				return
+			}
+
+			hold := &entity.TicketHold{
+				EventID:   event.ID,
+				TierID:    tier.ID,
+				UserID:    userID,
+				Quantity:  quantity,
+				ExpiresAt: time.Now().Add(10 * time.Minute),
+			}
+			_ = holdRepo.CreateHold(hold, nil)
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	var storedEvent entity.Event
+	if err := db.First(&storedEvent, event.ID).Error; err != nil {
+		t.Fatalf("failed to reload event: %v", err)
+	}
+
+	var storedTier entity.TicketTier
+	if err := db.First(&storedTier, tier.ID).Error; err != nil {
+		t.Fatalf("failed to reload tier: %v", err)
+	}
+
+	if storedEvent.AvailableTickets < 0 {
+		t.Fatalf("event available tickets went negative: %d", storedEvent.AvailableTickets)
+	}
+	if storedTier.AvailableTickets < 0 {
+		t.Fatalf("tier available tickets went negative: %d", storedTier.AvailableTickets)
+	}
+
+	var sold int64
+	if err := db.Model(&entity.Ticket{}).Where("event_id = ?", event.ID).Count(&sold).Error; err != nil {
+		t.Fatalf("failed to count tickets: %v", err)
+	}
+
+	var held int64
+	if err := db.Model(&entity.TicketHold{}).Where("event_id = ? AND status = ?", event.ID, "Reserved").
+		Select("COALESCE(SUM(quantity), 0)").Scan(&held).Error; err != nil {
+		t.Fatalf("failed to count holds: %v", err)
+	}
+
+	if sold+held > capacity {
+		t.Fatalf("sold %d and held %d tickets, more than the capacity of %d", sold, held, capacity)
+	}
+
+	// Setiap tiket yang terjual atau ditahan harus tercatat sebagai stok yang berkurang
+	if int(sold+held) != capacity-storedEvent.AvailableTickets {
+		t.Fatalf("event stock is %d but %d tickets were sold or held", storedEvent.AvailableTickets, sold+held)
+	}
+	if int(sold+held) != capacity-storedTier.AvailableTickets {
+		t.Fatalf("tier stock is %d but %d tickets were sold or held", storedTier.AvailableTickets, sold+held)
+	}
+}
@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OrderRepository interface {
+	CreateOrder(order *entity.Order, promoCode string) error
+	FindOrderByID(id int) (*entity.Order, error)
+	FailPendingOrder(id int) error
+}
+
+type orderRepository struct {
+	db *gorm.DB
+}
+
+func NewOrderRepository(db *gorm.DB) *orderRepository {
+	return &orderRepository{db: db}
+}
+
+func (r *orderRepository) CreateOrder(order *entity.Order, promoCode string) error {
+	// Mulai transaksi database, semua tiket dalam order dibuat atau tidak sama sekali
+	tx := r.db.Begin()
+
+	// Kunci baris event agar pengecekan stok dan batas pembelian tidak balapan
+	// dengan transaksi lain untuk event yang sama
+	event, err := lockEvent(tx, order.EventID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := checkEventOnSale(event); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if event.AvailableTickets <= 0 {
+		tx.Rollback()
+		return ErrNoAvailableTickets
+	}
+
+	if event.AvailableTickets < order.Quantity {
+		tx.Rollback()
+		return errors.New("not enough available tickets for this event")
+	}
+
+	// Cek batas pembelian tiket per user untuk event ini
+	if err := checkPurchaseLimit(tx, event, order.UserID, order.Quantity); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Pastikan kursi yang dipilih (untuk event dengan seat map) masih tersedia
+	var seatIDs []int
+	for _, ticket := range order.Tickets {
+		if ticket.SeatID != 0 {
+			seatIDs = append(seatIDs, ticket.SeatID)
+		}
+	}
+
+	if err := checkSeats(tx, event, seatIDs, order.Quantity); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Kurangi stok event (dan tier jika ada) sebanyak jumlah tiket dalam order
+	unitPrice, err := reserveTickets(tx, event, order.TierID, order.Quantity)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Terapkan potongan promo code pada setiap tiket dalam order
+	var promo *entity.PromoCode
+	if promoCode != "" {
+		if err := lockPromoCode(tx, promoCode); err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		promo, err = findValidPromoCode(tx, promoCode, order.EventID, order.UserID)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		discount := promoDiscount(promo, unitPrice)
+		for i := range order.Tickets {
+			order.Tickets[i].Discount = discount
+		}
+		order.PromoCodeID = promo.ID
+	}
+
+	// Simpan harga yang dibayar pada setiap tiket agar laporan tidak berubah
+	// ketika harga event atau tier diubah di kemudian hari
+	applyOrderPrice(order, unitPrice)
+
+	// Buat order beserta seluruh tiketnya
+	if err := tx.Create(order).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := issueTicketCodes(tx, order.Tickets); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if promo != nil {
+		if err := redeemPromoCode(tx, promo, order); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	// Tandai kursi sebagai terjual untuk event ini
+	for _, ticket := range order.Tickets {
+		if ticket.SeatID == 0 {
+			continue
+		}
+
+		eventSeat := &entity.EventSeat{
+			EventID:  order.EventID,
+			SeatID:   ticket.SeatID,
+			TicketID: ticket.ID,
+			Status:   "Sold",
+		}
+		if err := tx.Create(eventSeat).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	// Commit transaksi
+	return tx.Commit().Error
+}
+
+func (r *orderRepository) FindOrderByID(id int) (*entity.Order, error) {
+	var order entity.Order
+	err := r.db.Preload("Tickets").Where("id = ?", id).First(&order).Error
+	return &order, err
+}
+
+// FailPendingOrder menggagalkan order yang belum memiliki pembayaran, misalnya karena
+// data pembayaran gagal disimpan, agar stok yang sudah dipesan tidak tertahan selamanya
+func (r *orderRepository) FailPendingOrder(id int) error {
+	tx := r.db.Begin()
+
+	var order entity.Order
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", id).First(&order).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if order.Status != entity.TicketStatusPendingPayment {
+		tx.Rollback()
+		return nil
+	}
+
+	if err := failOrder(tx, &order); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// applyOrderPrice menyimpan harga satuan pada setiap tiket dan menghitung total order
+func applyOrderPrice(order *entity.Order, unitPrice int) {
+	order.TotalPrice = 0
+	order.Discount = 0
+	order.Currency = defaultCurrency
+	for i := range order.Tickets {
+		order.Tickets[i].UnitPrice = unitPrice
+		order.Tickets[i].Currency = defaultCurrency
+		order.TotalPrice += unitPrice - order.Tickets[i].Discount
+		order.Discount += order.Tickets[i].Discount
+	}
+}
@@ -0,0 +1,275 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrOrderNotPayable dikembalikan ketika dana masuk untuk order yang sudah tidak berlaku,
+// misalnya karena event dibatalkan atau listing resale sudah ditarik
+var ErrOrderNotPayable = errors.New("order can no longer be paid")
+
+type PaymentRepository interface {
+	CreatePayment(payment *entity.Payment) error
+	UpdateCharge(id int, chargeID, checkoutURL string) error
+	FindPaymentByChargeID(chargeID string) (*entity.Payment, error)
+	FindPaymentByOrderID(orderID int) (*entity.Payment, error)
+	MarkPaid(id int) error
+	MarkFailed(id int) error
+	MarkRefunded(id int) error
+	AddRefundedAmount(id, amount int) error
+	FindPendingPaymentsBefore(before time.Time) ([]entity.Payment, error)
+}
+
+type paymentRepository struct {
+	db *gorm.DB
+}
+
+func NewPaymentRepository(db *gorm.DB) *paymentRepository {
+	return &paymentRepository{db: db}
+}
+
+func (r *paymentRepository) CreatePayment(payment *entity.Payment) error {
+	return r.db.Create(payment).Error
+}
+
+func (r *paymentRepository) UpdateCharge(id int, chargeID, checkoutURL string) error {
+	return r.db.Model(&entity.Payment{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"charge_id": chargeID, "checkout_url": checkoutURL}).Error
+}
+
+func (r *paymentRepository) FindPaymentByChargeID(chargeID string) (*entity.Payment, error) {
+	var payment entity.Payment
+	err := r.db.Where("charge_id = ?", chargeID).First(&payment).Error
+	return &payment, err
+}
+
+func (r *paymentRepository) FindPaymentByOrderID(orderID int) (*entity.Payment, error) {
+	var payment entity.Payment
+	err := r.db.Where("order_id = ?", orderID).Order("id DESC").First(&payment).Error
+	return &payment, err
+}
+
+// MarkPaid menandai pembayaran berhasil sekaligus menerbitkan tiket pada order terkait,
+// atau memindahkan tiket ke pembeli untuk order resale.
+func (r *paymentRepository) MarkPaid(id int) error {
+	tx := r.db.Begin()
+
+	payment, err := lockPendingPayment(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Model(&entity.Payment{}).Where("id = ?", id).
+		Update("status", "Paid").Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var order entity.Order
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", payment.OrderID).First(&order).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Order yang sudah gagal atau dibatalkan tidak boleh hidup kembali karena webhook terlambat
+	if order.Status != entity.TicketStatusPendingPayment {
+		tx.Rollback()
+		return ErrOrderNotPayable
+	}
+
+	if err := tx.Model(&entity.Order{}).Where("id = ?", order.ID).
+		Update("status", entity.TicketStatusPurchased).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Order resale tidak menerbitkan tiket baru, tiket penjual dipindahkan ke pembeli
+	if order.ResaleListingID != 0 {
+		if err := completeResale(tx, &order); err != nil {
+			tx.Rollback()
+			if errors.Is(err, ErrListingNotActive) {
+				return ErrOrderNotPayable
+			}
+			return err
+		}
+		return tx.Commit().Error
+	}
+
+	var event entity.Event
+	if err := tx.Select("id", "status").Where("id = ?", order.EventID).First(&event).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if event.Status == entity.EventStatusCancelled {
+		tx.Rollback()
+		return ErrOrderNotPayable
+	}
+
+	// Hanya tiket yang masih menunggu pembayaran yang diterbitkan. Jika ada tiket order
+	// yang sudah berubah status, order dianggap tidak berlaku lagi.
+	result := tx.Model(&entity.Ticket{}).
+		Where("order_id = ? AND status = ?", order.ID, entity.TicketStatusPendingPayment).
+		Update("status", entity.TicketStatusPurchased)
+	if result.Error != nil {
+		tx.Rollback()
+		return result.Error
+	}
+
+	if int(result.RowsAffected) != order.Quantity {
+		tx.Rollback()
+		return ErrOrderNotPayable
+	}
+
+	return tx.Commit().Error
+}
+
+// MarkRefunded menandai pembayaran yang dananya sudah dikembalikan seluruhnya
+// karena ordernya tidak berlaku lagi, dan menggagalkan order jika masih menunggu pembayaran.
+func (r *paymentRepository) MarkRefunded(id int) error {
+	tx := r.db.Begin()
+
+	payment, err := lockPendingPayment(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Model(&entity.Payment{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"status": "Refunded", "refunded_amount": payment.Amount}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Model(&entity.Order{}).
+		Where("id = ? AND status = ?", payment.OrderID, entity.TicketStatusPendingPayment).
+		Update("status", entity.TicketStatusFailed).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// MarkFailed menandai pembayaran gagal, menggagalkan order terkait dan
+// mengembalikan stok serta kursi yang sudah dipesan.
+func (r *paymentRepository) MarkFailed(id int) error {
+	tx := r.db.Begin()
+
+	payment, err := lockPendingPayment(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Model(&entity.Payment{}).Where("id = ?", id).
+		Update("status", "Failed").Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var order entity.Order
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", payment.OrderID).First(&order).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Order yang sudah digagalkan lebih dulu, misalnya karena event dibatalkan,
+	// tidak boleh mengembalikan stok atau membuka listing-nya untuk kedua kalinya
+	if order.Status == entity.TicketStatusPendingPayment {
+		if err := failOrder(tx, &order); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit().Error
+}
+
+// failOrder menggagalkan order beserta tiketnya dan mengembalikan stok, kursi dan
+// kuota promo yang sudah dipesan. Untuk order resale listing-nya dibuka kembali.
+func failOrder(tx *gorm.DB, order *entity.Order) error {
+	if err := tx.Model(&entity.Order{}).Where("id = ?", order.ID).
+		Update("status", entity.TicketStatusFailed).Error; err != nil {
+		return err
+	}
+
+	// Order resale tidak memesan stok, cukup buka kembali listing-nya
+	if order.ResaleListingID != 0 {
+		return releaseResaleListing(tx, order)
+	}
+
+	if err := tx.Model(&entity.Ticket{}).Where("order_id = ?", order.ID).
+		Update("status", entity.TicketStatusFailed).Error; err != nil {
+		return err
+	}
+
+	if _, err := lockEvent(tx, order.EventID); err != nil {
+		return err
+	}
+
+	if err := releaseTickets(tx, order.EventID, order.TierID, order.Quantity); err != nil {
+		return err
+	}
+
+	if err := tx.Where("ticket_id IN (?)", tx.Model(&entity.Ticket{}).Select("id").Where("order_id = ?", order.ID)).
+		Delete(&entity.EventSeat{}).Error; err != nil {
+		return err
+	}
+
+	// Kuota promo code dari order yang gagal dikembalikan
+	return releasePromoRedemption(tx, order)
+}
+
+func (r *paymentRepository) AddRefundedAmount(id, amount int) error {
+	tx := r.db.Begin()
+
+	var payment entity.Payment
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", id).First(&payment).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	updates := map[string]interface{}{"refunded_amount": payment.RefundedAmount + amount}
+
+	// Pembayaran dianggap Refunded jika seluruh dananya sudah dikembalikan
+	if payment.RefundedAmount+amount >= payment.Amount {
+		updates["status"] = "Refunded"
+	}
+
+	if err := tx.Model(&entity.Payment{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+func (r *paymentRepository) FindPendingPaymentsBefore(before time.Time) ([]entity.Payment, error) {
+	var payments []entity.Payment
+	err := r.db.Where("status = ? AND created_at <= ?", "Pending", before).Find(&payments).Error
+	return payments, err
+}
+
+func lockPendingPayment(tx *gorm.DB, id int) (*entity.Payment, error) {
+	var payment entity.Payment
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", id).First(&payment).Error; err != nil {
+		return nil, err
+	}
+
+	if payment.Status != "Pending" {
+		return nil, errors.New("payment is no longer pending")
+	}
+
+	return &payment, nil
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"gorm.io/gorm"
+)
+
+func paymentStatus(t *testing.T, db *gorm.DB, id int) string {
+	t.Helper()
+
+	var payment entity.Payment
+	if err := db.First(&payment, id).Error; err != nil {
+		t.Fatalf("failed to reload payment: %v", err)
+	}
+
+	return payment.Status
+}
+
+func orderStatus(t *testing.T, db *gorm.DB, id int) entity.TicketStatus {
+	t.Helper()
+
+	var order entity.Order
+	if err := db.First(&order, id).Error; err != nil {
+		t.Fatalf("failed to reload order: %v", err)
+	}
+
+	return order.Status
+}
+
+func TestMarkPaidIssuesTicketsOnce(t *testing.T) {
+	db := openTestDB(t)
+	user := createTestUser(t, db)
+	event := createTestEvent(t, db, 10)
+	order := createTestOrder(t, db, event, user.ID, 2, "")
+	payment := createTestPayment(t, db, order)
+
+	paymentRepo := NewPaymentRepository(db)
+	if err := paymentRepo.MarkPaid(payment.ID); err != nil {
+		t.Fatalf("MarkPaid returned an error: %v", err)
+	}
+
+	// Webhook yang dikirim ulang tidak boleh memproses pembayaran untuk kedua kalinya
+	if err := paymentRepo.MarkPaid(payment.ID); err == nil {
+		t.Fatal("expected a second MarkPaid to fail")
+	}
+	if err := paymentRepo.MarkFailed(payment.ID); err == nil {
+		t.Fatal("expected MarkFailed of a paid payment to fail")
+	}
+
+	var purchased int64
+	db.Model(&entity.Ticket{}).Where("order_id = ? AND status = ?", order.ID, entity.TicketStatusPurchased).Count(&purchased)
+	if purchased != 2 {
+		t.Fatalf("%d tickets were issued, want 2", purchased)
+	}
+
+	if stored := reloadEvent(t, db, event.ID); stored.AvailableTickets != 8 {
+		t.Fatalf("available tickets = %d, want 8", stored.AvailableTickets)
+	}
+}
+
+func TestMarkPaidRejectsOrderOfCancelledEvent(t *testing.T) {
+	db := openTestDB(t)
+	user := createTestUser(t, db)
+	event := createTestEvent(t, db, 10)
+	order := createTestOrder(t, db, event, user.ID, 1, "")
+	payment := createTestPayment(t, db, order)
+
+	if _, err := NewEventRepository(db).CancelEvent(event.ID); err != nil {
+		t.Fatalf("CancelEvent returned an error: %v", err)
+	}
+
+	// Pembayaran yang masuk setelah event dibatalkan tidak boleh menerbitkan tiket
+	paymentRepo := NewPaymentRepository(db)
+	if err := paymentRepo.MarkPaid(payment.ID); !errors.Is(err, ErrOrderNotPayable) {
+		t.Fatalf("MarkPaid err = %v, want ErrOrderNotPayable", err)
+	}
+
+	// Pembayaran tetap Pending agar payment service bisa mengembalikan dananya
+	if status := paymentStatus(t, db, payment.ID); status != "Pending" {
+		t.Fatalf("payment status = %q, want Pending", status)
+	}
+
+	if err := paymentRepo.MarkRefunded(payment.ID); err != nil {
+		t.Fatalf("MarkRefunded returned an error: %v", err)
+	}
+	if status := paymentStatus(t, db, payment.ID); status != "Refunded" {
+		t.Fatalf("payment status = %q, want Refunded", status)
+	}
+	if status := orderStatus(t, db, order.ID); status != entity.TicketStatusFailed {
+		t.Fatalf("order status = %q, want %q", status, entity.TicketStatusFailed)
+	}
+}
+
+func TestMarkFailedAfterCancelDoesNotReleaseStockTwice(t *testing.T) {
+	db := openTestDB(t)
+	user := createTestUser(t, db)
+	event := createTestEvent(t, db, 10)
+	order := createTestOrder(t, db, event, user.ID, 2, "")
+	payment := createTestPayment(t, db, order)
+
+	if _, err := NewEventRepository(db).CancelEvent(event.ID); err != nil {
+		t.Fatalf("CancelEvent returned an error: %v", err)
+	}
+	before := reloadEvent(t, db, event.ID).AvailableTickets
+
+	// Pembayaran kedaluwarsa setelah ordernya digagalkan oleh pembatalan event
+	if err := NewPaymentRepository(db).MarkFailed(payment.ID); err != nil {
+		t.Fatalf("MarkFailed returned an error: %v", err)
+	}
+
+	if status := paymentStatus(t, db, payment.ID); status != "Failed" {
+		t.Fatalf("payment status = %q, want Failed", status)
+	}
+	if after := reloadEvent(t, db, event.ID).AvailableTickets; after != before {
+		t.Fatalf("available tickets changed from %d to %d", before, after)
+	}
+}
+
+func TestConcurrentMarkPaidAndMarkFailed(t *testing.T) {
+	db := openTestDB(t)
+	user := createTestUser(t, db)
+	event := createTestEvent(t, db, 10)
+	order := createTestOrder(t, db, event, user.ID, 2, "")
+	payment := createTestPayment(t, db, order)
+
+	paymentRepo := NewPaymentRepository(db)
+
+	// Webhook sukses datang bersamaan dengan sweeper pembayaran kedaluwarsa
+	var wg sync.WaitGroup
+	var paidErr, failedErr error
+	start := make(chan struct{})
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		<-start
+		paidErr = paymentRepo.MarkPaid(payment.ID)
+	}()
+	go func() {
+		defer wg.Done()
+		<-start
+		failedErr = paymentRepo.MarkFailed(payment.ID)
+	}()
+	close(start)
+	wg.Wait()
+
+	if (paidErr == nil) == (failedErr == nil) {
+		t.Fatalf("exactly one transition must succeed, MarkPaid err = %v, MarkFailed err = %v", paidErr, failedErr)
+	}
+
+	stored := reloadEvent(t, db, event.ID)
+	var tickets []entity.Ticket
+	db.Where("order_id = ?", order.ID).Find(&tickets)
+
+	wantPayment, wantOrder, wantStock := "Paid", entity.TicketStatusPurchased, 8
+	if paidErr != nil {
+		wantPayment, wantOrder, wantStock = "Failed", entity.TicketStatusFailed, 10
+	}
+
+	if status := paymentStatus(t, db, payment.ID); status != wantPayment {
+		t.Fatalf("payment status = %q, want %q", status, wantPayment)
+	}
+	if status := orderStatus(t, db, order.ID); status != wantOrder {
+		t.Fatalf("order status = %q, want %q", status, wantOrder)
+	}
+	for _, ticket := range tickets {
+		if ticket.Status != wantOrder {
+			t.Fatalf("ticket %d status = %q, want %q", ticket.ID, ticket.Status, wantOrder)
+		}
+	}
+	if stored.AvailableTickets != wantStock {
+		t.Fatalf("available tickets = %d, want %d", stored.AvailableTickets, wantStock)
+	}
+}
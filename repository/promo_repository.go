@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInvalidPromoCode dikembalikan ketika promo code tidak ada atau tidak bisa dipakai
+var ErrInvalidPromoCode = errors.New("invalid promo code")
+
+type PromoRepository interface {
+	CreatePromoCode(promo *entity.PromoCode) error
+	FindAllPromoCodes() ([]entity.PromoCode, error)
+	IsPromoCodeExists(code string) (bool, error)
+	PreviewPromoCode(code string, eventID, tierID, userID, quantity int) (*entity.PromoPreviewRes, error)
+}
+
+type promoRepository struct {
+	db *gorm.DB
+}
+
+func NewPromoRepository(db *gorm.DB) *promoRepository {
+	return &promoRepository{db: db}
+}
+
+func (r *promoRepository) CreatePromoCode(promo *entity.PromoCode) error {
+	return r.db.Create(promo).Error
+}
+
+func (r *promoRepository) FindAllPromoCodes() ([]entity.PromoCode, error) {
+	var promos []entity.PromoCode
+	err := r.db.Order("id DESC").Find(&promos).Error
+	return promos, err
+}
+
+func (r *promoRepository) IsPromoCodeExists(code string) (bool, error) {
+	var count int64
+	err := r.db.Model(&entity.PromoCode{}).Where("code = ?", code).Count(&count).Error
+	return count > 0, err
+}
+
+// PreviewPromoCode menghitung potongan harga yang akan didapat tanpa memesan tiket
+// dan tanpa menambah jumlah pemakaian promo code.
+func (r *promoRepository) PreviewPromoCode(code string, eventID, tierID, userID, quantity int) (*entity.PromoPreviewRes, error) {
+	promo, err := findValidPromoCode(r.db, code, eventID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var event entity.Event
+	if err := r.db.Where("id = ?", eventID).First(&event).Error; err != nil {
+		return nil, err
+	}
+
+	unitPrice := event.Price
+	if tierID != 0 {
+		var tier entity.TicketTier
+		if err := r.db.Where("id = ? AND event_id = ?", tierID, eventID).First(&tier).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errors.New("ticket tier not found for this event")
+			}
+			return nil, err
+		}
+		unitPrice = tier.Price
+	}
+
+	discount := promoDiscount(promo, unitPrice)
+
+	return &entity.PromoPreviewRes{
+		Code:              promo.Code,
+		EventID:           eventID,
+		TierID:            tierID,
+		Quantity:          quantity,
+		UnitPrice:         unitPrice,
+		DiscountPerTicket: discount,
+		TotalDiscount:     discount * quantity,
+		TotalPrice:        (unitPrice - discount) * quantity,
+		Currency:          defaultCurrency,
+	}, nil
+}
+
+// findValidPromoCode mencari promo code dan memastikan kode tersebut berlaku untuk
+// event dan user yang diberikan. Saat membuat order, panggil lockPromoCode terlebih
+// dahulu agar pengecekan batas pemakaian tidak balapan dengan order lain.
+func findValidPromoCode(tx *gorm.DB, code string, eventID, userID int) (*entity.PromoCode, error) {
+	var promo entity.PromoCode
+	if err := tx.Where("code = ?", code).First(&promo).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: code not found", ErrInvalidPromoCode)
+		}
+		return nil, err
+	}
+
+	if promo.EventID != 0 && promo.EventID != eventID {
+		return nil, fmt.Errorf("%w: code is not valid for this event", ErrInvalidPromoCode)
+	}
+
+	// Cek apakah promo code sedang dalam masa berlaku
+	now := time.Now()
+	if promo.ValidFrom != nil && now.Before(*promo.ValidFrom) {
+		return nil, fmt.Errorf("%w: code is not active yet", ErrInvalidPromoCode)
+	}
+	if promo.ValidUntil != nil && now.After(*promo.ValidUntil) {
+		return nil, fmt.Errorf("%w: code has expired", ErrInvalidPromoCode)
+	}
+
+	if promo.UsageLimit > 0 && promo.UsedCount >= promo.UsageLimit {
+		return nil, fmt.Errorf("%w: code usage limit reached", ErrInvalidPromoCode)
+	}
+
+	if promo.PerUserLimit > 0 {
+		var used int64
+		if err := tx.Model(&entity.PromoRedemption{}).
+			Where("promo_code_id = ? AND user_id = ?", promo.ID, userID).
+			Count(&used).Error; err != nil {
+			return nil, err
+		}
+
+		if int(used) >= promo.PerUserLimit {
+			return nil, fmt.Errorf("%w: code usage limit per user reached", ErrInvalidPromoCode)
+		}
+	}
+
+	return &promo, nil
+}
+
+// lockPromoCode mengunci baris promo code agar pengecekan batas pemakaian
+// tidak balapan dengan order lain yang memakai kode yang sama.
+func lockPromoCode(tx *gorm.DB, code string) error {
+	var promo entity.PromoCode
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("code = ?", code).First(&promo).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("%w: code not found", ErrInvalidPromoCode)
+	}
+	return err
+}
+
+// promoDiscount menghitung potongan per tiket. Potongan tidak pernah melebihi harga tiket.
+func promoDiscount(promo *entity.PromoCode, unitPrice int) int {
+	discount := promo.DiscountValue
+	if promo.DiscountType == "percentage" {
+		discount = unitPrice * promo.DiscountValue / 100
+	}
+
+	if discount > unitPrice {
+		discount = unitPrice
+	}
+
+	return discount
+}
+
+// redeemPromoCode mencatat pemakaian promo code untuk order yang baru dibuat
+func redeemPromoCode(tx *gorm.DB, promo *entity.PromoCode, order *entity.Order) error {
+	redemption := &entity.PromoRedemption{
+		PromoCodeID: promo.ID,
+		OrderID:     order.ID,
+		UserID:      order.UserID,
+		EventID:     order.EventID,
+		Discount:    order.Discount,
+	}
+	if err := tx.Create(redemption).Error; err != nil {
+		return err
+	}
+
+	return tx.Model(&entity.PromoCode{}).Where("id = ?", promo.ID).
+		Update("used_count", gorm.Expr("used_count + 1")).Error
+}
+
+// releasePromoRedemption membatalkan pemakaian promo code dari order yang gagal
+// dibayar sehingga kuotanya bisa dipakai lagi.
+func releasePromoRedemption(tx *gorm.DB, order *entity.Order) error {
+	if order.PromoCodeID == 0 {
+		return nil
+	}
+
+	result := tx.Where("order_id = ?", order.ID).Delete(&entity.PromoRedemption{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return nil
+	}
+
+	return tx.Model(&entity.PromoCode{}).Where("id = ? AND used_count > 0", order.PromoCodeID).
+		Update("used_count", gorm.Expr("used_count - 1")).Error
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+)
+
+func TestConcurrentOrdersRespectPromoUsageLimit(t *testing.T) {
+	db := openTestDB(t)
+	event := createTestEvent(t, db, 20)
+
+	const usageLimit = 3
+	const buyers = 10
+
+	promo := &entity.PromoCode{
+		Code:          fmt.Sprintf("TEST%d", time.Now().UnixNano()),
+		DiscountType:  "fixed",
+		DiscountValue: 10000,
+		EventID:       event.ID,
+		UsageLimit:    usageLimit,
+	}
+	if err := NewPromoRepository(db).CreatePromoCode(promo); err != nil {
+		t.Fatalf("failed to create promo code: %v", err)
+	}
+
+	users := make([]*entity.User, buyers)
+	for i := range users {
+		users[i] = createTestUser(t, db)
+	}
+
+	orderRepo := NewOrderRepository(db)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var orders []*entity.Order
+	start := make(chan struct{})
+	for _, user := range users {
+		wg.Add(1)
+		go func(userID int) {
+			defer wg.Done()
+			<-start
+
+			order := newTestOrder(event, userID, 1)
+			if err := orderRepo.CreateOrder(order, promo.Code); err == nil {
+				mu.Lock()
+				orders = append(orders, order)
+				mu.Unlock()
+			}
+		}(user.ID)
+	}
+	close(start)
+	wg.Wait()
+
+	if len(orders) != usageLimit {
+		t.Fatalf("%d orders used the promo code, want %d", len(orders), usageLimit)
+	}
+
+	var stored entity.PromoCode
+	if err := db.First(&stored, promo.ID).Error; err != nil {
+		t.Fatalf("failed to reload promo code: %v", err)
+	}
+	if stored.UsedCount != usageLimit {
+		t.Fatalf("used count = %d, want %d", stored.UsedCount, usageLimit)
+	}
+
+	for _, order := range orders {
+		if order.Discount != 10000 {
+			t.Fatalf("order %d discount = %d, want 10000", order.ID, order.Discount)
+		}
+	}
+
+	// Order yang gagal dibayar mengembalikan kuota promo code
+	payment := createTestPayment(t, db, orders[0])
+	if err := NewPaymentRepository(db).MarkFailed(payment.ID); err != nil {
+		t.Fatalf("MarkFailed returned an error: %v", err)
+	}
+
+	if err := db.First(&stored, promo.ID).Error; err != nil {
+		t.Fatalf("failed to reload promo code: %v", err)
+	}
+	if stored.UsedCount != usageLimit-1 {
+		t.Fatalf("used count after failed payment = %d, want %d", stored.UsedCount, usageLimit-1)
+	}
+}
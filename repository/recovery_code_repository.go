@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"gorm.io/gorm"
+)
+
+type RecoveryCodeRepository interface {
+	ReplaceRecoveryCodes(userID int, codes []entity.RecoveryCode) error
+	UseRecoveryCode(userID int, codeHash string, now time.Time) (bool, error)
+	CountUnusedRecoveryCodes(userID int) (int64, error)
+	DeleteRecoveryCodes(userID int) error
+}
+
+type recoveryCodeRepository struct {
+	db *gorm.DB
+}
+
+func NewRecoveryCodeRepository(db *gorm.DB) *recoveryCodeRepository {
+	return &recoveryCodeRepository{db: db}
+}
+
+// ReplaceRecoveryCodes menghapus semua recovery code lama user dan menyimpan yang baru
+func (r *recoveryCodeRepository) ReplaceRecoveryCodes(userID int, codes []entity.RecoveryCode) error {
+	tx := r.db.Begin()
+
+	if err := tx.Where("user_id = ?", userID).Delete(&entity.RecoveryCode{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Create(&codes).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// UseRecoveryCode menandai recovery code terpakai. Update bersyarat used_at IS NULL
+// memastikan kode yang sama tidak bisa dipakai oleh dua request bersamaan.
+func (r *recoveryCodeRepository) UseRecoveryCode(userID int, codeHash string, now time.Time) (bool, error) {
+	result := r.db.Model(&entity.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Limit(1).Update("used_at", now)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *recoveryCodeRepository) CountUnusedRecoveryCodes(userID int) (int64, error) {
+	var count int64
+	err := r.db.Model(&entity.RecoveryCode{}).Where("user_id = ? AND used_at IS NULL", userID).Count(&count).Error
+	return count, err
+}
+
+func (r *recoveryCodeRepository) DeleteRecoveryCodes(userID int) error {
+	return r.db.Where("user_id = ?", userID).Delete(&entity.RecoveryCode{}).Error
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"gorm.io/gorm"
+)
+
+type RefundRepository interface {
+	FindRefundByID(id int) (*entity.Refund, error)
+	FindAllRefunds() ([]entity.Refund, error)
+	UpdateRefundStatus(id int, status string) error
+	FindRefundRules(eventID int) ([]entity.RefundRule, error)
+	ReplaceRefundRules(eventID int, rules []entity.RefundRule) error
+}
+
+type refundRepository struct {
+	db *gorm.DB
+}
+
+func NewRefundRepository(db *gorm.DB) *refundRepository {
+	return &refundRepository{db: db}
+}
+
+func (r *refundRepository) FindRefundByID(id int) (*entity.Refund, error) {
+	var refund entity.Refund
+	err := r.db.Where("id = ?", id).First(&refund).Error
+	return &refund, err
+}
+
+func (r *refundRepository) FindAllRefunds() ([]entity.Refund, error) {
+	var refunds []entity.Refund
+	err := r.db.Order("id DESC").Find(&refunds).Error
+	return refunds, err
+}
+
+func (r *refundRepository) UpdateRefundStatus(id int, status string) error {
+	return r.db.Model(&entity.Refund{}).Where("id = ?", id).
+		Update("status", status).Error
+}
+
+func (r *refundRepository) FindRefundRules(eventID int) ([]entity.RefundRule, error) {
+	var rules []entity.RefundRule
+	err := r.db.Where("event_id = ?", eventID).Order("days_before_event DESC").Find(&rules).Error
+	return rules, err
+}
+
+func (r *refundRepository) ReplaceRefundRules(eventID int, rules []entity.RefundRule) error {
+	// Kebijakan lama diganti seluruhnya dengan kebijakan baru
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("event_id = ?", eventID).Delete(&entity.RefundRule{}).Error; err != nil {
+			return err
+		}
+
+		if len(rules) == 0 {
+			return nil
+		}
+
+		return tx.Create(&rules).Error
+	})
+}
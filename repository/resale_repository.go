@@ -0,0 +1,329 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"github.com/Ayyasy123/dibimbing-take-home-test/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrListingNotActive dikembalikan ketika listing sudah terjual, sedang dibayar atau ditarik
+var ErrListingNotActive = errors.New("resale listing is no longer active")
+
+// ErrPayoutNotPending dikembalikan ketika payout penjual sudah dibayar atau dibatalkan
+var ErrPayoutNotPending = errors.New("resale payout is no longer pending")
+
+type ResaleRepository interface {
+	CreateListing(listing *entity.ResaleListing) error
+	FindListingByID(id int) (*entity.ResaleListing, error)
+	FindActiveListings(eventID int) ([]entity.ResaleListing, error)
+	WithdrawListing(id int) error
+	ReserveListing(id int, order *entity.Order) error
+	WithdrawEventListings(eventID int) error
+	FindPayouts(sellerID int) ([]entity.ResalePayout, error)
+	UpdatePayoutStatus(id int, status string) error
+}
+
+type resaleRepository struct {
+	db *gorm.DB
+}
+
+func NewResaleRepository(db *gorm.DB) *resaleRepository {
+	return &resaleRepository{db: db}
+}
+
+func (r *resaleRepository) CreateListing(listing *entity.ResaleListing) error {
+	tx := r.db.Begin()
+
+	// Kunci tiket agar tidak dijual, ditransfer atau dibatalkan secara bersamaan
+	ticket, err := lockTicket(tx, listing.TicketID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if ticket.UserID != listing.SellerID || ticket.Status != entity.TicketStatusPurchased || ticket.CheckedInAt != nil {
+		tx.Rollback()
+		return errors.New("ticket cannot be listed for resale")
+	}
+
+	if err := checkTicketNotListed(tx, ticket.ID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var event entity.Event
+	if err := tx.Where("id = ?", ticket.EventID).First(&event).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := checkEventOnSale(&event); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var pendingTransfers int64
+	if err := tx.Model(&entity.TicketTransfer{}).
+		Where("ticket_id = ? AND status = ?", ticket.ID, "Pending").
+		Count(&pendingTransfers).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if pendingTransfers > 0 {
+		tx.Rollback()
+		return errors.New("ticket has a pending transfer")
+	}
+
+	if err := tx.Create(listing).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+func (r *resaleRepository) FindListingByID(id int) (*entity.ResaleListing, error) {
+	var listing entity.ResaleListing
+	err := r.db.Where("id = ?", id).First(&listing).Error
+	return &listing, err
+}
+
+func (r *resaleRepository) FindActiveListings(eventID int) ([]entity.ResaleListing, error) {
+	var listings []entity.ResaleListing
+	query := r.db.Where("status = ?", "Active")
+	if eventID != 0 {
+		query = query.Where("event_id = ?", eventID)
+	}
+	err := query.Order("price, id").Find(&listings).Error
+	return listings, err
+}
+
+// WithdrawListing menarik listing yang belum dibeli siapa pun
+func (r *resaleRepository) WithdrawListing(id int) error {
+	result := r.db.Model(&entity.ResaleListing{}).
+		Where("id = ? AND status = ?", id, "Active").
+		Update("status", "Withdrawn")
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrListingNotActive
+	}
+
+	return nil
+}
+
+// ReserveListing membuat order untuk pembeli dan menahan listing selama pembayaran
+// berlangsung. Tiket baru berpindah ke pembeli setelah pembayaran berhasil.
+func (r *resaleRepository) ReserveListing(id int, order *entity.Order) error {
+	tx := r.db.Begin()
+
+	var listing entity.ResaleListing
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", id).First(&listing).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if listing.Status != "Active" {
+		tx.Rollback()
+		return ErrListingNotActive
+	}
+
+	if listing.SellerID == order.UserID {
+		tx.Rollback()
+		return errors.New("cannot buy your own listing")
+	}
+
+	var event entity.Event
+	if err := tx.Where("id = ?", listing.EventID).First(&event).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := checkEventOnSale(&event); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Pastikan tiket masih bisa dipakai sebelum pembeli membayar
+	ticket, err := lockTicket(tx, listing.TicketID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if ticket.UserID != listing.SellerID || ticket.Status != entity.TicketStatusPurchased || ticket.CheckedInAt != nil {
+		tx.Rollback()
+		return ErrListingNotActive
+	}
+
+	order.EventID = listing.EventID
+	order.TierID = ticket.TierID
+	order.Quantity = 1
+	order.TotalPrice = listing.Price
+	order.Currency = listing.Currency
+	order.ResaleListingID = listing.ID
+	if err := tx.Create(order).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Model(&entity.ResaleListing{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"status": "Reserved", "buyer_id": order.UserID, "order_id": order.ID}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// WithdrawEventListings menarik semua listing sebuah event, misalnya ketika event sudah dimulai
+func (r *resaleRepository) WithdrawEventListings(eventID int) error {
+	tx := r.db.Begin()
+
+	if err := withdrawEventListings(tx, eventID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// FindPayouts mengambil payout penjual, atau semua payout jika sellerID 0
+func (r *resaleRepository) FindPayouts(sellerID int) ([]entity.ResalePayout, error) {
+	var payouts []entity.ResalePayout
+	query := r.db.Order("id DESC")
+	if sellerID != 0 {
+		query = query.Where("seller_id = ?", sellerID)
+	}
+	err := query.Find(&payouts).Error
+	return payouts, err
+}
+
+// UpdatePayoutStatus menyelesaikan payout yang masih Pending
+func (r *resaleRepository) UpdatePayoutStatus(id int, status string) error {
+	updates := map[string]interface{}{"status": status}
+	if status == "Paid" {
+		updates["paid_at"] = time.Now()
+	}
+
+	result := r.db.Model(&entity.ResalePayout{}).
+		Where("id = ? AND status = ?", id, "Pending").
+		Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		var count int64
+		if err := r.db.Model(&entity.ResalePayout{}).Where("id = ?", id).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return ErrPayoutNotPending
+	}
+
+	return nil
+}
+
+func lockTicket(tx *gorm.DB, id int) (*entity.Ticket, error) {
+	var ticket entity.Ticket
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", id).First(&ticket).Error
+	return &ticket, err
+}
+
+// checkTicketNotListed memastikan tiket tidak sedang dijual di resale marketplace
+func checkTicketNotListed(tx *gorm.DB, ticketID int) error {
+	var listed int64
+	if err := tx.Model(&entity.ResaleListing{}).
+		Where("ticket_id = ? AND status IN ?", ticketID, []string{"Active", "Reserved"}).
+		Count(&listed).Error; err != nil {
+		return err
+	}
+
+	if listed > 0 {
+		return errors.New("ticket is listed for resale")
+	}
+
+	return nil
+}
+
+// completeResale memindahkan tiket ke pembeli setelah pembayaran order resale berhasil.
+// Kode check-in diterbitkan ulang agar QR milik penjual tidak bisa dipakai lagi.
+func completeResale(tx *gorm.DB, order *entity.Order) error {
+	var listing entity.ResaleListing
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", order.ResaleListingID).First(&listing).Error; err != nil {
+		return err
+	}
+
+	if listing.Status != "Reserved" || listing.OrderID != order.ID {
+		return ErrListingNotActive
+	}
+
+	code, err := utils.GenerateTicketCode(listing.TicketID)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Model(&entity.Ticket{}).Where("id = ?", listing.TicketID).
+		Updates(map[string]interface{}{"user_id": order.UserID, "code": code}).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Model(&entity.ResaleListing{}).Where("id = ?", listing.ID).
+		Updates(map[string]interface{}{"status": "Sold", "sold_at": time.Now()}).Error; err != nil {
+		return err
+	}
+
+	// Catat dana penjual agar bisa dibayarkan oleh admin
+	return tx.Create(&entity.ResalePayout{
+		ListingID: listing.ID,
+		SellerID:  listing.SellerID,
+		OrderID:   order.ID,
+		Amount:    listing.SellerPayout,
+		Currency:  listing.Currency,
+		Status:    "Pending",
+	}).Error
+}
+
+// releaseResaleListing membuka kembali listing yang pembayarannya gagal
+func releaseResaleListing(tx *gorm.DB, order *entity.Order) error {
+	return tx.Model(&entity.ResaleListing{}).
+		Where("id = ? AND status = ? AND order_id = ?", order.ResaleListingID, "Reserved", order.ID).
+		Updates(map[string]interface{}{"status": "Active", "buyer_id": 0, "order_id": 0}).Error
+}
+
+// withdrawEventListings menarik semua listing event yang dibatalkan atau sudah dimulai.
+// Order listing yang sedang dibayar digagalkan, tetapi pembayarannya tidak diubah di sini:
+// pembayaran yang masih masuk ditolak oleh MarkPaid karena order sudah gagal, lalu
+// dananya dikembalikan lewat payment service. Pembayaran yang tidak pernah selesai
+// kedaluwarsa seperti biasa.
+func withdrawEventListings(tx *gorm.DB, eventID int) error {
+	var reserved []entity.ResaleListing
+	if err := tx.Where("event_id = ? AND status = ?", eventID, "Reserved").Find(&reserved).Error; err != nil {
+		return err
+	}
+
+	for _, listing := range reserved {
+		if err := tx.Model(&entity.Order{}).
+			Where("id = ? AND status = ?", listing.OrderID, entity.TicketStatusPendingPayment).
+			Update("status", entity.TicketStatusFailed).Error; err != nil {
+			return err
+		}
+	}
+
+	return tx.Model(&entity.ResaleListing{}).
+		Where("event_id = ? AND status IN ?", eventID, []string{"Active", "Reserved"}).
+		Update("status", "Withdrawn").Error
+}
@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"gorm.io/gorm"
+)
+
+func createTestListing(t *testing.T, db *gorm.DB, ticket *entity.Ticket) *entity.ResaleListing {
+	t.Helper()
+
+	listing := &entity.ResaleListing{
+		TicketID:     ticket.ID,
+		EventID:      ticket.EventID,
+		SellerID:     ticket.UserID,
+		Price:        120000,
+		FeeAmount:    12000,
+		SellerPayout: 108000,
+		Currency:     "IDR",
+	}
+	if err := NewResaleRepository(db).CreateListing(listing); err != nil {
+		t.Fatalf("failed to create listing: %v", err)
+	}
+
+	return listing
+}
+
+func reserveTestListing(t *testing.T, db *gorm.DB, listing *entity.ResaleListing, buyerID int) *entity.Order {
+	t.Helper()
+
+	order := &entity.Order{UserID: buyerID, Status: entity.TicketStatusPendingPayment}
+	if err := NewResaleRepository(db).ReserveListing(listing.ID, order); err != nil {
+		t.Fatalf("failed to reserve listing: %v", err)
+	}
+
+	return order
+}
+
+func TestConcurrentReserveListingReservesOnce(t *testing.T) {
+	db := openTestDB(t)
+	event := createTestEvent(t, db, 10)
+	ticket := createPaidTicket(t, db, event, createTestUser(t, db).ID)
+	listing := createTestListing(t, db, ticket)
+
+	const buyers = 5
+	users := make([]*entity.User, buyers)
+	for i := range users {
+		users[i] = createTestUser(t, db)
+	}
+
+	resaleRepo := NewResaleRepository(db)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	reserved := 0
+	start := make(chan struct{})
+	for _, user := range users {
+		wg.Add(1)
+		go func(userID int) {
+			defer wg.Done()
+			<-start
+
+			order := &entity.Order{UserID: userID, Status: entity.TicketStatusPendingPayment}
+			if err := resaleRepo.ReserveListing(listing.ID, order); err == nil {
+				mu.Lock()
+				reserved++
+				mu.Unlock()
+			}
+		}(user.ID)
+	}
+	close(start)
+	wg.Wait()
+
+	if reserved != 1 {
+		t.Fatalf("listing was reserved %d times, want 1", reserved)
+	}
+
+	// Tiket yang sedang dijual tidak bisa ditransfer
+	err := NewTransferRepository(db).CreateTransfer(&entity.TicketTransfer{
+		TicketID:   ticket.ID,
+		FromUserID: ticket.UserID,
+		ToEmail:    "someone@example.com",
+	})
+	if err == nil {
+		t.Fatal("expected a transfer of a listed ticket to fail")
+	}
+}
+
+func TestResalePaymentMovesTicketAndRecordsPayout(t *testing.T) {
+	db := openTestDB(t)
+	event := createTestEvent(t, db, 10)
+	seller := createTestUser(t, db)
+	buyer := createTestUser(t, db)
+	ticket := createPaidTicket(t, db, event, seller.ID)
+	listing := createTestListing(t, db, ticket)
+
+	order := reserveTestListing(t, db, listing, buyer.ID)
+	payment := createTestPayment(t, db, order)
+
+	if err := NewPaymentRepository(db).MarkPaid(payment.ID); err != nil {
+		t.Fatalf("MarkPaid returned an error: %v", err)
+	}
+
+	var stored entity.Ticket
+	if err := db.First(&stored, ticket.ID).Error; err != nil {
+		t.Fatalf("failed to reload ticket: %v", err)
+	}
+	if stored.UserID != buyer.ID {
+		t.Fatalf("ticket owner = %d, want buyer %d", stored.UserID, buyer.ID)
+	}
+	if stored.Code == ticket.Code {
+		t.Fatal("ticket code was not re-issued for the buyer")
+	}
+
+	storedListing, err := NewResaleRepository(db).FindListingByID(listing.ID)
+	if err != nil {
+		t.Fatalf("failed to reload listing: %v", err)
+	}
+	if storedListing.Status != "Sold" {
+		t.Fatalf("listing status = %q, want Sold", storedListing.Status)
+	}
+
+	payouts, err := NewResaleRepository(db).FindPayouts(seller.ID)
+	if err != nil {
+		t.Fatalf("FindPayouts returned an error: %v", err)
+	}
+	if len(payouts) != 1 || payouts[0].Amount != listing.SellerPayout || payouts[0].Status != "Pending" {
+		t.Fatalf("payouts = %+v, want one pending payout of %d", payouts, listing.SellerPayout)
+	}
+
+	resaleRepo := NewResaleRepository(db)
+	if err := resaleRepo.UpdatePayoutStatus(payouts[0].ID, "Paid"); err != nil {
+		t.Fatalf("UpdatePayoutStatus returned an error: %v", err)
+	}
+	if err := resaleRepo.UpdatePayoutStatus(payouts[0].ID, "Cancelled"); !errors.Is(err, ErrPayoutNotPending) {
+		t.Fatalf("second UpdatePayoutStatus err = %v, want ErrPayoutNotPending", err)
+	}
+}
+
+func TestWithdrawnListingRejectsLatePayment(t *testing.T) {
+	db := openTestDB(t)
+	event := createTestEvent(t, db, 10)
+	seller := createTestUser(t, db)
+	ticket := createPaidTicket(t, db, event, seller.ID)
+	listing := createTestListing(t, db, ticket)
+
+	order := reserveTestListing(t, db, listing, createTestUser(t, db).ID)
+	payment := createTestPayment(t, db, order)
+
+	// Listing ditarik saat event dimulai, pembayaran pembeli baru masuk sesudahnya
+	if err := NewResaleRepository(db).WithdrawEventListings(event.ID); err != nil {
+		t.Fatalf("WithdrawEventListings returned an error: %v", err)
+	}
+
+	if err := NewPaymentRepository(db).MarkPaid(payment.ID); !errors.Is(err, ErrOrderNotPayable) {
+		t.Fatalf("MarkPaid err = %v, want ErrOrderNotPayable", err)
+	}
+
+	var stored entity.Ticket
+	if err := db.First(&stored, ticket.ID).Error; err != nil {
+		t.Fatalf("failed to reload ticket: %v", err)
+	}
+	if stored.UserID != seller.ID {
+		t.Fatalf("ticket owner = %d, want seller %d", stored.UserID, seller.ID)
+	}
+
+	var payouts int64
+	db.Model(&entity.ResalePayout{}).Where("listing_id = ?", listing.ID).Count(&payouts)
+	if payouts != 0 {
+		t.Fatalf("%d payouts recorded for a withdrawn listing", payouts)
+	}
+
+	// Pembayaran yang kedaluwarsa tidak membuka kembali listing yang sudah ditarik
+	if err := NewPaymentRepository(db).MarkFailed(payment.ID); err != nil {
+		t.Fatalf("MarkFailed returned an error: %v", err)
+	}
+	storedListing, err := NewResaleRepository(db).FindListingByID(listing.ID)
+	if err != nil {
+		t.Fatalf("failed to reload listing: %v", err)
+	}
+	if storedListing.Status != "Withdrawn" {
+		t.Fatalf("listing status = %q, want Withdrawn", storedListing.Status)
+	}
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SeriesRepository interface {
+	CreateSeries(series *entity.EventSeries) error
+	FindSeriesByID(id int) (*entity.EventSeries, error)
+	FindAllSeries() ([]entity.EventSeries, error)
+	FindOccurrenceDates(seriesID int) ([]time.Time, error)
+	CreateOccurrences(events []entity.Event) error
+	UpdateSeries(series *entity.EventSeries, capacityDelta int, from time.Time) (int, error)
+	GetSeriesSales(seriesID int) ([]entity.TicketsSoldPerEvent, error)
+}
+
+type seriesRepository struct {
+	db *gorm.DB
+}
+
+func NewSeriesRepository(db *gorm.DB) *seriesRepository {
+	return &seriesRepository{db: db}
+}
+
+func (r *seriesRepository) CreateSeries(series *entity.EventSeries) error {
+	return r.db.Create(series).Error
+}
+
+func (r *seriesRepository) FindSeriesByID(id int) (*entity.EventSeries, error) {
+	var series entity.EventSeries
+	err := r.db.Preload("Events", func(db *gorm.DB) *gorm.DB {
+		return db.Order("date")
+	}).Where("id = ?", id).First(&series).Error
+	return &series, err
+}
+
+func (r *seriesRepository) FindAllSeries() ([]entity.EventSeries, error) {
+	var series []entity.EventSeries
+	err := r.db.Find(&series).Error
+	return series, err
+}
+
+func (r *seriesRepository) FindOccurrenceDates(seriesID int) ([]time.Time, error) {
+	var dates []time.Time
+	err := r.db.Model(&entity.Event{}).Where("series_id = ?", seriesID).
+		Order("date").Pluck("date", &dates).Error
+	return dates, err
+}
+
+func (r *seriesRepository) CreateOccurrences(events []entity.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return r.db.Create(&events).Error
+}
+
+// UpdateSeries menyimpan perubahan series lalu menerapkannya ke semua kemunculan
+// yang masih Aktif dan belum dimulai pada waktu from. Kemunculan yang sudah lewat
+// tidak diubah agar laporan dan tiket lama tetap sesuai. Perubahan kapasitas
+// diterapkan sebagai selisih sehingga tiket yang sudah terjual tetap dihitung.
+// Jumlah kemunculan yang diubah dikembalikan.
+func (r *seriesRepository) UpdateSeries(series *entity.EventSeries, capacityDelta int, from time.Time) (int, error) {
+	tx := r.db.Begin()
+
+	if err := tx.Model(&entity.EventSeries{}).Where("id = ?", series.ID).
+		Omit(clause.Associations).Updates(series).Error; err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	var events []entity.Event
+	if err := tx.Where("series_id = ? AND status = ? AND date > ?", series.ID, entity.EventStatusActive, from).
+		Find(&events).Error; err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	for _, event := range events {
+		// Kunci event karena stok tiketnya bisa berubah bersamaan dengan pembelian
+		locked, err := lockEvent(tx, event.ID)
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+
+		availableTickets := locked.AvailableTickets + capacityDelta
+		if availableTickets < 0 {
+			tx.Rollback()
+			return 0, fmt.Errorf("capacity cannot be lower than the number of tickets already sold for event %d", event.ID)
+		}
+
+		ticketAvailability := entity.TicketAvailabilityAvailable
+		if availableTickets == 0 {
+			ticketAvailability = entity.TicketAvailabilitySoldOut
+		}
+
+		if err := tx.Model(&entity.Event{}).Where("id = ?", event.ID).
+			Updates(map[string]interface{}{
+				"name":                 series.Name,
+				"description":          series.Description,
+				"location":             series.Location,
+				"category":             series.Category,
+				"price":                series.Price,
+				"max_tickets_per_user": series.MaxTicketsPerUser,
+				"capacity":             locked.Capacity + capacityDelta,
+				"available_tickets":    availableTickets,
+				"ticket_availability":  ticketAvailability,
+			}).Error; err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+
+	return len(events), tx.Commit().Error
+}
+
+// GetSeriesSales menghitung tiket terjual per kemunculan, termasuk kemunculan
+// yang belum memiliki penjualan
+func (r *seriesRepository) GetSeriesSales(seriesID int) ([]entity.TicketsSoldPerEvent, error) {
+	var results []entity.TicketsSoldPerEvent
+	err := r.db.Model(&entity.Event{}).
+		Select("events.id as event_id, events.name as event_name, COUNT(tickets.id) as total_tickets, COALESCE(SUM(tickets.unit_price - tickets.discount), 0) as total_revenue").
+		Joins("LEFT JOIN tickets ON tickets.event_id = events.id AND tickets.status = ?", entity.TicketStatusPurchased).
+		Where("events.series_id = ?", seriesID).
+		Group("events.id, events.name, events.date").
+		Order("events.date").
+		Scan(&results).Error
+	return results, err
+}
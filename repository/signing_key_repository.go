@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SigningKeyRepository interface {
+	FindActiveKey() (*entity.SigningKey, error)
+	FindVerificationKeys(since time.Time) ([]entity.SigningKey, error)
+	RotateKey(key *entity.SigningKey, dueBefore time.Time) (bool, error)
+	DeleteRetiredKeys(before time.Time) (int64, error)
+}
+
+type signingKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewSigningKeyRepository(db *gorm.DB) *signingKeyRepository {
+	return &signingKeyRepository{db: db}
+}
+
+func (r *signingKeyRepository) FindActiveKey() (*entity.SigningKey, error) {
+	var key entity.SigningKey
+	err := r.db.Where("retired_at IS NULL").Order("created_at DESC").First(&key).Error
+	return &key, err
+}
+
+// FindVerificationKeys mengembalikan kunci aktif dan kunci yang dirotasi setelah since,
+// diurutkan dari yang terbaru
+func (r *signingKeyRepository) FindVerificationKeys(since time.Time) ([]entity.SigningKey, error) {
+	var keys []entity.SigningKey
+	err := r.db.Where("retired_at IS NULL OR retired_at > ?", since).
+		Order("created_at DESC").Find(&keys).Error
+	return keys, err
+}
+
+// RotateKey memensiunkan kunci aktif dan menyimpan kunci baru. Rotasi dilewati jika
+// kunci aktif memakai algoritma yang sama dan dibuat setelah dueBefore, sehingga
+// beberapa instance yang memeriksa jadwal bersamaan hanya merotasi sekali.
+func (r *signingKeyRepository) RotateKey(key *entity.SigningKey, dueBefore time.Time) (bool, error) {
+	tx := r.db.Begin()
+
+	var active []entity.SigningKey
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("retired_at IS NULL").Order("created_at DESC").Find(&active).Error; err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	if len(active) > 0 && active[0].Algorithm == key.Algorithm && active[0].CreatedAt.After(dueBefore) {
+		tx.Rollback()
+		return false, nil
+	}
+
+	if len(active) > 0 {
+		if err := tx.Model(&entity.SigningKey{}).Where("retired_at IS NULL").
+			Update("retired_at", key.CreatedAt).Error; err != nil {
+			tx.Rollback()
+			return false, err
+		}
+	}
+
+	if err := tx.Create(key).Error; err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	return true, tx.Commit().Error
+}
+
+// DeleteRetiredKeys menghapus kunci yang masa tenggangnya sudah habis
+func (r *signingKeyRepository) DeleteRetiredKeys(before time.Time) (int64, error) {
+	result := r.db.Where("retired_at IS NOT NULL AND retired_at <= ?", before).Delete(&entity.SigningKey{})
+	return result.RowsAffected, result.Error
+}
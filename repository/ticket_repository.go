@@ -6,23 +6,35 @@ import (
 	"time"
 
 	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"github.com/Ayyasy123/dibimbing-take-home-test/utils"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TicketRepository interface {
-	CreateTicket(ticket *entity.Ticket) error
 	FindTicketByID(id int) (*entity.Ticket, error)
 	FindAllTickets() ([]entity.Ticket, error)
 	UpdateTicket(id int, ticket *entity.Ticket) error
 	DeleteTicket(id int) error
 	FindAllTicketsByUserID(userID int) ([]entity.Ticket, error)
-	GetTotalTickets(startDate, endDate time.Time) (int64, error)
-	GetTotalRevenue(startDate, endDate time.Time) (int, error)
-	GetTicketStatusDistribution(status string, startDate, endDate time.Time) (int, int, error)
-	GetTicketsSoldPerEvent(startDate, endDate time.Time, eventID int) ([]entity.TicketsSoldPerEvent, error)
-	UpdateTicketStatus(id int, status string) error
+	GetTotalTickets(startDate, endDate time.Time, organizerID int) (int64, error)
+	GetTotalRevenue(startDate, endDate time.Time, organizerID int) (int, error)
+	GetTicketStatusDistribution(status entity.TicketStatus, startDate, endDate time.Time, organizerID int) (int, int, error)
+	GetTicketsSoldPerEvent(startDate, endDate time.Time, eventID, organizerID int) ([]entity.TicketsSoldPerEvent, error)
+	UpdateTicketStatus(id int, status entity.TicketStatus) error
+	CancelTicket(id int, refund *entity.Refund) error
+	GetTotalRefunds(startDate, endDate time.Time, organizerID int) (int, int, error)
+	GetPromoRedemptions(startDate, endDate time.Time, organizerID int) ([]entity.PromoRedemptionSummary, error)
+	CheckInTicket(id int, gate string, staffID int, at time.Time) error
 }
 
+// ErrTicketAlreadyCheckedIn dikembalikan ketika tiket sudah pernah dipindai
+var ErrTicketAlreadyCheckedIn = errors.New("ticket has already been checked in")
+
+// ErrTicketNotRefundable dikembalikan ketika tiket sudah ditransfer atau dijual kembali.
+// Pembayaran order tiket milik pembeli pertama, jadi pemegang sekarang tidak bisa di-refund.
+var ErrTicketNotRefundable = errors.New("ticket was transferred or resold and can no longer be refunded")
+
 type ticketRepository struct {
 	db *gorm.DB
 }
@@ -31,52 +43,9 @@ func NewTicketRepository(db *gorm.DB) *ticketRepository {
 	return &ticketRepository{db: db}
 }
 
-func (r *ticketRepository) CreateTicket(ticket *entity.Ticket) error {
-	// Mulai transaksi database
-	tx := r.db.Begin()
-
-	// Cek apakah event masih memiliki tiket yang tersedia
-	var event entity.Event
-	if err := tx.Model(&entity.Event{}).Where("id = ?", ticket.EventID).First(&event).Error; err != nil {
-		tx.Rollback()
-		return err
-	}
-
-	if event.AvailableTickets <= 0 {
-		tx.Rollback()
-		return errors.New("no available tickets for this event")
-	}
-
-	// Kurangi AvailableTickets pada event
-	newAvailableTickets := event.AvailableTickets - 1
-	if err := tx.Model(&entity.Event{}).Where("id = ?", ticket.EventID).
-		Update("available_tickets", newAvailableTickets).Error; err != nil {
-		tx.Rollback()
-		return err
-	}
-
-	// Jika AvailableTickets menjadi 0, update status event menjadi "habis"
-	if newAvailableTickets == 0 {
-		if err := tx.Model(&entity.Event{}).Where("id = ?", ticket.EventID).
-			Update("status", "Habis").Error; err != nil {
-			tx.Rollback()
-			return err
-		}
-	}
-
-	// Buat tiket
-	if err := tx.Create(ticket).Error; err != nil {
-		tx.Rollback()
-		return err
-	}
-
-	// Commit transaksi
-	return tx.Commit().Error
-}
-
 func (r *ticketRepository) FindTicketByID(id int) (*entity.Ticket, error) {
 	var ticket entity.Ticket
-	err := r.db.Where("id = ?", id).First(&ticket).Error
+	err := r.db.Preload("Event").Where("id = ?", id).First(&ticket).Error
 	return &ticket, err
 }
 
@@ -104,9 +73,16 @@ func (r *ticketRepository) FindAllTicketsByUserID(userID int) ([]entity.Ticket,
 	return tickets, err
 }
 
-func (r *ticketRepository) GetTotalTickets(startDate, endDate time.Time) (int64, error) {
+func (r *ticketRepository) GetTotalTickets(startDate, endDate time.Time, organizerID int) (int64, error) {
 	var totalTickets int64
-	query := r.db.Model(&entity.Ticket{})
+	// Tiket yang belum atau gagal dibayar tidak dihitung sebagai tiket terjual
+	query := r.db.Model(&entity.Ticket{}).
+		Where("tickets.status NOT IN ?", []entity.TicketStatus{entity.TicketStatusPendingPayment, entity.TicketStatusFailed})
+
+	// Batasi ke event milik organizer jika organizerID tidak nol
+	if organizerID != 0 {
+		query = query.Where("tickets.event_id IN (?)", organizerEventIDs(r.db, organizerID))
+	}
 
 	// Tambahkan filter tanggal jika startDate atau endDate tidak kosong
 	if !startDate.IsZero() {
@@ -120,10 +96,15 @@ func (r *ticketRepository) GetTotalTickets(startDate, endDate time.Time) (int64,
 	return totalTickets, err
 }
 
-func (r *ticketRepository) GetTotalRevenue(startDate, endDate time.Time) (int, error) {
+func (r *ticketRepository) GetTotalRevenue(startDate, endDate time.Time, organizerID int) (int, error) {
 	var totalRevenue sql.NullInt64 // Gunakan sql.NullInt64 untuk menangani NULL
 	query := r.db.Model(&entity.Ticket{}).
-		Joins("JOIN events ON tickets.event_id = events.id")
+		Where("tickets.status NOT IN ?", []entity.TicketStatus{entity.TicketStatusPendingPayment, entity.TicketStatusFailed})
+
+	// Batasi ke event milik organizer jika organizerID tidak nol
+	if organizerID != 0 {
+		query = query.Where("tickets.event_id IN (?)", organizerEventIDs(r.db, organizerID))
+	}
 
 	// Tambahkan filter tanggal jika startDate atau endDate tidak kosong
 	if !startDate.IsZero() {
@@ -133,7 +114,8 @@ func (r *ticketRepository) GetTotalRevenue(startDate, endDate time.Time) (int, e
 		query = query.Where("tickets.created_at <= ?", endDate)
 	}
 
-	err := query.Select("SUM(events.price)").Scan(&totalRevenue).Error
+	// Jumlahkan harga yang tersimpan pada tiket saat dibeli, bukan harga event saat ini
+	err := query.Select("SUM(tickets.unit_price - tickets.discount)").Scan(&totalRevenue).Error
 	if err != nil {
 		return 0, err
 	}
@@ -146,12 +128,16 @@ func (r *ticketRepository) GetTotalRevenue(startDate, endDate time.Time) (int, e
 	return int(totalRevenue.Int64), nil
 }
 
-func (r *ticketRepository) GetTicketStatusDistribution(status string, startDate, endDate time.Time) (int, int, error) {
+func (r *ticketRepository) GetTicketStatusDistribution(status entity.TicketStatus, startDate, endDate time.Time, organizerID int) (int, int, error) {
 	var result entity.TicketStatusDistributionResult
 	query := r.db.Model(&entity.Ticket{}).
-		Joins("JOIN events ON tickets.event_id = events.id").
 		Where("tickets.status = ?", status)
 
+	// Batasi ke event milik organizer jika organizerID tidak nol
+	if organizerID != 0 {
+		query = query.Where("tickets.event_id IN (?)", organizerEventIDs(r.db, organizerID))
+	}
+
 	// Tambahkan filter tanggal jika startDate atau endDate tidak kosong
 	if !startDate.IsZero() {
 		query = query.Where("tickets.created_at >= ?", startDate)
@@ -160,7 +146,7 @@ func (r *ticketRepository) GetTicketStatusDistribution(status string, startDate,
 		query = query.Where("tickets.created_at <= ?", endDate)
 	}
 
-	err := query.Select("COUNT(tickets.id) as total_tickets, SUM(events.price) as total_revenue").
+	err := query.Select("COUNT(tickets.id) as total_tickets, SUM(tickets.unit_price - tickets.discount) as total_revenue").
 		Scan(&result).Error
 
 	// Konversi sql.NullInt64 ke int
@@ -177,11 +163,11 @@ func (r *ticketRepository) GetTicketStatusDistribution(status string, startDate,
 	return totalTickets, totalRevenue, err
 }
 
-func (r *ticketRepository) GetTicketsSoldPerEvent(startDate, endDate time.Time, eventID int) ([]entity.TicketsSoldPerEvent, error) {
+func (r *ticketRepository) GetTicketsSoldPerEvent(startDate, endDate time.Time, eventID, organizerID int) ([]entity.TicketsSoldPerEvent, error) {
 	var results []entity.TicketsSoldPerEvent
 	query := r.db.Model(&entity.Ticket{}).
 		Joins("JOIN events ON tickets.event_id = events.id").
-		Where("tickets.status = ?", "Dibeli"). // Hanya tiket dengan status "Dibeli"
+		Where("tickets.status = ?", entity.TicketStatusPurchased). // Hanya tiket dengan status "Dibeli"
 		Group("events.id")
 
 	// Tambahkan filter tanggal jika startDate atau endDate tidak kosong
@@ -197,7 +183,12 @@ func (r *ticketRepository) GetTicketsSoldPerEvent(startDate, endDate time.Time,
 		query = query.Where("events.id = ?", eventID)
 	}
 
-	err := query.Select("events.id as event_id, events.name as event_name, COUNT(tickets.id) as total_tickets, SUM(events.price) as total_revenue").
+	// Organizer hanya melihat penjualan event miliknya
+	if organizerID != 0 {
+		query = query.Where("events.organizer_id = ?", organizerID)
+	}
+
+	err := query.Select("events.id as event_id, events.name as event_name, COUNT(tickets.id) as total_tickets, SUM(tickets.unit_price - tickets.discount) as total_revenue").
 		Scan(&results).Error
 	if err != nil {
 		return nil, err
@@ -206,7 +197,173 @@ func (r *ticketRepository) GetTicketsSoldPerEvent(startDate, endDate time.Time,
 	return results, nil
 }
 
-func (r *ticketRepository) UpdateTicketStatus(id int, status string) error {
+func (r *ticketRepository) UpdateTicketStatus(id int, status entity.TicketStatus) error {
 	return r.db.Model(&entity.Ticket{}).Where("id = ?", id).
 		Update("status", status).Error
 }
+
+func (r *ticketRepository) CancelTicket(id int, refund *entity.Refund) error {
+	tx := r.db.Begin()
+
+	// Kunci tiket agar tidak dibatalkan dua kali secara bersamaan
+	var ticket entity.Ticket
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", id).First(&ticket).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if ticket.Status != entity.TicketStatusPurchased {
+		tx.Rollback()
+		return errors.New("ticket cannot be cancelled because it is not in 'Dibeli' status")
+	}
+
+	// Cek ulang di bawah lock karena tiket bisa dipindai setelah dibaca oleh service
+	if ticket.CheckedInAt != nil {
+		tx.Rollback()
+		return ErrTicketAlreadyCheckedIn
+	}
+
+	// Refund dibuat untuk pemilik yang dibaca service, tiket bisa saja ditransfer sejak itu
+	if ticket.UserID != refund.UserID {
+		tx.Rollback()
+		return ErrTicketNotRefundable
+	}
+
+	// Tiket yang sedang dijual kembali harus ditarik dari marketplace terlebih dahulu
+	if err := checkTicketNotListed(tx, id); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Model(&entity.Ticket{}).Where("id = ?", id).
+		Update("status", entity.TicketStatusCancelled).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Kembalikan tiket ke stok event dan tier
+	if _, err := lockEvent(tx, ticket.EventID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := releaseTickets(tx, ticket.EventID, ticket.TierID, 1); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Kursi yang dipakai tiket ini bisa dijual lagi
+	if err := tx.Where("ticket_id = ?", id).Delete(&entity.EventSeat{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Catat pengembalian dana dalam transaksi yang sama dengan pembatalan
+	if err := tx.Create(refund).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+func (r *ticketRepository) GetTotalRefunds(startDate, endDate time.Time, organizerID int) (int, int, error) {
+	var result entity.TicketStatusDistributionResult
+	query := r.db.Model(&entity.Refund{}).Where("status <> ?", "Failed")
+
+	// Batasi ke event milik organizer jika organizerID tidak nol
+	if organizerID != 0 {
+		query = query.Where("event_id IN (?)", organizerEventIDs(r.db, organizerID))
+	}
+
+	// Tambahkan filter tanggal jika startDate atau endDate tidak kosong
+	if !startDate.IsZero() {
+		query = query.Where("created_at >= ?", startDate)
+	}
+	if !endDate.IsZero() {
+		query = query.Where("created_at <= ?", endDate)
+	}
+
+	err := query.Select("COUNT(id) as total_tickets, SUM(amount) as total_revenue").
+		Scan(&result).Error
+
+	totalRefunds := 0
+	if result.TotalTickets.Valid {
+		totalRefunds = int(result.TotalTickets.Int64)
+	}
+
+	totalRefunded := 0
+	if result.TotalRevenue.Valid {
+		totalRefunded = int(result.TotalRevenue.Int64)
+	}
+
+	return totalRefunds, totalRefunded, err
+}
+
+func (r *ticketRepository) GetPromoRedemptions(startDate, endDate time.Time, organizerID int) ([]entity.PromoRedemptionSummary, error) {
+	var summaries []entity.PromoRedemptionSummary
+	query := r.db.Model(&entity.PromoRedemption{}).
+		Select("promo_codes.id as promo_code_id, promo_codes.code, COUNT(promo_redemptions.id) as total_redemptions, SUM(promo_redemptions.discount) as total_discount").
+		Joins("JOIN promo_codes ON promo_codes.id = promo_redemptions.promo_code_id")
+
+	// Batasi ke event milik organizer jika organizerID tidak nol
+	if organizerID != 0 {
+		query = query.Where("promo_redemptions.event_id IN (?)", organizerEventIDs(r.db, organizerID))
+	}
+
+	// Tambahkan filter tanggal jika startDate atau endDate tidak kosong
+	if !startDate.IsZero() {
+		query = query.Where("promo_redemptions.created_at >= ?", startDate)
+	}
+	if !endDate.IsZero() {
+		query = query.Where("promo_redemptions.created_at <= ?", endDate)
+	}
+
+	err := query.Group("promo_codes.id, promo_codes.code").
+		Order("total_redemptions DESC").
+		Scan(&summaries).Error
+
+	return summaries, err
+}
+
+// CheckInTicket mencatat waktu dan gate check-in. UPDATE hanya berhasil jika tiket
+// masih berstatus Dibeli dan belum pernah dipindai, sehingga dua pemindaian yang
+// bersamaan tidak bisa sama-sama lolos.
+func (r *ticketRepository) CheckInTicket(id int, gate string, staffID int, at time.Time) error {
+	result := r.db.Model(&entity.Ticket{}).
+		Where("id = ? AND status = ? AND checked_in_at IS NULL", id, entity.TicketStatusPurchased).
+		Updates(map[string]interface{}{
+			"checked_in_at": at,
+			"check_in_gate": gate,
+			"checked_in_by": staffID,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrTicketAlreadyCheckedIn
+	}
+
+	return nil
+}
+
+// issueTicketCodes membuat kode check-in untuk tiket yang baru dibuat. Kode
+// memuat ID tiket, jadi harus dipanggil setelah tiket disimpan.
+func issueTicketCodes(tx *gorm.DB, tickets []entity.Ticket) error {
+	for i := range tickets {
+		code, err := utils.GenerateTicketCode(tickets[i].ID)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(&entity.Ticket{}).Where("id = ?", tickets[i].ID).
+			Update("code", code).Error; err != nil {
+			return err
+		}
+		tickets[i].Code = code
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"gorm.io/gorm"
+)
+
+// ErrTierCapacityExceeded dikembalikan ketika total kapasitas tier melebihi kapasitas event
+var ErrTierCapacityExceeded = errors.New("total tier capacity exceeds event capacity")
+
+type TierRepository interface {
+	CreateTier(tier *entity.TicketTier) error
+	FindTierByID(id int) (*entity.TicketTier, error)
+	FindTiersByEventID(eventID int) ([]entity.TicketTier, error)
+	UpdateTier(id int, tier *entity.TicketTier) error
+}
+
+type tierRepository struct {
+	db *gorm.DB
+}
+
+func NewTierRepository(db *gorm.DB) *tierRepository {
+	return &tierRepository{db: db}
+}
+
+func (r *tierRepository) CreateTier(tier *entity.TicketTier) error {
+	tx := r.db.Begin()
+
+	// Kunci event agar dua tier yang dibuat bersamaan tidak sama-sama lolos
+	// pengecekan kapasitas
+	event, err := lockEvent(tx, tier.EventID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Total kapasitas semua tier tidak boleh melebihi kapasitas event
+	var totalCapacity int
+	if err := tx.Model(&entity.TicketTier{}).Where("event_id = ?", tier.EventID).
+		Select("COALESCE(SUM(capacity), 0)").Scan(&totalCapacity).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if totalCapacity+tier.Capacity > event.Capacity {
+		tx.Rollback()
+		return ErrTierCapacityExceeded
+	}
+
+	if err := tx.Create(tier).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+func (r *tierRepository) FindTierByID(id int) (*entity.TicketTier, error) {
+	var tier entity.TicketTier
+	err := r.db.Where("id = ?", id).First(&tier).Error
+	return &tier, err
+}
+
+func (r *tierRepository) FindTiersByEventID(eventID int) ([]entity.TicketTier, error) {
+	var tiers []entity.TicketTier
+	err := r.db.Where("event_id = ?", eventID).Order("price").Find(&tiers).Error
+	return tiers, err
+}
+
+func (r *tierRepository) UpdateTier(id int, tier *entity.TicketTier) error {
+	return r.db.Model(&entity.TicketTier{}).Where("id = ?", id).Updates(tier).Error
+}
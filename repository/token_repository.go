@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	// ErrRefreshTokenReused dikembalikan ketika token yang sudah ditukar dipakai lagi,
+	// tanda token kemungkinan dicuri. Seluruh keluarga token sudah dicabut.
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected, session revoked")
+	ErrInvalidAccountToken = errors.New("invalid, used or expired token")
+)
+
+type TokenRepository interface {
+	CreateRefreshToken(token *entity.RefreshToken) error
+	RotateRefreshToken(tokenHash string, next *entity.RefreshToken, now time.Time) (*entity.RefreshToken, error)
+	RevokeRefreshTokenFamily(tokenHash string, userID int) error
+	RevokeUserRefreshTokens(userID int) error
+	RevokeAccessToken(token *entity.RevokedToken) error
+	IsAccessTokenRevoked(jti string) (bool, error)
+	DeleteExpiredTokens(now time.Time) (int64, error)
+	CreateAccountToken(token *entity.AccountToken) error
+	ConsumeAccountToken(tokenHash, purpose string, now time.Time) (*entity.AccountToken, error)
+}
+
+type tokenRepository struct {
+	db *gorm.DB
+}
+
+func NewTokenRepository(db *gorm.DB) *tokenRepository {
+	return &tokenRepository{db: db}
+}
+
+func (r *tokenRepository) CreateRefreshToken(token *entity.RefreshToken) error {
+	return r.db.Create(token).Error
+}
+
+// RotateRefreshToken menandai token lama sudah dipakai dan menyimpan token pengganti
+// pada keluarga yang sama. Token lama dikunci agar dua refresh bersamaan tidak
+// sama-sama berhasil.
+func (r *tokenRepository) RotateRefreshToken(tokenHash string, next *entity.RefreshToken, now time.Time) (*entity.RefreshToken, error) {
+	tx := r.db.Begin()
+
+	var current entity.RefreshToken
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("token_hash = ?", tokenHash).First(&current).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	// Token yang sudah ditukar atau dicabut dipakai lagi, cabut seluruh keluarganya
+	if current.UsedAt != nil || current.RevokedAt != nil {
+		if err := revokeFamily(tx, current.FamilyID, now); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		if err := tx.Commit().Error; err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+
+	if !now.Before(current.ExpiresAt) {
+		tx.Rollback()
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if err := tx.Model(&entity.RefreshToken{}).Where("id = ?", current.ID).
+		Update("used_at", now).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	next.UserID = current.UserID
+	next.FamilyID = current.FamilyID
+	next.MFA = current.MFA
+	if err := tx.Create(next).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return &current, tx.Commit().Error
+}
+
+// RevokeRefreshTokenFamily mencabut sesi milik token tersebut, dipakai saat logout
+func (r *tokenRepository) RevokeRefreshTokenFamily(tokenHash string, userID int) error {
+	var token entity.RefreshToken
+	if err := r.db.Where("token_hash = ? AND user_id = ?", tokenHash, userID).First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidRefreshToken
+		}
+		return err
+	}
+
+	return revokeFamily(r.db, token.FamilyID, time.Now())
+}
+
+func (r *tokenRepository) RevokeUserRefreshTokens(userID int) error {
+	return r.db.Model(&entity.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *tokenRepository) RevokeAccessToken(token *entity.RevokedToken) error {
+	// Logout dua kali dengan token yang sama tidak dianggap error
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(token).Error
+}
+
+func (r *tokenRepository) IsAccessTokenRevoked(jti string) (bool, error) {
+	var count int64
+	err := r.db.Model(&entity.RevokedToken{}).Where("jti = ?", jti).Count(&count).Error
+	return count > 0, err
+}
+
+// DeleteExpiredTokens membersihkan denylist, refresh token dan token akun yang sudah kedaluwarsa
+func (r *tokenRepository) DeleteExpiredTokens(now time.Time) (int64, error) {
+	revoked := r.db.Where("expires_at < ?", now).Delete(&entity.RevokedToken{})
+	if revoked.Error != nil {
+		return 0, revoked.Error
+	}
+
+	refresh := r.db.Where("expires_at < ?", now).Delete(&entity.RefreshToken{})
+	if refresh.Error != nil {
+		return 0, refresh.Error
+	}
+
+	account := r.db.Where("expires_at < ?", now).Delete(&entity.AccountToken{})
+	return revoked.RowsAffected + refresh.RowsAffected + account.RowsAffected, account.Error
+}
+
+// CreateAccountToken menyimpan token baru dan menandai token lama dengan tujuan yang
+// sama sudah terpakai, sehingga hanya link di email terakhir yang berlaku
+func (r *tokenRepository) CreateAccountToken(token *entity.AccountToken) error {
+	tx := r.db.Begin()
+
+	if err := tx.Model(&entity.AccountToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", token.UserID, token.Purpose).
+		Update("used_at", token.CreatedAt).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Create(token).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// ConsumeAccountToken menandai token sudah dipakai. Baris dikunci agar token yang
+// sama tidak bisa dipakai dua kali oleh request yang bersamaan.
+func (r *tokenRepository) ConsumeAccountToken(tokenHash, purpose string, now time.Time) (*entity.AccountToken, error) {
+	tx := r.db.Begin()
+
+	var token entity.AccountToken
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("token_hash = ? AND purpose = ?", tokenHash, purpose).First(&token).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidAccountToken
+		}
+		return nil, err
+	}
+
+	if token.UsedAt != nil || !now.Before(token.ExpiresAt) {
+		tx.Rollback()
+		return nil, ErrInvalidAccountToken
+	}
+
+	if err := tx.Model(&entity.AccountToken{}).Where("id = ?", token.ID).
+		Update("used_at", now).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	token.UsedAt = &now
+	return &token, tx.Commit().Error
+}
+
+func revokeFamily(tx *gorm.DB, familyID string, now time.Time) error {
+	return tx.Model(&entity.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", now).Error
+}
@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"gorm.io/gorm"
+)
+
+func createTestRefreshToken(t *testing.T, db *gorm.DB, userID int, expiresAt time.Time) *entity.RefreshToken {
+	t.Helper()
+
+	token := &entity.RefreshToken{
+		UserID:    userID,
+		FamilyID:  fmt.Sprintf("family-%d", time.Now().UnixNano()),
+		TokenHash: fmt.Sprintf("hash-%d", time.Now().UnixNano()),
+		ExpiresAt: expiresAt,
+	}
+	if err := NewTokenRepository(db).CreateRefreshToken(token); err != nil {
+		t.Fatalf("failed to create refresh token: %v", err)
+	}
+
+	return token
+}
+
+func nextRefreshToken() *entity.RefreshToken {
+	return &entity.RefreshToken{
+		TokenHash: fmt.Sprintf("hash-%d", time.Now().UnixNano()),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+}
+
+func TestRotateRefreshTokenDetectsReuse(t *testing.T) {
+	db := openTestDB(t)
+	user := createTestUser(t, db)
+	current := createTestRefreshToken(t, db, user.ID, time.Now().Add(time.Hour))
+
+	tokenRepo := NewTokenRepository(db)
+
+	next := nextRefreshToken()
+	if _, err := tokenRepo.RotateRefreshToken(current.TokenHash, next, time.Now()); err != nil {
+		t.Fatalf("RotateRefreshToken returned an error: %v", err)
+	}
+	if next.FamilyID != current.FamilyID || next.UserID != user.ID {
+		t.Fatalf("rotated token belongs to family %q user %d, want %q user %d", next.FamilyID, next.UserID, current.FamilyID, user.ID)
+	}
+
+	// Token lama dipakai lagi, misalnya oleh pihak yang mencurinya
+	if _, err := tokenRepo.RotateRefreshToken(current.TokenHash, nextRefreshToken(), time.Now()); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("reuse err = %v, want ErrRefreshTokenReused", err)
+	}
+
+	// Seluruh keluarga token ikut dicabut, termasuk token terbaru milik user asli
+	if _, err := tokenRepo.RotateRefreshToken(next.TokenHash, nextRefreshToken(), time.Now()); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("rotation of a revoked token err = %v, want ErrRefreshTokenReused", err)
+	}
+
+	var active int64
+	db.Model(&entity.RefreshToken{}).Where("family_id = ? AND revoked_at IS NULL", current.FamilyID).Count(&active)
+	if active != 0 {
+		t.Fatalf("%d tokens of the family are still active", active)
+	}
+}
+
+func TestRotateExpiredRefreshTokenFails(t *testing.T) {
+	db := openTestDB(t)
+	user := createTestUser(t, db)
+	expired := createTestRefreshToken(t, db, user.ID, time.Now().Add(-time.Minute))
+
+	tokenRepo := NewTokenRepository(db)
+	if _, err := tokenRepo.RotateRefreshToken(expired.TokenHash, nextRefreshToken(), time.Now()); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("err = %v, want ErrInvalidRefreshToken", err)
+	}
+	if _, err := tokenRepo.RotateRefreshToken("unknown-hash", nextRefreshToken(), time.Now()); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("unknown token err = %v, want ErrInvalidRefreshToken", err)
+	}
+}
+
+func TestConcurrentRefreshTokenRotationSucceedsOnce(t *testing.T) {
+	db := openTestDB(t)
+	user := createTestUser(t, db)
+	current := createTestRefreshToken(t, db, user.ID, time.Now().Add(time.Hour))
+
+	tokenRepo := NewTokenRepository(db)
+
+	// Dua tab browser melakukan refresh dengan token yang sama secara bersamaan
+	const attempts = 5
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	rotated, reused := 0, 0
+	start := make(chan struct{})
+	for i := 0; i < attempts; i++ {
+		next := &entity.RefreshToken{
+			TokenHash: fmt.Sprintf("hash-%d-%d", time.Now().UnixNano(), i),
+			ExpiresAt: time.Now().Add(time.Hour),
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+
+			_, err := tokenRepo.RotateRefreshToken(current.TokenHash, next, time.Now())
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				rotated++
+			case errors.Is(err, ErrRefreshTokenReused):
+				reused++
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	if rotated != 1 {
+		t.Fatalf("token was rotated %d times, want 1", rotated)
+	}
+	if reused != attempts-1 {
+		t.Fatalf("%d rotations were reported as reuse, want %d", reused, attempts-1)
+	}
+}
+
+func TestConsumeAccountTokenIsSingleUse(t *testing.T) {
+	db := openTestDB(t)
+	user := createTestUser(t, db)
+
+	token := &entity.AccountToken{
+		UserID:    user.ID,
+		Purpose:   entity.AccountTokenPasswordReset,
+		TokenHash: fmt.Sprintf("hash-%d", time.Now().UnixNano()),
+		ExpiresAt: time.Now().Add(time.Hour),
+		CreatedAt: time.Now(),
+	}
+	tokenRepo := NewTokenRepository(db)
+	if err := tokenRepo.CreateAccountToken(token); err != nil {
+		t.Fatalf("CreateAccountToken returned an error: %v", err)
+	}
+
+	// Token reset password tidak bisa dipakai sebagai challenge login
+	if _, err := tokenRepo.ConsumeAccountToken(token.TokenHash, entity.AccountTokenLoginChallenge, time.Now()); !errors.Is(err, ErrInvalidAccountToken) {
+		t.Fatalf("wrong purpose err = %v, want ErrInvalidAccountToken", err)
+	}
+
+	const attempts = 5
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	consumed := 0
+	start := make(chan struct{})
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+
+			if _, err := tokenRepo.ConsumeAccountToken(token.TokenHash, entity.AccountTokenPasswordReset, time.Now()); err == nil {
+				mu.Lock()
+				consumed++
+				mu.Unlock()
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	if consumed != 1 {
+		t.Fatalf("token was consumed %d times, want 1", consumed)
+	}
+}
+
+func TestCreateAccountTokenInvalidatesPreviousToken(t *testing.T) {
+	db := openTestDB(t)
+	user := createTestUser(t, db)
+	tokenRepo := NewTokenRepository(db)
+
+	newToken := func() *entity.AccountToken {
+		token := &entity.AccountToken{
+			UserID:    user.ID,
+			Purpose:   entity.AccountTokenEmailVerification,
+			Email:     user.Email,
+			TokenHash: fmt.Sprintf("hash-%d", time.Now().UnixNano()),
+			ExpiresAt: time.Now().Add(time.Hour),
+			CreatedAt: time.Now(),
+		}
+		if err := tokenRepo.CreateAccountToken(token); err != nil {
+			t.Fatalf("CreateAccountToken returned an error: %v", err)
+		}
+		return token
+	}
+
+	first := newToken()
+	second := newToken()
+
+	// Hanya link di email terakhir yang berlaku
+	if _, err := tokenRepo.ConsumeAccountToken(first.TokenHash, entity.AccountTokenEmailVerification, time.Now()); !errors.Is(err, ErrInvalidAccountToken) {
+		t.Fatalf("older token err = %v, want ErrInvalidAccountToken", err)
+	}
+	if _, err := tokenRepo.ConsumeAccountToken(second.TokenHash, entity.AccountTokenEmailVerification, time.Now()); err != nil {
+		t.Fatalf("latest token returned an error: %v", err)
+	}
+}
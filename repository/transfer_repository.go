@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"github.com/Ayyasy123/dibimbing-take-home-test/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrTransferNotPending dikembalikan ketika transfer sudah diterima, ditolak atau dibatalkan
+var ErrTransferNotPending = errors.New("ticket transfer is no longer pending")
+
+type TransferRepository interface {
+	CreateTransfer(transfer *entity.TicketTransfer) error
+	FindTransferByID(id int) (*entity.TicketTransfer, error)
+	FindTransfersByTicketID(ticketID int) ([]entity.TicketTransfer, error)
+	FindPendingTransfersByEmail(email string) ([]entity.TicketTransfer, error)
+	AcceptTransfer(id, toUserID int) error
+	CloseTransfer(id int, status string) error
+}
+
+type transferRepository struct {
+	db *gorm.DB
+}
+
+func NewTransferRepository(db *gorm.DB) *transferRepository {
+	return &transferRepository{db: db}
+}
+
+func (r *transferRepository) CreateTransfer(transfer *entity.TicketTransfer) error {
+	tx := r.db.Begin()
+
+	// Kunci tiket agar tidak ada dua transfer yang dibuat bersamaan
+	if _, err := lockTicket(tx, transfer.TicketID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var pending int64
+	if err := tx.Model(&entity.TicketTransfer{}).
+		Where("ticket_id = ? AND status = ?", transfer.TicketID, "Pending").
+		Count(&pending).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if pending > 0 {
+		tx.Rollback()
+		return errors.New("ticket already has a pending transfer")
+	}
+
+	if err := checkTicketNotListed(tx, transfer.TicketID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Create(transfer).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+func (r *transferRepository) FindTransferByID(id int) (*entity.TicketTransfer, error) {
+	var transfer entity.TicketTransfer
+	err := r.db.Where("id = ?", id).First(&transfer).Error
+	return &transfer, err
+}
+
+func (r *transferRepository) FindTransfersByTicketID(ticketID int) ([]entity.TicketTransfer, error) {
+	var transfers []entity.TicketTransfer
+	err := r.db.Where("ticket_id = ?", ticketID).Order("id").Find(&transfers).Error
+	return transfers, err
+}
+
+func (r *transferRepository) FindPendingTransfersByEmail(email string) ([]entity.TicketTransfer, error) {
+	var transfers []entity.TicketTransfer
+	err := r.db.Where("to_email = ? AND status = ?", email, "Pending").Order("id").Find(&transfers).Error
+	return transfers, err
+}
+
+// AcceptTransfer memindahkan kepemilikan tiket ke penerima dan menerbitkan kode
+// check-in baru sehingga kode milik pemilik lama tidak bisa dipakai lagi.
+func (r *transferRepository) AcceptTransfer(id, toUserID int) error {
+	tx := r.db.Begin()
+
+	transfer, err := lockPendingTransfer(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	ticket, err := lockTicket(tx, transfer.TicketID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Tiket bisa saja sudah dibatalkan atau dipakai setelah transfer dibuat
+	if ticket.UserID != transfer.FromUserID || ticket.Status != entity.TicketStatusPurchased || ticket.CheckedInAt != nil {
+		tx.Rollback()
+		return errors.New("ticket can no longer be transferred")
+	}
+
+	code, err := utils.GenerateTicketCode(ticket.ID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Model(&entity.Ticket{}).Where("id = ?", ticket.ID).
+		Updates(map[string]interface{}{"user_id": toUserID, "code": code}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	now := time.Now()
+	if err := tx.Model(&entity.TicketTransfer{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"status": "Accepted", "to_user_id": toUserID, "responded_at": now}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// CloseTransfer mengakhiri transfer yang masih Pending tanpa memindahkan tiket
+func (r *transferRepository) CloseTransfer(id int, status string) error {
+	result := r.db.Model(&entity.TicketTransfer{}).
+		Where("id = ? AND status = ?", id, "Pending").
+		Updates(map[string]interface{}{"status": status, "responded_at": time.Now()})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrTransferNotPending
+	}
+
+	return nil
+}
+
+func lockPendingTransfer(tx *gorm.DB, id int) (*entity.TicketTransfer, error) {
+	var transfer entity.TicketTransfer
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", id).First(&transfer).Error; err != nil {
+		return nil, err
+	}
+
+	if transfer.Status != "Pending" {
+		return nil, ErrTransferNotPending
+	}
+
+	return &transfer, nil
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+)
+
+func TestTransferIsAcceptedOnce(t *testing.T) {
+	db := openTestDB(t)
+	event := createTestEvent(t, db, 10)
+	owner := createTestUser(t, db)
+	recipient := createTestUser(t, db)
+	ticket := createPaidTicket(t, db, event, owner.ID)
+
+	transferRepo := NewTransferRepository(db)
+	transfer := &entity.TicketTransfer{
+		TicketID:   ticket.ID,
+		FromUserID: owner.ID,
+		ToEmail:    recipient.Email,
+	}
+	if err := transferRepo.CreateTransfer(transfer); err != nil {
+		t.Fatalf("CreateTransfer returned an error: %v", err)
+	}
+
+	// Tiket hanya boleh memiliki satu transfer yang masih Pending
+	second := &entity.TicketTransfer{TicketID: ticket.ID, FromUserID: owner.ID, ToEmail: "other@example.com"}
+	if err := transferRepo.CreateTransfer(second); err == nil {
+		t.Fatal("expected a second pending transfer to be rejected")
+	}
+
+	const attempts = 5
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	accepted := 0
+	start := make(chan struct{})
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+
+			if err := transferRepo.AcceptTransfer(transfer.ID, recipient.ID); err == nil {
+				mu.Lock()
+				accepted++
+				mu.Unlock()
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	if accepted != 1 {
+		t.Fatalf("transfer was accepted %d times, want 1", accepted)
+	}
+
+	var stored entity.Ticket
+	if err := db.First(&stored, ticket.ID).Error; err != nil {
+		t.Fatalf("failed to reload ticket: %v", err)
+	}
+	if stored.UserID != recipient.ID {
+		t.Fatalf("ticket owner = %d, want recipient %d", stored.UserID, recipient.ID)
+	}
+	if stored.Code == ticket.Code {
+		t.Fatal("ticket code was not re-issued for the recipient")
+	}
+
+	if err := transferRepo.CloseTransfer(transfer.ID, "Cancelled"); !errors.Is(err, ErrTransferNotPending) {
+		t.Fatalf("CloseTransfer err = %v, want ErrTransferNotPending", err)
+	}
+}
+
+func TestAcceptTransferOfCancelledTicketFails(t *testing.T) {
+	db := openTestDB(t)
+	event := createTestEvent(t, db, 10)
+	owner := createTestUser(t, db)
+	recipient := createTestUser(t, db)
+	ticket := createPaidTicket(t, db, event, owner.ID)
+
+	transferRepo := NewTransferRepository(db)
+	transfer := &entity.TicketTransfer{TicketID: ticket.ID, FromUserID: owner.ID, ToEmail: recipient.Email}
+	if err := transferRepo.CreateTransfer(transfer); err != nil {
+		t.Fatalf("CreateTransfer returned an error: %v", err)
+	}
+
+	// Tiket dibatalkan pemiliknya sebelum penerima sempat menerima
+	db.Model(&entity.Ticket{}).Where("id = ?", ticket.ID).Update("status", entity.TicketStatusCancelled)
+
+	if err := transferRepo.AcceptTransfer(transfer.ID, recipient.ID); err == nil {
+		t.Fatal("expected the transfer of a cancelled ticket to fail")
+	}
+
+	var stored entity.Ticket
+	if err := db.First(&stored, ticket.ID).Error; err != nil {
+		t.Fatalf("failed to reload ticket: %v", err)
+	}
+	if stored.UserID != owner.ID {
+		t.Fatalf("ticket owner = %d, want %d", stored.UserID, owner.ID)
+	}
+}
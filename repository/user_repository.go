@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"strings"
 	"time"
 
 	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
@@ -15,8 +16,19 @@ type UserRepository interface {
 	UpdateUser(id int, user *entity.User) error
 	DeleteUser(id int) error
 	IsEmailExists(email string) (bool, error)
+	CountUsersByEmail(email string) (int64, error)
 	GetTotalUsers(startDate, endDate time.Time) (int64, error)
 	GetUserRoleDistribution(role string, startDate, endDate time.Time) (int64, error)
+	FindUserByCalendarToken(token string) (*entity.User, error)
+	UpdateCalendarToken(id int, token string) error
+	UpdatePassword(id int, hashedPassword string) error
+	MarkEmailVerified(id int, email string, verifiedAt time.Time) (bool, error)
+	ResetEmailVerification(id int) error
+	IsEmailVerified(id int) (bool, error)
+	SetTOTPSecret(id int, secret string) (bool, error)
+	EnableTOTP(id int, step int64, enabledAt time.Time) (bool, error)
+	UseTOTPStep(id int, step int64) (bool, error)
+	DisableTOTP(id int) error
 }
 
 type userRepository struct {
@@ -67,6 +79,13 @@ func (r *userRepository) IsEmailExists(email string) (bool, error) {
 	return count > 0, nil
 }
 
+// CountUsersByEmail menghitung akun dengan email yang sama tanpa membedakan huruf besar dan kecil
+func (r *userRepository) CountUsersByEmail(email string) (int64, error) {
+	var count int64
+	err := r.db.Model(&entity.User{}).Where("LOWER(email) = ?", strings.ToLower(email)).Count(&count).Error
+	return count, err
+}
+
 func (r *userRepository) GetTotalUsers(startDate, endDate time.Time) (int64, error) {
 	var totalUser int64
 	query := r.db.Model(&entity.User{})
@@ -98,3 +117,63 @@ func (r *userRepository) GetUserRoleDistribution(role string, startDate, endDate
 	err := query.Count(&totalUser).Error
 	return totalUser, err
 }
+
+func (r *userRepository) FindUserByCalendarToken(token string) (*entity.User, error) {
+	var user entity.User
+	err := r.db.Where("calendar_token = ?", token).First(&user).Error
+	return &user, err
+}
+
+func (r *userRepository) UpdateCalendarToken(id int, token string) error {
+	return r.db.Model(&entity.User{}).Where("id = ?", id).
+		Update("calendar_token", token).Error
+}
+
+func (r *userRepository) UpdatePassword(id int, hashedPassword string) error {
+	return r.db.Model(&entity.User{}).Where("id = ?", id).Update("password", hashedPassword).Error
+}
+
+// MarkEmailVerified hanya berhasil jika email user masih sama dengan email yang
+// dikirimi link verifikasi
+func (r *userRepository) MarkEmailVerified(id int, email string, verifiedAt time.Time) (bool, error) {
+	result := r.db.Model(&entity.User{}).Where("id = ? AND email = ?", id, email).
+		Update("email_verified_at", verifiedAt)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *userRepository) ResetEmailVerification(id int) error {
+	return r.db.Model(&entity.User{}).Where("id = ?", id).Update("email_verified_at", nil).Error
+}
+
+func (r *userRepository) IsEmailVerified(id int) (bool, error) {
+	var count int64
+	err := r.db.Model(&entity.User{}).Where("id = ? AND email_verified_at IS NOT NULL", id).Count(&count).Error
+	return count > 0, err
+}
+
+// SetTOTPSecret menyimpan secret baru selama 2FA belum aktif, sehingga setup ulang
+// tidak bisa mengganti secret yang sedang dipakai
+func (r *userRepository) SetTOTPSecret(id int, secret string) (bool, error) {
+	result := r.db.Model(&entity.User{}).Where("id = ? AND totp_enabled_at IS NULL", id).
+		Updates(map[string]interface{}{"totp_secret": secret, "totp_last_step": 0})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *userRepository) EnableTOTP(id int, step int64, enabledAt time.Time) (bool, error) {
+	result := r.db.Model(&entity.User{}).Where("id = ? AND totp_enabled_at IS NULL", id).
+		Updates(map[string]interface{}{"totp_enabled_at": enabledAt, "totp_last_step": step})
+	return result.RowsAffected > 0, result.Error
+}
+
+// UseTOTPStep mencatat time step kode yang baru dipakai. Gagal jika step tersebut
+// atau step yang lebih baru sudah pernah dipakai, jadi satu kode hanya berlaku sekali.
+func (r *userRepository) UseTOTPStep(id int, step int64) (bool, error) {
+	result := r.db.Model(&entity.User{}).Where("id = ? AND totp_last_step < ?", id, step).
+		Update("totp_last_step", step)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *userRepository) DisableTOTP(id int) error {
+	return r.db.Model(&entity.User{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"totp_secret": "", "totp_enabled_at": nil, "totp_last_step": 0}).Error
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+)
+
+func TestUseTOTPStepRejectsReplayedCodes(t *testing.T) {
+	db := openTestDB(t)
+	user := createTestUser(t, db)
+	userRepo := NewUserRepository(db)
+
+	tests := []struct {
+		step int64
+		want bool
+	}{
+		{100, true},
+		{100, false}, // Kode yang sama dipakai lagi
+		{99, false},  // Kode dari periode sebelumnya setelah kode yang lebih baru dipakai
+		{101, true},
+	}
+
+	for _, tt := range tests {
+		used, err := userRepo.UseTOTPStep(user.ID, tt.step)
+		if err != nil {
+			t.Fatalf("UseTOTPStep(%d) returned an error: %v", tt.step, err)
+		}
+		if used != tt.want {
+			t.Fatalf("UseTOTPStep(%d) = %v, want %v", tt.step, used, tt.want)
+		}
+	}
+}
+
+func TestConcurrentRecoveryCodeUseSucceedsOnce(t *testing.T) {
+	db := openTestDB(t)
+	user := createTestUser(t, db)
+	recoveryRepo := NewRecoveryCodeRepository(db)
+
+	codes := []entity.RecoveryCode{
+		{UserID: user.ID, CodeHash: "recovery-hash-1"},
+		{UserID: user.ID, CodeHash: "recovery-hash-2"},
+	}
+	if err := recoveryRepo.ReplaceRecoveryCodes(user.ID, codes); err != nil {
+		t.Fatalf("ReplaceRecoveryCodes returned an error: %v", err)
+	}
+
+	const attempts = 5
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	used := 0
+	start := make(chan struct{})
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+
+			ok, err := recoveryRepo.UseRecoveryCode(user.ID, "recovery-hash-1", time.Now())
+			if err == nil && ok {
+				mu.Lock()
+				used++
+				mu.Unlock()
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	if used != 1 {
+		t.Fatalf("recovery code was used %d times, want 1", used)
+	}
+
+	remaining, err := recoveryRepo.CountUnusedRecoveryCodes(user.ID)
+	if err != nil {
+		t.Fatalf("CountUnusedRecoveryCodes returned an error: %v", err)
+	}
+	if remaining != 1 {
+		t.Fatalf("%d recovery codes remaining, want 1", remaining)
+	}
+}
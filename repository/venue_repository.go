@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"gorm.io/gorm"
+)
+
+type VenueRepository interface {
+	CreateVenue(venue *entity.Venue) error
+	FindVenueByID(id int) (*entity.Venue, error)
+	CountSeats(venueID int) (int, error)
+	FindSeatAvailability(eventID, venueID int) ([]entity.SeatAvailability, error)
+}
+
+type venueRepository struct {
+	db *gorm.DB
+}
+
+func NewVenueRepository(db *gorm.DB) *venueRepository {
+	return &venueRepository{db: db}
+}
+
+func (r *venueRepository) CreateVenue(venue *entity.Venue) error {
+	// Venue, section dan seat dibuat sekaligus dalam satu transaksi
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return tx.Create(venue).Error
+	})
+}
+
+func (r *venueRepository) FindVenueByID(id int) (*entity.Venue, error) {
+	var venue entity.Venue
+	err := r.db.Preload("Sections.Seats").Where("id = ?", id).First(&venue).Error
+	return &venue, err
+}
+
+func (r *venueRepository) CountSeats(venueID int) (int, error) {
+	var count int64
+	err := r.db.Model(&entity.Seat{}).
+		Joins("JOIN sections ON seats.section_id = sections.id").
+		Where("sections.venue_id = ?", venueID).
+		Count(&count).Error
+	return int(count), err
+}
+
+func (r *venueRepository) FindSeatAvailability(eventID, venueID int) ([]entity.SeatAvailability, error) {
+	var seats []entity.SeatAvailability
+	err := r.db.Model(&entity.Seat{}).
+		Joins("JOIN sections ON seats.section_id = sections.id").
+		Joins("LEFT JOIN event_seats ON event_seats.seat_id = seats.id AND event_seats.event_id = ?", eventID).
+		Where("sections.venue_id = ?", venueID).
+		Order("sections.id, seats.row, seats.number").
+		Select("seats.id as seat_id, sections.name as section, seats.row, seats.number, seats.label, COALESCE(event_seats.status, 'Tersedia') as status").
+		Scan(&seats).Error
+	return seats, err
+}
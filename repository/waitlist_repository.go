@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Lama waktu user waitlist untuk mengonfirmasi tiket yang ditawarkan
+const waitlistClaimWindow = 30 * time.Minute
+
+type WaitlistRepository interface {
+	CreateEntry(entry *entity.WaitlistEntry) error
+	FindActiveEntry(eventID, userID int) (*entity.WaitlistEntry, error)
+	GetPosition(entry *entity.WaitlistEntry) (int, error)
+	OfferAvailableTickets(eventID int) (int, error)
+	FindEventIDsWithWaitingEntries() ([]int, error)
+	CloseWaitlist(eventID int) error
+}
+
+type waitlistRepository struct {
+	db *gorm.DB
+}
+
+func NewWaitlistRepository(db *gorm.DB) *waitlistRepository {
+	return &waitlistRepository{db: db}
+}
+
+func (r *waitlistRepository) CreateEntry(entry *entity.WaitlistEntry) error {
+	return r.db.Create(entry).Error
+}
+
+func (r *waitlistRepository) FindActiveEntry(eventID, userID int) (*entity.WaitlistEntry, error) {
+	var entry entity.WaitlistEntry
+	err := r.db.Where("event_id = ? AND user_id = ? AND status IN ?", eventID, userID, []string{"Waiting", "Offered"}).
+		Order("id DESC").First(&entry).Error
+	return &entry, err
+}
+
+func (r *waitlistRepository) GetPosition(entry *entity.WaitlistEntry) (int, error) {
+	var position int64
+	err := r.db.Model(&entity.WaitlistEntry{}).
+		Where("event_id = ? AND status = ? AND id <= ?", entry.EventID, "Waiting", entry.ID).
+		Count(&position).Error
+	return int(position), err
+}
+
+// OfferAvailableTickets memberikan stok yang tersedia kepada user waitlist
+// secara berurutan. Setiap penawaran berupa hold yang harus dikonfirmasi
+// sebelum waitlistClaimWindow berakhir. Entry yang belum bisa dipenuhi dilewati
+// agar tidak menahan antrean di belakangnya, dan entry yang tidak akan pernah
+// bisa dipenuhi diberi status Expired.
+func (r *waitlistRepository) OfferAvailableTickets(eventID int) (int, error) {
+	tx := r.db.Begin()
+
+	event, err := lockEvent(tx, eventID)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	// Jangan tawarkan tiket untuk event yang sudah tidak dijual
+	if checkEventOnSale(event) != nil {
+		tx.Rollback()
+		return 0, nil
+	}
+
+	var entries []entity.WaitlistEntry
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("event_id = ? AND status = ?", eventID, "Waiting").
+		Order("id").Find(&entries).Error; err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	offered := 0
+	available := event.AvailableTickets
+	for _, entry := range entries {
+		if available == 0 {
+			break
+		}
+
+		// Stok belum cukup untuk permintaan ini, entry tetap menunggu
+		if entry.Quantity > available {
+			continue
+		}
+
+		fillable, err := checkWaitlistEntry(tx, event, &entry)
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+		if !fillable {
+			continue
+		}
+
+		tx.SavePoint("offer")
+		unitPrice, err := reserveTickets(tx, event, entry.TierID, entry.Quantity)
+		if errors.Is(err, ErrTierSoldOut) {
+			tx.RollbackTo("offer")
+			continue
+		}
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+
+		expiresAt := time.Now().Add(waitlistClaimWindow)
+		hold := &entity.TicketHold{
+			EventID:   entry.EventID,
+			TierID:    entry.TierID,
+			UserID:    entry.UserID,
+			Quantity:  entry.Quantity,
+			UnitPrice: unitPrice,
+			Currency:  defaultCurrency,
+			Status:    "Reserved",
+			ExpiresAt: expiresAt,
+		}
+		if err := tx.Create(hold).Error; err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+
+		if err := tx.Model(&entity.WaitlistEntry{}).Where("id = ?", entry.ID).
+			Updates(map[string]interface{}{"status": "Offered", "hold_id": hold.ID, "offer_expires_at": expiresAt}).Error; err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+
+		available -= entry.Quantity
+		offered++
+	}
+
+	return offered, tx.Commit().Error
+}
+
+// checkWaitlistEntry memastikan penawaran untuk entry boleh dibuat dengan aturan yang
+// sama seperti pembelian biasa. Entry yang tier-nya tidak valid, masa penjualan tier-nya
+// sudah berakhir atau user-nya sudah mencapai batas pembelian diberi status Expired.
+// Harus dipanggil setelah lockEvent.
+func checkWaitlistEntry(tx *gorm.DB, event *entity.Event, entry *entity.WaitlistEntry) (bool, error) {
+	expire := func() (bool, error) {
+		return false, tx.Model(&entity.WaitlistEntry{}).Where("id = ?", entry.ID).
+			Update("status", "Expired").Error
+	}
+
+	if entry.TierID == 0 {
+		var tierCount int64
+		if err := tx.Model(&entity.TicketTier{}).Where("event_id = ?", event.ID).
+			Count(&tierCount).Error; err != nil {
+			return false, err
+		}
+		if tierCount > 0 {
+			return expire()
+		}
+	} else {
+		var tier entity.TicketTier
+		err := tx.Where("id = ? AND event_id = ?", entry.TierID, event.ID).First(&tier).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return expire()
+		}
+		if err != nil {
+			return false, err
+		}
+
+		now := time.Now()
+		if tier.SaleEnd != nil && now.After(*tier.SaleEnd) {
+			return expire()
+		}
+		// Penjualan tier belum dibuka, entry tetap menunggu
+		if tier.SaleStart != nil && now.Before(*tier.SaleStart) {
+			return false, nil
+		}
+	}
+
+	err := checkPurchaseLimit(tx, event, entry.UserID, entry.Quantity)
+	if errors.Is(err, ErrPurchaseLimitExceeded) {
+		return expire()
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *waitlistRepository) FindEventIDsWithWaitingEntries() ([]int, error) {
+	var eventIDs []int
+	err := r.db.Model(&entity.WaitlistEntry{}).
+		Where("status = ?", "Waiting").
+		Distinct().Pluck("event_id", &eventIDs).Error
+	return eventIDs, err
+}
+
+// CloseWaitlist mengakhiri antrean event yang sudah tidak menjual tiket.
+// Penawaran yang sedang berjalan dibiarkan kedaluwarsa bersama hold-nya.
+func (r *waitlistRepository) CloseWaitlist(eventID int) error {
+	return r.db.Model(&entity.WaitlistEntry{}).
+		Where("event_id = ? AND status = ?", eventID, "Waiting").
+		Update("status", "Expired").Error
+}
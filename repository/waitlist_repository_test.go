@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"gorm.io/gorm"
+)
+
+func createTestWaitlistEntry(t *testing.T, db *gorm.DB, event *entity.Event, userID, tierID, quantity int) *entity.WaitlistEntry {
+	t.Helper()
+
+	entry := &entity.WaitlistEntry{
+		EventID:  event.ID,
+		UserID:   userID,
+		TierID:   tierID,
+		Quantity: quantity,
+		Status:   "Waiting",
+	}
+	if err := NewWaitlistRepository(db).CreateEntry(entry); err != nil {
+		t.Fatalf("failed to create waitlist entry: %v", err)
+	}
+
+	return entry
+}
+
+func waitlistStatus(t *testing.T, db *gorm.DB, id int) string {
+	t.Helper()
+
+	var entry entity.WaitlistEntry
+	if err := db.First(&entry, id).Error; err != nil {
+		t.Fatalf("failed to reload waitlist entry: %v", err)
+	}
+
+	return entry.Status
+}
+
+func TestOfferAvailableTicketsSkipsEntriesThatDoNotFit(t *testing.T) {
+	db := openTestDB(t)
+	event := createTestEvent(t, db, 2)
+	large := createTestWaitlistEntry(t, db, event, createTestUser(t, db).ID, 0, 3)
+	small := createTestWaitlistEntry(t, db, event, createTestUser(t, db).ID, 0, 2)
+
+	offered, err := NewWaitlistRepository(db).OfferAvailableTickets(event.ID)
+	if err != nil {
+		t.Fatalf("OfferAvailableTickets returned an error: %v", err)
+	}
+	if offered != 1 {
+		t.Fatalf("offered %d entries, want 1", offered)
+	}
+
+	// Entry pertama tidak muat dan tetap menunggu tanpa menahan antrean di belakangnya
+	if status := waitlistStatus(t, db, large.ID); status != "Waiting" {
+		t.Fatalf("large entry status = %q, want Waiting", status)
+	}
+	if status := waitlistStatus(t, db, small.ID); status != "Offered" {
+		t.Fatalf("small entry status = %q, want Offered", status)
+	}
+
+	if stored := reloadEvent(t, db, event.ID); stored.AvailableTickets != 0 {
+		t.Fatalf("available tickets = %d, want 0", stored.AvailableTickets)
+	}
+}
+
+func TestOfferAvailableTicketsExpiresUnfillableEntries(t *testing.T) {
+	db := openTestDB(t)
+	event := createTestEvent(t, db, 10)
+	db.Model(event).Update("max_tickets_per_user", 2)
+
+	buyer := createTestUser(t, db)
+	createTestOrder(t, db, event, buyer.ID, 2, "")
+
+	// User sudah mencapai batas pembelian dan tier yang diminta tidak ada di event ini
+	overLimit := createTestWaitlistEntry(t, db, event, buyer.ID, 0, 1)
+	badTier := createTestWaitlistEntry(t, db, event, createTestUser(t, db).ID, 999999, 1)
+	valid := createTestWaitlistEntry(t, db, event, createTestUser(t, db).ID, 0, 1)
+
+	offered, err := NewWaitlistRepository(db).OfferAvailableTickets(event.ID)
+	if err != nil {
+		t.Fatalf("OfferAvailableTickets returned an error: %v", err)
+	}
+	if offered != 1 {
+		t.Fatalf("offered %d entries, want 1", offered)
+	}
+
+	if status := waitlistStatus(t, db, overLimit.ID); status != "Expired" {
+		t.Fatalf("over-limit entry status = %q, want Expired", status)
+	}
+	if status := waitlistStatus(t, db, badTier.ID); status != "Expired" {
+		t.Fatalf("invalid tier entry status = %q, want Expired", status)
+	}
+	if status := waitlistStatus(t, db, valid.ID); status != "Offered" {
+		t.Fatalf("valid entry status = %q, want Offered", status)
+	}
+}
@@ -2,21 +2,43 @@ package routes
 
 import (
 	"github.com/Ayyasy123/dibimbing-take-home-test/controller"
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"github.com/Ayyasy123/dibimbing-take-home-test/mail"
 	"github.com/Ayyasy123/dibimbing-take-home-test/middleware"
+	"github.com/Ayyasy123/dibimbing-take-home-test/payment"
 	"github.com/Ayyasy123/dibimbing-take-home-test/repository"
 	"github.com/Ayyasy123/dibimbing-take-home-test/service"
+	"github.com/Ayyasy123/dibimbing-take-home-test/utils"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
-func SetupUserRoutes(db *gorm.DB, r *gin.Engine) {
+func SetupUserRoutes(db *gorm.DB, r *gin.Engine, mailer mail.Sender, policy entity.AuthPolicy) {
 	userRepo := repository.NewUserRepository(db)
-	userService := service.NewUserService(userRepo)
+	tokenRepo := repository.NewTokenRepository(db)
+	recoveryCodeRepo := repository.NewRecoveryCodeRepository(db)
+	userService := service.NewUserService(userRepo, tokenRepo, recoveryCodeRepo, mailer, policy)
 	userController := controller.NewUserController(userService)
 
 	r.POST("/register", userController.RegisterUser)
 	r.POST("/login", userController.LoginUser)
-	r.POST("/register/admin", userController.RegisterAsAdmin)
+	r.POST("/auth/refresh", userController.RefreshToken)
+	r.POST("/logout", middleware.JWTAuth(), userController.Logout)
+	r.POST("/auth/forgot-password", userController.ForgotPassword)
+	r.POST("/auth/reset-password", userController.ResetPassword)
+	r.GET("/auth/verify-email", userController.VerifyEmail)
+	r.POST("/auth/verify-email/resend", middleware.JWTAuth(), userController.ResendVerificationEmail)
+	r.POST("/auth/2fa/verify", userController.VerifyTwoFactor)
+
+	twoFactorRoutes := r.Group("/auth/2fa")
+	twoFactorRoutes.Use(middleware.JWTAuth())
+	{
+		twoFactorRoutes.GET("", userController.GetTwoFactorStatus)
+		twoFactorRoutes.POST("/setup", userController.SetupTwoFactor)
+		twoFactorRoutes.POST("/confirm", userController.ConfirmTwoFactor)
+		twoFactorRoutes.POST("/disable", userController.DisableTwoFactor)
+		twoFactorRoutes.POST("/recovery-codes", userController.RegenerateRecoveryCodes)
+	}
 
 	userRoutes := r.Group("/users")
 	userRoutes.Use(middleware.JWTAuth())
@@ -29,41 +51,234 @@ func SetupUserRoutes(db *gorm.DB, r *gin.Engine) {
 	}
 }
 
-func SetupEventRoutes(db *gorm.DB, r *gin.Engine) {
+func SetupEventRoutes(db *gorm.DB, r *gin.Engine, gateway payment.PaymentGateway, checkInPolicy entity.CheckInPolicy) {
 	eventRepo := repository.NewEventRepository(db)
-	eventService := service.NewEventService(eventRepo)
+	tierRepo := repository.NewTierRepository(db)
+	venueRepo := repository.NewVenueRepository(db)
+	waitlistRepo := repository.NewWaitlistRepository(db)
+	refundRepo := repository.NewRefundRepository(db)
+	checkInRepo := repository.NewCheckInRepository(db)
+	userRepo := repository.NewUserRepository(db)
+	orderRepo := repository.NewOrderRepository(db)
+	paymentRepo := repository.NewPaymentRepository(db)
+	paymentService := service.NewPaymentService(gateway, paymentRepo, refundRepo, orderRepo)
+	eventService := service.NewEventService(eventRepo, tierRepo, venueRepo, waitlistRepo, userRepo, paymentService)
 	eventController := controller.NewEventController(eventService)
+	waitlistService := service.NewWaitlistService(waitlistRepo, eventRepo, tierRepo)
+	waitlistController := controller.NewWaitlistController(waitlistService)
+	refundService := service.NewRefundService(refundRepo, eventRepo)
+	refundController := controller.NewRefundController(refundService)
+	checkInService := service.NewCheckInService(checkInRepo, eventRepo, checkInPolicy)
+	checkInController := controller.NewCheckInController(checkInService)
 
 	eventRoutes := r.Group("/events")
 	eventRoutes.Use(middleware.JWTAuth())
 	{
-		eventRoutes.POST("", middleware.RoleAuth("admin"), eventController.CreateEvent)
+		eventRoutes.POST("", middleware.RoleAuth("admin", "organizer"), eventController.CreateEvent)
 		eventRoutes.GET("", eventController.FindAllEvents)
 		eventRoutes.GET("/:id", eventController.FindEventByID)
-		eventRoutes.PUT("/:id", middleware.RoleAuth("admin"), eventController.UpdateEvent)
-		eventRoutes.DELETE("/:id", middleware.RoleAuth("admin"), eventController.DeleteEvent)
-		eventRoutes.PATCH("/:id", middleware.RoleAuth("admin"), eventController.CancelEvent)
+		eventRoutes.GET("/:id/ics", eventController.GetEventCalendar)
+		eventRoutes.PUT("/:id", middleware.RoleAuth("admin", "organizer"), eventController.UpdateEvent)
+		eventRoutes.DELETE("/:id", middleware.RoleAuth("admin", "organizer"), eventController.DeleteEvent)
+		eventRoutes.PATCH("/:id", middleware.RoleAuth("admin", "organizer"), eventController.CancelEvent)
 		eventRoutes.GET("/search", eventController.SearchEvents)
-		eventRoutes.GET("/report", middleware.RoleAuth("admin"), eventController.GetEventReport)
+		eventRoutes.GET("/report", middleware.RoleAuth("admin", "organizer"), eventController.GetEventReport)
+		eventRoutes.POST("/:id/tiers", middleware.RoleAuth("admin", "organizer"), eventController.CreateTier)
+		eventRoutes.GET("/:id/tiers", eventController.FindTiersByEventID)
+		eventRoutes.PUT("/:id/tiers/:tier_id", middleware.RoleAuth("admin", "organizer"), eventController.UpdateTier)
+		eventRoutes.GET("/:id/seats", eventController.FindSeatsByEventID)
+		eventRoutes.POST("/:id/waitlist", waitlistController.JoinWaitlist)
+		eventRoutes.GET("/:id/waitlist/me", waitlistController.FindMyWaitlistEntry)
+		eventRoutes.GET("/:id/refund-policy", refundController.FindRefundPolicy)
+		eventRoutes.PUT("/:id/refund-policy", middleware.RoleAuth("admin", "organizer"), refundController.SetRefundPolicy)
+		eventRoutes.GET("/:id/checkin-bundle", middleware.RoleAuth("staff", "admin"), checkInController.GetCheckInBundle)
+		eventRoutes.POST("/:id/checkin-scans", middleware.RoleAuth("staff", "admin"), checkInController.UploadScans)
 	}
 }
 
-func SetupTicketRoutes(db *gorm.DB, r *gin.Engine) {
+func SetupTicketRoutes(db *gorm.DB, r *gin.Engine, gateway payment.PaymentGateway) {
 	ticketRepo := repository.NewTicketRepository(db)
-	ticketService := service.NewTicketService(ticketRepo)
+	orderRepo := repository.NewOrderRepository(db)
+	holdRepo := repository.NewHoldRepository(db)
+	waitlistRepo := repository.NewWaitlistRepository(db)
+	refundRepo := repository.NewRefundRepository(db)
+	eventRepo := repository.NewEventRepository(db)
+	paymentRepo := repository.NewPaymentRepository(db)
+	transferRepo := repository.NewTransferRepository(db)
+	userRepo := repository.NewUserRepository(db)
+	paymentService := service.NewPaymentService(gateway, paymentRepo, refundRepo, orderRepo)
+	ticketService := service.NewTicketService(ticketRepo, orderRepo, holdRepo, waitlistRepo, refundRepo, eventRepo, paymentService)
 	ticketController := controller.NewTicketController(ticketService)
+	refundService := service.NewRefundService(refundRepo, eventRepo)
+	refundController := controller.NewRefundController(refundService)
+	transferService := service.NewTransferService(transferRepo, ticketRepo, userRepo)
+	transferController := controller.NewTransferController(transferService)
 
 	ticketRoutes := r.Group("/tickets")
 	ticketRoutes.Use(middleware.JWTAuth())
 	{
-		ticketRoutes.POST("", ticketController.CreateTicket)
+		ticketRoutes.POST("", middleware.RequireVerifiedEmail(), ticketController.CreateTicket)
 		ticketRoutes.GET("", middleware.RoleAuth("admin"), ticketController.FindAllTickets)
 		ticketRoutes.GET("/:id", ticketController.FindTicketByID)
 		ticketRoutes.PUT("/:id", middleware.RoleAuth("admin"), ticketController.UpdateTicket)
 		ticketRoutes.DELETE("/:id", middleware.RoleAuth("admin"), ticketController.DeleteTicket)
 		ticketRoutes.GET("/user", ticketController.FindAllTicketsByUserID)
 		ticketRoutes.PATCH("/:id", ticketController.CancelTicket)
-		ticketRoutes.GET("/report", middleware.RoleAuth("admin"), ticketController.GetTicketSalesReport)
-		ticketRoutes.GET("/report/event", middleware.RoleAuth("admin"), ticketController.GetTicketsSoldPerEvent)
+		ticketRoutes.GET("/report", middleware.RoleAuth("admin", "organizer"), ticketController.GetTicketSalesReport)
+		ticketRoutes.GET("/report/event", middleware.RoleAuth("admin", "organizer"), ticketController.GetTicketsSoldPerEvent)
+		ticketRoutes.GET("/orders/:id", ticketController.FindOrderByID)
+		ticketRoutes.POST("/hold", middleware.RequireVerifiedEmail(), ticketController.CreateHold)
+		ticketRoutes.POST("/hold/:id/confirm", middleware.RequireVerifiedEmail(), ticketController.ConfirmHold)
+		ticketRoutes.GET("/refunds", middleware.RoleAuth("admin"), refundController.FindAllRefunds)
+		ticketRoutes.PATCH("/refunds/:id", middleware.RoleAuth("admin"), refundController.UpdateRefund)
+		ticketRoutes.GET("/:id/qr", ticketController.GetTicketQR)
+		ticketRoutes.POST("/:id/transfer", transferController.CreateTransfer)
+		ticketRoutes.GET("/:id/transfers", transferController.FindTransferHistory)
+		ticketRoutes.GET("/transfers/incoming", transferController.FindIncomingTransfers)
+		ticketRoutes.POST("/transfers/:id/accept", transferController.AcceptTransfer)
+		ticketRoutes.POST("/transfers/:id/decline", transferController.DeclineTransfer)
+		ticketRoutes.POST("/transfers/:id/cancel", transferController.CancelTransfer)
+	}
+
+	// Dipakai petugas di pintu masuk untuk memindai QR tiket
+	r.POST("/checkin", middleware.JWTAuth(), middleware.RoleAuth("staff", "admin"), ticketController.CheckIn)
+}
+
+func SetupVenueRoutes(db *gorm.DB, r *gin.Engine) {
+	venueRepo := repository.NewVenueRepository(db)
+	venueService := service.NewVenueService(venueRepo)
+	venueController := controller.NewVenueController(venueService)
+
+	venueRoutes := r.Group("/venues")
+	venueRoutes.Use(middleware.JWTAuth())
+	{
+		venueRoutes.POST("", middleware.RoleAuth("admin"), venueController.CreateVenue)
+		venueRoutes.GET("/:id", venueController.FindVenueByID)
+	}
+}
+
+func SetupPaymentRoutes(db *gorm.DB, r *gin.Engine, gateway payment.PaymentGateway) {
+	paymentRepo := repository.NewPaymentRepository(db)
+	refundRepo := repository.NewRefundRepository(db)
+	orderRepo := repository.NewOrderRepository(db)
+	paymentService := service.NewPaymentService(gateway, paymentRepo, refundRepo, orderRepo)
+	paymentController := controller.NewPaymentController(paymentService)
+
+	// Webhook dipanggil oleh penyedia pembayaran, diverifikasi lewat signature bukan JWT
+	r.POST("/payments/webhook", paymentController.HandleWebhook)
+
+	// Simulasi pembayaran hanya tersedia jika mock gateway diaktifkan
+	if _, ok := gateway.(*payment.MockGateway); !ok {
+		return
+	}
+
+	paymentRoutes := r.Group("/payments")
+	paymentRoutes.Use(middleware.JWTAuth())
+	{
+		paymentRoutes.POST("/mock/:charge_id", paymentController.SimulateMockPayment)
+	}
+}
+
+func SetupPromoRoutes(db *gorm.DB, r *gin.Engine) {
+	promoRepo := repository.NewPromoRepository(db)
+	eventRepo := repository.NewEventRepository(db)
+	promoService := service.NewPromoService(promoRepo, eventRepo)
+	promoController := controller.NewPromoController(promoService)
+
+	promoRoutes := r.Group("/promo-codes")
+	promoRoutes.Use(middleware.JWTAuth())
+	{
+		promoRoutes.POST("", middleware.RoleAuth("admin"), promoController.CreatePromoCode)
+		promoRoutes.GET("", middleware.RoleAuth("admin"), promoController.FindAllPromoCodes)
+		promoRoutes.POST("/validate", promoController.ValidatePromoCode)
+	}
+}
+
+func SetupResaleRoutes(db *gorm.DB, r *gin.Engine, gateway payment.PaymentGateway, policy entity.ResalePolicy) {
+	resaleRepo := repository.NewResaleRepository(db)
+	ticketRepo := repository.NewTicketRepository(db)
+	paymentRepo := repository.NewPaymentRepository(db)
+	refundRepo := repository.NewRefundRepository(db)
+	orderRepo := repository.NewOrderRepository(db)
+	paymentService := service.NewPaymentService(gateway, paymentRepo, refundRepo, orderRepo)
+	resaleService := service.NewResaleService(resaleRepo, ticketRepo, paymentService, policy)
+	resaleController := controller.NewResaleController(resaleService)
+
+	resaleRoutes := r.Group("/resale")
+	resaleRoutes.Use(middleware.JWTAuth())
+	{
+		resaleRoutes.POST("/listings", resaleController.CreateListing)
+		resaleRoutes.GET("/listings", resaleController.FindActiveListings)
+		resaleRoutes.POST("/listings/:id/withdraw", resaleController.WithdrawListing)
+		resaleRoutes.POST("/listings/:id/buy", middleware.RequireVerifiedEmail(), resaleController.BuyListing)
+		resaleRoutes.GET("/payouts", resaleController.FindPayouts)
+		resaleRoutes.PUT("/payouts/:id", middleware.RoleAuth("admin"), resaleController.UpdatePayout)
+	}
+}
+
+func SetupSeriesRoutes(db *gorm.DB, r *gin.Engine) {
+	seriesRepo := repository.NewSeriesRepository(db)
+	userRepo := repository.NewUserRepository(db)
+	seriesService := service.NewSeriesService(seriesRepo, userRepo)
+	seriesController := controller.NewSeriesController(seriesService)
+
+	seriesRoutes := r.Group("/event-series")
+	seriesRoutes.Use(middleware.JWTAuth())
+	{
+		seriesRoutes.POST("", middleware.RoleAuth("admin", "organizer"), seriesController.CreateSeries)
+		seriesRoutes.GET("/:id", seriesController.FindSeriesByID)
+		seriesRoutes.PUT("/:id", middleware.RoleAuth("admin", "organizer"), seriesController.UpdateSeries)
+		seriesRoutes.POST("/:id/generate", middleware.RoleAuth("admin", "organizer"), seriesController.GenerateOccurrences)
+		seriesRoutes.GET("/:id/report", middleware.RoleAuth("admin", "organizer"), seriesController.GetSeriesReport)
+	}
+}
+
+func SetupCalendarRoutes(db *gorm.DB, r *gin.Engine) {
+	userRepo := repository.NewUserRepository(db)
+	eventRepo := repository.NewEventRepository(db)
+	calendarService := service.NewCalendarService(userRepo, eventRepo)
+	calendarController := controller.NewCalendarController(calendarService)
+
+	// Feed langganan dibuka oleh aplikasi kalender tanpa JWT, token pada URL menjadi kuncinya.
+	// Gin tidak mendukung parameter dengan akhiran tetap, jadi GET /calendar/:token.ics
+	// didaftarkan sebagai /calendar/:token dan akhiran .ics dicek di controller.
+	r.GET("/calendar/:token", calendarController.GetUserCalendar)
+
+	calendarRoutes := r.Group("/calendar/subscription")
+	calendarRoutes.Use(middleware.JWTAuth())
+	{
+		calendarRoutes.GET("", calendarController.GetSubscription)
+		calendarRoutes.POST("/reset", calendarController.ResetSubscription)
+	}
+}
+
+func SetupKeyRoutes(db *gorm.DB, r *gin.Engine, policy entity.KeyPolicy) {
+	signingKeyRepo := repository.NewSigningKeyRepository(db)
+	keyService := service.NewKeyService(signingKeyRepo, utils.DefaultKeyRing(), policy)
+	keyController := controller.NewKeyController(keyService)
+
+	// Kunci publik untuk layanan lain yang memverifikasi token secara mandiri
+	r.GET("/.well-known/jwks.json", keyController.GetJWKS)
+}
+
+func SetupAdminRoutes(db *gorm.DB, r *gin.Engine, mailer mail.Sender, policy entity.AuthPolicy) {
+	adminRepo := repository.NewAdminRepository(db)
+	userRepo := repository.NewUserRepository(db)
+	adminService := service.NewAdminService(adminRepo, userRepo, mailer, policy)
+	adminController := controller.NewAdminController(adminService)
+
+	// Admin baru hanya bisa dibuat oleh admin, kecuali admin pertama lewat bootstrap token
+	r.POST("/register/admin", middleware.JWTAuth(), middleware.RoleAuth("admin"), adminController.RegisterAdmin)
+	r.POST("/admin/bootstrap", adminController.BootstrapAdmin)
+	r.POST("/auth/invitations/accept", adminController.AcceptInvitation)
+
+	adminRoutes := r.Group("/admin")
+	adminRoutes.Use(middleware.JWTAuth(), middleware.RoleAuth("admin"))
+	{
+		adminRoutes.PUT("/users/:id/role", adminController.UpdateUserRole)
+		adminRoutes.GET("/role-grants", adminController.FindAllRoleGrants)
+		adminRoutes.POST("/invitations", adminController.CreateInvitation)
+		adminRoutes.GET("/invitations", adminController.FindAllInvitations)
+		adminRoutes.DELETE("/invitations/:id", adminController.RevokeInvitation)
 	}
 }
@@ -0,0 +1,101 @@
+package scheduler
+
+import (
+	"log"
+	"time"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"github.com/Ayyasy123/dibimbing-take-home-test/repository"
+	"gorm.io/gorm"
+)
+
+// EventTransitionHook dipanggil setiap kali scheduler memindahkan status event.
+// Hook dijalankan setelah status tersimpan dan harus menangani error-nya sendiri.
+type EventTransitionHook func(event entity.Event, from, to entity.EventStatus)
+
+// StartEventLifecycle menjalankan goroutine yang secara berkala memindahkan event
+// dari Aktif ke Berlangsung saat Date tercapai, lalu ke Selesai saat
+// EndDate tercapai. Setiap perpindahan memanggil semua hook secara berurutan.
+func StartEventLifecycle(db *gorm.DB, interval time.Duration, hooks ...EventTransitionHook) {
+	eventRepo := repository.NewEventRepository(db)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			transitionEvents(eventRepo, time.Now(), hooks)
+		}
+	}()
+}
+
+func transitionEvents(eventRepo repository.EventRepository, now time.Time, hooks []EventTransitionHook) {
+	events, err := eventRepo.FindEventsDueForTransition(now)
+	if err != nil {
+		log.Println("Failed to find events due for transition:", err)
+		return
+	}
+
+	for _, event := range events {
+		// Event yang terlewat lama bisa langsung melewati Berlangsung dan Selesai
+		// dalam satu putaran, hook tetap dipanggil untuk setiap langkah
+		for next, ok := nextEventStatus(event, now); ok; next, ok = nextEventStatus(event, now) {
+			moved, err := eventRepo.TransitionEventStatus(event.ID, event.Status, next)
+			if err != nil {
+				log.Printf("Failed to move event %d to %s: %v", event.ID, next, err)
+				break
+			}
+
+			// Status sudah diubah proses lain, misalnya event dibatalkan admin
+			if !moved {
+				break
+			}
+
+			from := event.Status
+			event.Status = next
+			for _, hook := range hooks {
+				hook(event, from, next)
+			}
+		}
+	}
+}
+
+func nextEventStatus(event entity.Event, now time.Time) (entity.EventStatus, bool) {
+	switch event.Status {
+	case entity.EventStatusActive:
+		if !now.Before(event.Date) {
+			return entity.EventStatusOngoing, true
+		}
+	case entity.EventStatusOngoing:
+		if !now.Before(event.EndDate) {
+			return entity.EventStatusFinished, true
+		}
+	}
+	return "", false
+}
+
+// LogEventTransition mencatat setiap perpindahan status event
+func LogEventTransition(event entity.Event, from, to entity.EventStatus) {
+	log.Printf("Event %d (%s) moved from %s to %s", event.ID, event.Name, from, to)
+}
+
+// CloseEventSales menutup semua jalur penjualan yang tersisa ketika event dimulai:
+// listing resale ditarik dan antrean waitlist diakhiri.
+func CloseEventSales(db *gorm.DB) EventTransitionHook {
+	resaleRepo := repository.NewResaleRepository(db)
+	waitlistRepo := repository.NewWaitlistRepository(db)
+
+	return func(event entity.Event, from, to entity.EventStatus) {
+		if to != entity.EventStatusOngoing {
+			return
+		}
+
+		if err := resaleRepo.WithdrawEventListings(event.ID); err != nil {
+			log.Printf("Failed to withdraw resale listings for event %d: %v", event.ID, err)
+		}
+
+		if err := waitlistRepo.CloseWaitlist(event.ID); err != nil {
+			log.Printf("Failed to close waitlist for event %d: %v", event.ID, err)
+		}
+	}
+}
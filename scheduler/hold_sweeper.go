@@ -0,0 +1,56 @@
+package scheduler
+
+import (
+	"log"
+	"time"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/repository"
+	"gorm.io/gorm"
+)
+
+// StartHoldSweeper menjalankan goroutine yang secara berkala melepas hold
+// yang sudah kedaluwarsa, mengembalikan stoknya ke event, lalu menawarkan
+// stok yang tersedia kepada user di waitlist.
+func StartHoldSweeper(db *gorm.DB, interval time.Duration) {
+	holdRepo := repository.NewHoldRepository(db)
+	waitlistRepo := repository.NewWaitlistRepository(db)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			released, err := holdRepo.ReleaseExpiredHolds(time.Now())
+			if err != nil {
+				log.Println("Failed to release expired holds:", err)
+				continue
+			}
+
+			if released > 0 {
+				log.Printf("Released %d expired ticket holds", released)
+			}
+
+			offerWaitlist(waitlistRepo)
+		}
+	}()
+}
+
+func offerWaitlist(waitlistRepo repository.WaitlistRepository) {
+	eventIDs, err := waitlistRepo.FindEventIDsWithWaitingEntries()
+	if err != nil {
+		log.Println("Failed to find events with waitlist:", err)
+		return
+	}
+
+	for _, eventID := range eventIDs {
+		offered, err := waitlistRepo.OfferAvailableTickets(eventID)
+		if err != nil {
+			log.Printf("Failed to process waitlist for event %d: %v", eventID, err)
+			continue
+		}
+
+		if offered > 0 {
+			log.Printf("Offered tickets to %d waitlisted users for event %d", offered, eventID)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package scheduler
+
+import (
+	"log"
+	"time"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/service"
+)
+
+// StartKeyRotation menjalankan goroutine yang secara berkala merotasi kunci JWT yang
+// sudah jatuh tempo dan memuat ulang key ring, sehingga kunci yang dirotasi oleh
+// instance lain juga ikut dipakai.
+func StartKeyRotation(keyService service.KeyService, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := keyService.RotateKeys(time.Now()); err != nil {
+				log.Println("Failed to rotate JWT signing keys:", err)
+			}
+		}
+	}()
+}
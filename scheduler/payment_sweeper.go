@@ -0,0 +1,39 @@
+package scheduler
+
+import (
+	"log"
+	"time"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/repository"
+	"gorm.io/gorm"
+)
+
+// StartPaymentSweeper menjalankan goroutine yang secara berkala menggagalkan
+// pembayaran yang masih Pending lebih lama dari timeout, sehingga stok tiket
+// yang dipesan kembali tersedia.
+func StartPaymentSweeper(db *gorm.DB, interval, timeout time.Duration) {
+	paymentRepo := repository.NewPaymentRepository(db)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			payments, err := paymentRepo.FindPendingPaymentsBefore(time.Now().Add(-timeout))
+			if err != nil {
+				log.Println("Failed to find pending payments:", err)
+				continue
+			}
+
+			for _, payment := range payments {
+				if err := paymentRepo.MarkFailed(payment.ID); err != nil {
+					log.Printf("Failed to expire payment %d: %v", payment.ID, err)
+				}
+			}
+
+			if len(payments) > 0 {
+				log.Printf("Expired %d pending payments", len(payments))
+			}
+		}
+	}()
+}
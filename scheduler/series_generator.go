@@ -0,0 +1,33 @@
+package scheduler
+
+import (
+	"log"
+	"time"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/repository"
+	"github.com/Ayyasy123/dibimbing-take-home-test/service"
+	"gorm.io/gorm"
+)
+
+// StartSeriesGenerator menjalankan goroutine yang secara berkala membuat kemunculan
+// baru untuk series tanpa akhir, sehingga selalu ada event yang bisa dibeli
+// beberapa bulan ke depan.
+func StartSeriesGenerator(db *gorm.DB, interval time.Duration) {
+	seriesService := service.NewSeriesService(repository.NewSeriesRepository(db), repository.NewUserRepository(db))
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			created, err := seriesService.GenerateAllOccurrences()
+			if err != nil {
+				log.Println("Failed to generate series occurrences:", err)
+			}
+
+			if created > 0 {
+				log.Printf("Generated %d series occurrences", created)
+			}
+		}
+	}()
+}
@@ -0,0 +1,32 @@
+package scheduler
+
+import (
+	"log"
+	"time"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/repository"
+	"gorm.io/gorm"
+)
+
+// StartTokenSweeper menjalankan goroutine yang secara berkala menghapus refresh
+// token dan jti di denylist yang sudah kedaluwarsa agar tabelnya tidak terus membesar.
+func StartTokenSweeper(db *gorm.DB, interval time.Duration) {
+	tokenRepo := repository.NewTokenRepository(db)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			deleted, err := tokenRepo.DeleteExpiredTokens(time.Now())
+			if err != nil {
+				log.Println("Failed to delete expired tokens:", err)
+				continue
+			}
+
+			if deleted > 0 {
+				log.Printf("Deleted %d expired tokens", deleted)
+			}
+		}
+	}()
+}
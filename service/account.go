@@ -0,0 +1,150 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"github.com/Ayyasy123/dibimbing-take-home-test/mail"
+	"github.com/Ayyasy123/dibimbing-take-home-test/repository"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	passwordResetTTL     = time.Hour
+	emailVerificationTTL = 24 * time.Hour
+)
+
+var ErrEmailAlreadyVerified = errors.New("email is already verified")
+
+// ForgotPassword mengirim link reset password. Email yang tidak terdaftar tidak
+// dianggap error agar endpoint ini tidak bisa dipakai untuk menebak email user.
+func (s *userService) ForgotPassword(req *entity.ForgotPasswordReq) error {
+	user, err := s.userRepository.FindUserByEmail(req.Email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	token, err := s.issueAccountToken(user, entity.AccountTokenPasswordReset, passwordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(mail.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nUse the link below to choose a new password. It expires in %d minutes and can only be used once.\n\n%s\n\nIf you did not ask for a password reset you can ignore this email.\n",
+			user.Name, int(passwordResetTTL.Minutes()), withToken(s.authPolicy.PasswordResetURL, token)),
+	})
+}
+
+// ResetPassword mengganti password dengan token dari email lalu mencabut semua refresh
+// token user, sehingga sesi yang mungkin dipegang orang lain ikut berakhir
+func (s *userService) ResetPassword(req *entity.ResetPasswordReq) error {
+	now := time.Now()
+	token, err := s.tokenRepository.ConsumeAccountToken(hashToken(req.Token), entity.AccountTokenPasswordReset, now)
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userRepository.UpdatePassword(token.UserID, string(hashedPassword)); err != nil {
+		return err
+	}
+
+	// Link reset membuktikan user memiliki email tersebut
+	if _, err := s.userRepository.MarkEmailVerified(token.UserID, token.Email, now); err != nil {
+		return err
+	}
+
+	return s.tokenRepository.RevokeUserRefreshTokens(token.UserID)
+}
+
+func (s *userService) VerifyEmail(rawToken string) error {
+	now := time.Now()
+	token, err := s.tokenRepository.ConsumeAccountToken(hashToken(rawToken), entity.AccountTokenEmailVerification, now)
+	if err != nil {
+		return err
+	}
+
+	verified, err := s.userRepository.MarkEmailVerified(token.UserID, token.Email, now)
+	if err != nil {
+		return err
+	}
+
+	// Email sudah diganti setelah link dikirim, link lama tidak boleh memverifikasi email baru
+	if !verified {
+		return repository.ErrInvalidAccountToken
+	}
+
+	return nil
+}
+
+// ResendVerificationEmail mengirim ulang link verifikasi untuk user yang sedang login
+func (s *userService) ResendVerificationEmail(userID int) error {
+	user, err := s.userRepository.FindUserByID(userID)
+	if err != nil {
+		return err
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return ErrEmailAlreadyVerified
+	}
+
+	return s.sendVerificationEmail(user)
+}
+
+func (s *userService) sendVerificationEmail(user *entity.User) error {
+	token, err := s.issueAccountToken(user, entity.AccountTokenEmailVerification, emailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(mail.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm your email address by opening the link below. It expires in %d hours.\n\n%s\n",
+			user.Name, int(emailVerificationTTL.Hours()), withToken(s.authPolicy.BaseURL+"/auth/verify-email", token)),
+	})
+}
+
+// notifyVerification dipakai setelah registrasi atau ganti email. Gagal kirim hanya
+// dicatat karena user bisa meminta link baru lewat endpoint resend.
+func (s *userService) notifyVerification(user *entity.User) {
+	if err := s.sendVerificationEmail(user); err != nil {
+		log.Printf("Failed to send verification email to user %d: %v", user.ID, err)
+	}
+}
+
+func (s *userService) issueAccountToken(user *entity.User, purpose string, ttl time.Duration) (string, error) {
+	token, err := generateSecureToken()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	err = s.tokenRepository.CreateAccountToken(&entity.AccountToken{
+		UserID:    user.ID,
+		Purpose:   purpose,
+		Email:     user.Email,
+		TokenHash: hashToken(token),
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	})
+	return token, err
+}
+
+func withToken(link, token string) string {
+	return link + "?token=" + url.QueryEscape(token)
+}
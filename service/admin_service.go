@@ -0,0 +1,267 @@
+package service
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"github.com/Ayyasy123/dibimbing-take-home-test/mail"
+	"github.com/Ayyasy123/dibimbing-take-home-test/repository"
+	"github.com/Ayyasy123/dibimbing-take-home-test/utils"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrBootstrapDisabled     = errors.New("admin bootstrap is not configured")
+	ErrInvalidBootstrapToken = errors.New("invalid bootstrap token")
+	ErrCannotChangeOwnRole   = errors.New("admins cannot change their own role")
+	ErrUserAlreadyHasRole    = errors.New("user already has this role")
+	ErrInvitationEmailInUse  = errors.New("email is already registered, send the password of that account")
+)
+
+type AdminService interface {
+	RegisterAdmin(req *entity.RegisterReq, adminID int) (*entity.UserRes, error)
+	BootstrapAdmin(req *entity.RegisterReq, token string) (*entity.UserRes, error)
+	UpdateUserRole(id int, req *entity.UpdateRoleReq, adminID int) error
+	FindAllRoleGrants(userID int) ([]entity.RoleGrant, error)
+	CreateInvitation(req *entity.CreateInvitationReq, adminID int) (*entity.Invitation, error)
+	FindAllInvitations() ([]entity.Invitation, error)
+	RevokeInvitation(id int) error
+	AcceptInvitation(req *entity.AcceptInvitationReq) (*entity.UserRes, error)
+}
+
+type adminService struct {
+	adminRepository repository.AdminRepository
+	userRepository  repository.UserRepository
+	mailer          mail.Sender
+	authPolicy      entity.AuthPolicy
+}
+
+func NewAdminService(adminRepository repository.AdminRepository, userRepository repository.UserRepository, mailer mail.Sender, authPolicy entity.AuthPolicy) AdminService {
+	return &adminService{
+		adminRepository: adminRepository,
+		userRepository:  userRepository,
+		mailer:          mailer,
+		authPolicy:      authPolicy,
+	}
+}
+
+// RegisterAdmin membuat akun admin baru oleh admin yang sedang login
+func (s *adminService) RegisterAdmin(req *entity.RegisterReq, adminID int) (*entity.UserRes, error) {
+	user, err := s.newAdmin(req)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.adminRepository.CreateUserWithGrant(user, &entity.RoleGrant{
+		GrantedBy: &adminID,
+		Source:    entity.RoleGrantSourceAdmin,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toAdminUserRes(user), nil
+}
+
+// BootstrapAdmin membuat admin pertama dengan token dari konfigurasi. Token hanya bisa
+// dipakai sekali, admin berikutnya dibuat oleh admin atau lewat undangan.
+func (s *adminService) BootstrapAdmin(req *entity.RegisterReq, token string) (*entity.UserRes, error) {
+	if s.authPolicy.BootstrapToken == "" {
+		return nil, ErrBootstrapDisabled
+	}
+
+	if subtle.ConstantTimeCompare([]byte(token), []byte(s.authPolicy.BootstrapToken)) != 1 {
+		return nil, ErrInvalidBootstrapToken
+	}
+
+	user, err := s.newAdmin(req)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.adminRepository.CreateBootstrapAdmin(user, &entity.RoleGrant{
+		Source: entity.RoleGrantSourceBootstrap,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toAdminUserRes(user), nil
+}
+
+func (s *adminService) UpdateUserRole(id int, req *entity.UpdateRoleReq, adminID int) error {
+	// Mencegah admin terakhir tidak sengaja mencabut akses adminnya sendiri
+	if id == adminID {
+		return ErrCannotChangeOwnRole
+	}
+
+	user, err := s.userRepository.FindUserByID(id)
+	if err != nil {
+		return err
+	}
+
+	if user.Role == req.Role {
+		return ErrUserAlreadyHasRole
+	}
+
+	return s.adminRepository.ChangeRole(user.ID, &entity.RoleGrant{
+		NewRole:   req.Role,
+		GrantedBy: &adminID,
+		Source:    entity.RoleGrantSourceAdmin,
+	})
+}
+
+func (s *adminService) FindAllRoleGrants(userID int) ([]entity.RoleGrant, error) {
+	return s.adminRepository.FindAllRoleGrants(userID)
+}
+
+// CreateInvitation menyimpan undangan lalu mengirim link berisi token yang
+// ditandatangani ke email tujuan
+func (s *adminService) CreateInvitation(req *entity.CreateInvitationReq, adminID int) (*entity.Invitation, error) {
+	invitation := &entity.Invitation{
+		Email:     req.Email,
+		Role:      req.Role,
+		InvitedBy: adminID,
+		ExpiresAt: time.Now().Add(utils.InvitationTTL),
+	}
+
+	if err := s.adminRepository.CreateInvitation(invitation); err != nil {
+		return nil, err
+	}
+
+	token, err := utils.GenerateInvitationToken(invitation.ID, invitation.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.mailer.Send(mail.Message{
+		To:      invitation.Email,
+		Subject: fmt.Sprintf("You have been invited as %s", invitation.Role),
+		Body: fmt.Sprintf("Hello,\n\nYou have been invited to join as %s. Open the link below to accept the invitation. It expires in %d hours and can only be used once.\n\n%s\n\nIf you did not expect this invitation you can ignore this email.\n",
+			invitation.Role, int(utils.InvitationTTL.Hours()), withToken(s.authPolicy.InvitationURL, token)),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return invitation, nil
+}
+
+func (s *adminService) FindAllInvitations() ([]entity.Invitation, error) {
+	return s.adminRepository.FindAllInvitations()
+}
+
+func (s *adminService) RevokeInvitation(id int) error {
+	return s.adminRepository.RevokeInvitation(id, time.Now())
+}
+
+// AcceptInvitation membuat akun baru dengan role dari undangan. Jika email sudah
+// terdaftar, password akun tersebut harus benar lalu role-nya diganti.
+func (s *adminService) AcceptInvitation(req *entity.AcceptInvitationReq) (*entity.UserRes, error) {
+	invitationID, err := utils.ValidateInvitationToken(req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	invitation, err := s.adminRepository.FindInvitationByID(invitationID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrInvalidInvitation
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	user, err := s.userRepository.FindUserByEmail(invitation.Email)
+	existing := err == nil
+	switch {
+	case err == nil:
+		if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)) != nil {
+			return nil, ErrInvitationEmailInUse
+		}
+		if user.Role == invitation.Role {
+			return nil, ErrUserAlreadyHasRole
+		}
+		user.Role = invitation.Role
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, err
+		}
+
+		name := req.Name
+		if name == "" {
+			name = invitation.Email
+		}
+
+		user = &entity.User{
+			Name:     name,
+			Email:    invitation.Email,
+			Password: string(hashedPassword),
+			Role:     invitation.Role,
+		}
+	default:
+		return nil, err
+	}
+
+	// Undangan dikirim ke email tersebut, jadi menerima undangan sekaligus memverifikasinya
+	user.EmailVerifiedAt = &now
+
+	err = s.adminRepository.AcceptInvitation(invitation.ID, user, &entity.RoleGrant{
+		NewRole:      invitation.Role,
+		GrantedBy:    &invitation.InvitedBy,
+		Source:       entity.RoleGrantSourceInvitation,
+		InvitationID: &invitation.ID,
+	}, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing {
+		if _, err := s.userRepository.MarkEmailVerified(user.ID, user.Email, now); err != nil {
+			return nil, err
+		}
+	}
+
+	return toAdminUserRes(user), nil
+}
+
+func (s *adminService) newAdmin(req *entity.RegisterReq) (*entity.User, error) {
+	exists, err := s.userRepository.IsEmailExists(req.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	if exists {
+		return nil, errors.New("email already registered")
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.User{
+		Name:     req.Name,
+		Email:    req.Email,
+		Password: string(hashedPassword),
+		Role:     "admin",
+	}, nil
+}
+
+func toAdminUserRes(user *entity.User) *entity.UserRes {
+	return &entity.UserRes{
+		ID:               user.ID,
+		Name:             user.Name,
+		Email:            user.Email,
+		Role:             user.Role,
+		EmailVerifiedAt:  user.EmailVerifiedAt,
+		CreatedAt:        user.CreatedAt,
+		UpdatedAt:        user.UpdatedAt,
+		TwoFactorEnabled: user.TOTPEnabledAt != nil,
+	}
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"github.com/Ayyasy123/dibimbing-take-home-test/repository"
+	"github.com/Ayyasy123/dibimbing-take-home-test/utils"
+)
+
+// Domain pada UID VEVENT, harus tetap sama agar kalender klien mengenali event yang diperbarui
+const calendarUIDDomain = "dibimbing-take-home-test"
+
+var ErrInvalidCalendarToken = errors.New("invalid calendar token")
+
+type CalendarService interface {
+	GetSubscriptionToken(userID int) (string, error)
+	ResetSubscriptionToken(userID int) (string, error)
+	GetUserCalendar(token string) ([]byte, error)
+}
+
+type calendarService struct {
+	userRepository  repository.UserRepository
+	eventRepository repository.EventRepository
+}
+
+func NewCalendarService(userRepository repository.UserRepository, eventRepository repository.EventRepository) CalendarService {
+	return &calendarService{
+		userRepository:  userRepository,
+		eventRepository: eventRepository,
+	}
+}
+
+// GetSubscriptionToken mengembalikan token langganan user, dibuat saat pertama kali diminta
+func (s *calendarService) GetSubscriptionToken(userID int) (string, error) {
+	user, err := s.userRepository.FindUserByID(userID)
+	if err != nil {
+		return "", err
+	}
+
+	if user.CalendarToken != "" {
+		return user.CalendarToken, nil
+	}
+
+	return s.ResetSubscriptionToken(userID)
+}
+
+// ResetSubscriptionToken mengganti token langganan, URL lama langsung tidak berlaku
+func (s *calendarService) ResetSubscriptionToken(userID int) (string, error) {
+	token, err := generateSecureToken()
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.userRepository.UpdateCalendarToken(userID, token); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// GetUserCalendar dibuat ulang setiap kali diminta, sehingga perubahan dan pembatalan
+// event langsung terlihat pada sinkronisasi kalender berikutnya
+func (s *calendarService) GetUserCalendar(token string) ([]byte, error) {
+	// Token kosong akan cocok dengan semua user yang belum berlangganan
+	if token == "" {
+		return nil, ErrInvalidCalendarToken
+	}
+
+	user, err := s.userRepository.FindUserByCalendarToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := s.eventRepository.FindCalendarEventsByUserID(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	icalEvents := make([]utils.ICalEvent, 0, len(events))
+	for _, event := range events {
+		icalEvents = append(icalEvents, toICalEvent(event))
+	}
+
+	return utils.BuildCalendar("Tiket "+user.Name, icalEvents, time.Now()), nil
+}
+
+func toICalEvent(event entity.Event) utils.ICalEvent {
+	status := utils.ICalStatusConfirmed
+	if event.Status == entity.EventStatusCancelled {
+		status = utils.ICalStatusCancelled
+	}
+
+	return utils.ICalEvent{
+		UID:         fmt.Sprintf("event-%d@%s", event.ID, calendarUIDDomain),
+		Summary:     event.Name,
+		Description: event.Description,
+		Location:    event.Location,
+		Start:       event.Date,
+		End:         event.EndDate,
+		Status:      status,
+		// SEQUENCE harus naik setiap kali event berubah, cukup pakai detik sejak event dibuat
+		Sequence:     int(event.UpdatedAt.Sub(event.CreatedAt) / time.Second),
+		LastModified: event.UpdatedAt,
+	}
+}
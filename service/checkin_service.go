@@ -0,0 +1,189 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"github.com/Ayyasy123/dibimbing-take-home-test/repository"
+	"github.com/Ayyasy123/dibimbing-take-home-test/utils"
+)
+
+// Jam scanner boleh sedikit lebih cepat dari jam server
+const maxScanClockSkew = time.Minute
+
+type CheckInService interface {
+	GetCheckInBundle(eventID int) (*entity.SignedCheckInBundle, error)
+	UploadScans(eventID, staffID int, req *entity.UploadScansReq) (*entity.UploadScansRes, error)
+}
+
+type checkInService struct {
+	checkInRepository repository.CheckInRepository
+	eventRepository   repository.EventRepository
+	policy            entity.CheckInPolicy
+}
+
+func NewCheckInService(checkInRepository repository.CheckInRepository, eventRepository repository.EventRepository, policy entity.CheckInPolicy) CheckInService {
+	return &checkInService{checkInRepository: checkInRepository, eventRepository: eventRepository, policy: policy}
+}
+
+func (s *checkInService) GetCheckInBundle(eventID int) (*entity.SignedCheckInBundle, error) {
+	event, err := s.eventRepository.FindEventByID(eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	tickets, err := s.checkInRepository.FindBundleTickets(eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := entity.CheckInBundle{
+		EventID:     event.ID,
+		EventName:   event.Name,
+		GeneratedAt: time.Now().Format(time.RFC3339),
+		Tickets:     []entity.BundleTicket{},
+	}
+
+	for _, ticket := range tickets {
+		// Versi bundle mengikuti perubahan tiket terakhir, jadi scanner tahu
+		// kapan bundle yang dimilikinya sudah usang
+		if ticket.UpdatedAt.UnixMilli() > bundle.Version {
+			bundle.Version = ticket.UpdatedAt.UnixMilli()
+		}
+
+		bundleTicket := entity.BundleTicket{
+			TicketID:   ticket.ID,
+			Code:       ticket.Code,
+			Status:     string(ticket.Status),
+			HolderName: ticket.User.Name,
+			SeatID:     ticket.SeatID,
+		}
+		if ticket.CheckedInAt != nil {
+			bundleTicket.CheckedInAt = ticket.CheckedInAt.Format(time.RFC3339)
+		}
+
+		bundle.Tickets = append(bundle.Tickets, bundleTicket)
+	}
+
+	payload, err := json.Marshal(bundle)
+	if err != nil {
+		return nil, err
+	}
+
+	key, signature, err := utils.DefaultKeyRing().SignData(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.SignedCheckInBundle{
+		Bundle:    bundle,
+		KeyID:     key.KID,
+		Algorithm: key.Algorithm,
+		Signature: signature,
+	}, nil
+}
+
+func (s *checkInService) UploadScans(eventID, staffID int, req *entity.UploadScansReq) (*entity.UploadScansRes, error) {
+	event, err := s.eventRepository.FindEventByID(eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	bundleGeneratedAt, err := time.Parse(time.RFC3339, req.BundleGeneratedAt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bundle_generated_at %q, use RFC 3339 format", req.BundleGeneratedAt)
+	}
+
+	now := time.Now()
+	if bundleGeneratedAt.After(now.Add(maxScanClockSkew)) {
+		return nil, errors.New("bundle_generated_at cannot be in the future")
+	}
+
+	scans := make([]entity.CheckInScan, 0, len(req.Scans))
+	for _, scanReq := range req.Scans {
+		scannedAt, err := time.Parse(time.RFC3339, scanReq.ScannedAt)
+		if err != nil {
+			return nil, fmt.Errorf("invalid scanned_at %q, use RFC 3339 format", scanReq.ScannedAt)
+		}
+
+		scan := entity.CheckInScan{
+			EventID:       eventID,
+			Code:          scanReq.Code,
+			Gate:          scanReq.Gate,
+			DeviceID:      req.DeviceID,
+			BundleVersion: req.BundleVersion,
+			ScannedAt:     scannedAt,
+			UploadedBy:    staffID,
+		}
+
+		// Kode dengan signature tidak valid langsung ditolak tanpa mencari tiketnya
+		ticketID, err := utils.ParseTicketCode(scanReq.Code)
+		if err != nil {
+			scan.Result = "Invalid"
+			scan.Note = "invalid ticket code signature"
+		}
+		scan.TicketID = ticketID
+
+		// Waktu scan berasal dari perangkat, jadi waktu yang tidak mungkin terjadi ditolak
+		// agar tidak bisa dipakai untuk menggeser check-in yang sudah tercatat
+		if scan.Result == "" {
+			if note := checkScanTime(scannedAt, now, bundleGeneratedAt, event.Date.Add(-s.policy.DoorsOpenBefore)); note != "" {
+				scan.Result = "Invalid"
+				scan.Note = note
+			}
+		}
+
+		scans = append(scans, scan)
+	}
+
+	// Scan paling awal diproses lebih dulu agar menjadi check-in yang sah
+	sort.SliceStable(scans, func(i, j int) bool {
+		return scans[i].ScannedAt.Before(scans[j].ScannedAt)
+	})
+
+	err = s.checkInRepository.RecordScans(eventID, scans)
+	if err != nil {
+		return nil, err
+	}
+
+	uploadRes := &entity.UploadScansRes{Results: []entity.ScanResultRes{}}
+	for _, scan := range scans {
+		switch scan.Result {
+		case "Accepted":
+			uploadRes.Accepted++
+		case "Duplicate":
+			uploadRes.Duplicate++
+		case "Conflict":
+			uploadRes.Conflict++
+		case "Invalid":
+			uploadRes.Invalid++
+		}
+
+		uploadRes.Results = append(uploadRes.Results, entity.ScanResultRes{
+			TicketID:  scan.TicketID,
+			Gate:      scan.Gate,
+			ScannedAt: scan.ScannedAt.Format(time.RFC3339),
+			Result:    scan.Result,
+			Note:      scan.Note,
+		})
+	}
+
+	return uploadRes, nil
+}
+
+// checkScanTime mengembalikan alasan penolakan jika waktu scan tidak masuk akal
+func checkScanTime(scannedAt, now, bundleGeneratedAt, doorsOpen time.Time) string {
+	switch {
+	case scannedAt.After(now.Add(maxScanClockSkew)):
+		return "scanned_at is in the future"
+	case scannedAt.Before(bundleGeneratedAt):
+		return "scanned_at is before the check-in bundle was generated"
+	case scannedAt.Before(doorsOpen):
+		return "scanned_at is before the doors opened"
+	}
+	return ""
+}
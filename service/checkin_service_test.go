@@ -0,0 +1,37 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCheckScanTime(t *testing.T) {
+	now := time.Date(2026, 5, 1, 19, 30, 0, 0, time.UTC)
+	bundleGeneratedAt := now.Add(-3 * time.Hour)
+	doorsOpen := now.Add(-time.Hour)
+
+	tests := []struct {
+		name      string
+		scannedAt time.Time
+		valid     bool
+	}{
+		{"after the doors opened", now.Add(-30 * time.Minute), true},
+		{"exactly when the doors opened", doorsOpen, true},
+		{"within the clock skew", now.Add(30 * time.Second), true},
+		{"in the future", now.Add(2 * time.Minute), false},
+		{"before the bundle was generated", bundleGeneratedAt.Add(-time.Minute), false},
+		{"before the doors opened", doorsOpen.Add(-time.Minute), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			note := checkScanTime(tt.scannedAt, now, bundleGeneratedAt, doorsOpen)
+			if tt.valid && note != "" {
+				t.Fatalf("scan rejected: %s", note)
+			}
+			if !tt.valid && note == "" {
+				t.Fatal("expected the scan to be rejected")
+			}
+		})
+	}
+}
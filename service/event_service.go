@@ -2,36 +2,66 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"log"
 	"time"
 
 	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
 	"github.com/Ayyasy123/dibimbing-take-home-test/repository"
+	"github.com/Ayyasy123/dibimbing-take-home-test/utils"
 )
 
 type EventService interface {
-	CreateEvent(req *entity.CreateEventReq) (*entity.Event, error)
+	CreateEvent(req *entity.CreateEventReq, userID int, role string) (*entity.Event, error)
 	FindEventByID(id int) (*entity.Event, error)
 	FindAllEvents() ([]entity.Event, error)
-	UpdateEvent(id int, req *entity.UpdateEventReq) error
-	DeleteEvent(id int) error
+	UpdateEvent(id int, req *entity.UpdateEventReq, userID int, role string) error
+	DeleteEvent(id int, userID int, role string) error
 	SearchEvents(searchQuery string, minPrice, maxPrice int, category, status string, startDate, endDate time.Time) ([]entity.EventRes, error)
-	GetEventReport(startDate, endDate time.Time) (*entity.EventReport, error)
-	CancelEvent(eventID int) error
+	GetEventReport(startDate, endDate time.Time, userID int, role string) (*entity.EventReport, error)
+	CancelEvent(eventID int, userID int, role string) error
+	CreateTier(eventID int, req *entity.CreateTierReq, userID int, role string) (*entity.TierRes, error)
+	FindTiersByEventID(eventID int) ([]entity.TierRes, error)
+	UpdateTier(eventID, tierID int, req *entity.UpdateTierReq, userID int, role string) error
+	FindSeatsByEventID(eventID int) ([]entity.SeatAvailability, error)
+	GetEventCalendar(id int) ([]byte, error)
 }
 
 type eventService struct {
-	eventRepository repository.EventRepository
+	eventRepository    repository.EventRepository
+	tierRepository     repository.TierRepository
+	venueRepository    repository.VenueRepository
+	waitlistRepository repository.WaitlistRepository
+	userRepository     repository.UserRepository
+	paymentService     PaymentService
 }
 
-func NewEventService(eventRepository repository.EventRepository) EventService {
-	return &eventService{eventRepository: eventRepository}
+func NewEventService(eventRepository repository.EventRepository, tierRepository repository.TierRepository, venueRepository repository.VenueRepository, waitlistRepository repository.WaitlistRepository, userRepository repository.UserRepository, paymentService PaymentService) EventService {
+	return &eventService{
+		eventRepository:    eventRepository,
+		tierRepository:     tierRepository,
+		venueRepository:    venueRepository,
+		waitlistRepository: waitlistRepository,
+		userRepository:     userRepository,
+		paymentService:     paymentService,
+	}
 }
 
-func (s *eventService) CreateEvent(req *entity.CreateEventReq) (*entity.Event, error) {
-	// Parse date
-	eventDate, err := time.Parse("2006-01-02", req.Date)
+func (s *eventService) CreateEvent(req *entity.CreateEventReq, userID int, role string) (*entity.Event, error) {
+	organizerID, err := resolveOrganizerID(s.userRepository, req.OrganizerID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	loc, err := loadEventLocation(req.TimeZone)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse waktu mulai dan selesai, tanggal tanpa jam dibaca pada zona waktu event
+	eventDate, endDate, err := parseEventTimes(req.Date, req.EndDate, loc)
 	if err != nil {
-		return nil, errors.New("invalid date format, must be YYYY-MM-DD")
+		return nil, err
 	}
 
 	existingEventName, err := s.eventRepository.IsEventNameExists(req.Name)
@@ -43,17 +73,28 @@ func (s *eventService) CreateEvent(req *entity.CreateEventReq) (*entity.Event, e
 		return nil, errors.New("event name already exists")
 	}
 
+	if req.VenueID != 0 {
+		if err := s.validateVenueCapacity(req.VenueID, req.Capacity); err != nil {
+			return nil, err
+		}
+	}
+
 	event := &entity.Event{
 		Name:               req.Name,
 		Description:        req.Description,
 		Location:           req.Location,
 		Date:               eventDate,
+		EndDate:            endDate,
+		TimeZone:           loc.String(),
 		Category:           req.Category,
 		Capacity:           req.Capacity,
 		Price:              req.Price,
-		Status:             "Aktif",
+		Status:             entity.EventStatusActive,
 		AvailableTickets:   req.Capacity,
-		TicketAvailability: "Tersedia",
+		TicketAvailability: entity.TicketAvailabilityAvailable,
+		MaxTicketsPerUser:  req.MaxTicketsPerUser,
+		VenueID:            req.VenueID,
+		OrganizerID:        organizerID,
 	}
 
 	err = s.eventRepository.CreateEvent(event)
@@ -64,56 +105,171 @@ func (s *eventService) FindEventByID(id int) (*entity.Event, error) {
 	return s.eventRepository.FindEventByID(id)
 }
 
+// GetEventCalendar membuat file .ics berisi satu event untuk ditambahkan ke kalender
+func (s *eventService) GetEventCalendar(id int) ([]byte, error) {
+	event, err := s.eventRepository.FindEventByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return utils.BuildCalendar("", []utils.ICalEvent{toICalEvent(*event)}, time.Now()), nil
+}
+
 func (s *eventService) FindAllEvents() ([]entity.Event, error) {
 	return s.eventRepository.FindAllEvents()
 }
 
-func (s *eventService) UpdateEvent(id int, req *entity.UpdateEventReq) error {
+func (s *eventService) UpdateEvent(id int, req *entity.UpdateEventReq, userID int, role string) error {
 	existingEvent, err := s.eventRepository.FindEventByID(id)
 	if err != nil {
 		return err
 	}
 
+	if err := checkEventOwner(existingEvent.OrganizerID, userID, role); err != nil {
+		return err
+	}
+
+	// Hanya kolom yang diubah yang ditulis, sehingga stok dan status yang diubah
+	// transaksi lain atau scheduler tidak tertimpa nilai lama
+	updates := map[string]interface{}{}
+
+	// Hanya admin yang boleh memindahkan event ke organizer lain
+	if req.OrganizerID != 0 && req.OrganizerID != existingEvent.OrganizerID {
+		if role != "admin" {
+			return ErrNotEventOrganizer
+		}
+
+		organizerID, err := resolveOrganizerID(s.userRepository, req.OrganizerID, userID, role)
+		if err != nil {
+			return err
+		}
+		updates["organizer_id"] = organizerID
+	}
+
 	if req.Name != "" {
-		existingEvent.Name = req.Name
+		updates["name"] = req.Name
 	}
 
 	if req.Description != "" {
-		existingEvent.Description = req.Description
+		updates["description"] = req.Description
 	}
 
 	if req.Location != "" {
-		existingEvent.Location = req.Location
+		updates["location"] = req.Location
 	}
 
 	if req.Category != "" {
-		existingEvent.Category = req.Category
+		updates["category"] = req.Category
 	}
 
-	if req.Capacity != 0 {
-		existingEvent.Capacity = req.Capacity
+	// Zona waktu baru hanya mengubah cara waktu ditampilkan dan dibaca, bukan waktu event
+	timeZone := existingEvent.TimeZone
+	if req.TimeZone != "" {
+		loc, err := loadEventLocation(req.TimeZone)
+		if err != nil {
+			return err
+		}
+		timeZone = loc.String()
+		updates["time_zone"] = timeZone
+	}
+
+	loc, err := loadEventLocation(timeZone)
+	if err != nil {
+		return err
+	}
+
+	// Tanggal dan waktu selesai menentukan kapan scheduler mengubah status event
+	eventDate := existingEvent.Date
+	if req.Date != "" {
+		eventDate, _, err = parseEventTime("date", req.Date, loc)
+		if err != nil {
+			return err
+		}
+
+		// Event yang dijadwalkan ulang tetap memiliki durasi yang sama
+		updates["end_date"] = existingEvent.EndDate.Add(eventDate.Sub(existingEvent.Date))
+		updates["date"] = eventDate
+	}
+
+	if req.EndDate != "" {
+		endDate, err := parseEventEndTime(req.EndDate, eventDate, loc)
+		if err != nil {
+			return err
+		}
+		updates["end_date"] = endDate
 	}
 
 	if req.Price != 0 {
-		existingEvent.Price = req.Price
+		updates["price"] = req.Price
 	}
 
-	if req.Status != "" {
-		existingEvent.Status = req.Status
+	// Pembatalan harus membuat refund dan menarik listing resale, jadi tidak lewat update biasa
+	if entity.EventStatus(req.Status) == entity.EventStatusCancelled && existingEvent.Status != entity.EventStatusCancelled {
+		return fmt.Errorf("%w: use PATCH /events/:id/cancel to cancel an event", ErrInvalidStatusTransition)
 	}
 
-	if req.AvailableTickets != 0 {
-		existingEvent.AvailableTickets = req.AvailableTickets
+	// Status hanya boleh berpindah sesuai tabel transisi event. Repository mengecek
+	// ulang transisinya terhadap status terbaru di bawah lock event.
+	if req.Status != "" && entity.EventStatus(req.Status) != existingEvent.Status {
+		if err := checkEventTransition(existingEvent.Status, entity.EventStatus(req.Status)); err != nil {
+			return err
+		}
+		updates["status"] = entity.EventStatus(req.Status)
 	}
 
 	if req.TicketAvailability != "" {
-		existingEvent.TicketAvailability = req.TicketAvailability
+		updates["ticket_availability"] = entity.TicketAvailability(req.TicketAvailability)
 	}
 
-	return s.eventRepository.UpdateEvent(id, existingEvent)
+	if req.MaxTicketsPerUser != 0 {
+		updates["max_tickets_per_user"] = req.MaxTicketsPerUser
+	}
+
+	venueID := existingEvent.VenueID
+	if req.VenueID != 0 {
+		venueID = req.VenueID
+		updates["venue_id"] = venueID
+	}
+
+	capacity := existingEvent.Capacity
+	if req.Capacity != 0 {
+		capacity = req.Capacity
+	}
+
+	if venueID != 0 {
+		if err := s.validateVenueCapacity(venueID, capacity); err != nil {
+			return err
+		}
+	}
+
+	// Perubahan kapasitas ikut mengubah jumlah tiket yang tersedia, dihitung di
+	// repository dari stok terbaru di bawah lock event
+	capacityIncreased, err := s.eventRepository.UpdateEvent(id, updates, req.Capacity)
+	if err != nil {
+		return err
+	}
+
+	// Tawarkan tiket tambahan kepada user di waitlist. Kegagalan cukup dicatat
+	// karena sweeper akan mencoba lagi.
+	if capacityIncreased {
+		if _, err := s.waitlistRepository.OfferAvailableTickets(id); err != nil {
+			log.Printf("Failed to process waitlist for event %d: %v", id, err)
+		}
+	}
+
+	return nil
 }
 
-func (s *eventService) DeleteEvent(id int) error {
+func (s *eventService) DeleteEvent(id int, userID int, role string) error {
+	event, err := s.eventRepository.FindEventByID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := checkEventOwner(event.OrganizerID, userID, role); err != nil {
+		return err
+	}
+
 	return s.eventRepository.DeleteEvent(id)
 }
 
@@ -125,46 +281,56 @@ func (s *eventService) SearchEvents(searchQuery string, minPrice, maxPrice int,
 
 	var eventRes []entity.EventRes
 	for _, event := range events {
-		eventRes = append(eventRes, entity.EventRes{
-			ID:                 event.ID,
-			Name:               event.Name,
-			Description:        event.Description,
-			Location:           event.Location,
-			Date:               event.Date.Format("2006-01-02 15:04:05"),
-			Category:           event.Category,
-			Capacity:           event.Capacity,
-			Price:              event.Price,
-			Status:             event.Status,
-			AvailableTickets:   event.AvailableTickets,
-			TicketAvailability: event.TicketAvailability,
-			CreatedAt:          event.CreatedAt.Format("2006-01-02 15:04:05"),
-			UpdatedAt:          event.UpdatedAt.Format("2006-01-02 15:04:05"),
-		})
+		eventRes = append(eventRes, toEventRes(event))
 	}
 
 	return eventRes, nil
 }
 
-func (s *eventService) GetEventReport(startDate, endDate time.Time) (*entity.EventReport, error) {
+func toEventRes(event entity.Event) entity.EventRes {
+	return entity.EventRes{
+		ID:                 event.ID,
+		Name:               event.Name,
+		Description:        event.Description,
+		Location:           event.Location,
+		Date:               formatEventTime(event.Date, event.TimeZone),
+		EndDate:            formatEventTime(event.EndDate, event.TimeZone),
+		TimeZone:           event.TimeZone,
+		Category:           event.Category,
+		Capacity:           event.Capacity,
+		Price:              event.Price,
+		Status:             string(event.Status),
+		AvailableTickets:   event.AvailableTickets,
+		TicketAvailability: string(event.TicketAvailability),
+		MaxTicketsPerUser:  event.MaxTicketsPerUser,
+		VenueID:            event.VenueID,
+		SeriesID:           event.SeriesID,
+		OrganizerID:        event.OrganizerID,
+		CreatedAt:          event.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:          event.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+func (s *eventService) GetEventReport(startDate, endDate time.Time, userID int, role string) (*entity.EventReport, error) {
+	// Organizer hanya melihat laporan event miliknya
+	organizerID := reportOrganizerID(userID, role)
+
 	// Hitung total event
-	totalEvent, err := s.eventRepository.GetTotalEvents(startDate, endDate)
+	totalEvent, err := s.eventRepository.GetTotalEvents(startDate, endDate, organizerID)
 	if err != nil {
 		return nil, err
 	}
 
-	// Daftar status event yang ingin dihitung
-	statuses := []string{"Aktif", "Berlangsung", "Selesai", "Dibatalkan"}
-
 	// Slice untuk menyimpan distribusi status event
 	var statusDistribution []entity.EventStatusDistribution
 
 	// Loop melalui setiap status dan hitung distribusinya
-	for _, status := range statuses {
-		distribution, err := s.eventRepository.GetEventStatusDistribution(status, startDate, endDate)
+	for _, status := range entity.EventStatuses {
+		distribution, err := s.eventRepository.GetEventStatusDistribution(status, startDate, endDate, organizerID)
 		if err != nil {
 			return nil, err
 		}
-		distribution.EventStatus = status
+		distribution.EventStatus = string(status)
 		statusDistribution = append(statusDistribution, distribution)
 	}
 
@@ -174,18 +340,208 @@ func (s *eventService) GetEventReport(startDate, endDate time.Time) (*entity.Eve
 	}, nil
 }
 
-func (s *eventService) CancelEvent(eventID int) error {
+func (s *eventService) CancelEvent(eventID int, userID int, role string) error {
 	// Cek apakah event ada
 	event, err := s.eventRepository.FindEventByID(eventID)
 	if err != nil {
 		return err
 	}
 
-	// Validasi: Event hanya bisa dibatalkan jika statusnya "active" atau "upcoming"
-	if event.Status != "active" && event.Status != "upcoming" {
-		return errors.New("event cannot be cancelled because it is not in 'active' or 'upcoming' status")
+	if err := checkEventOwner(event.OrganizerID, userID, role); err != nil {
+		return err
+	}
+
+	// Validasi: Event hanya bisa dibatalkan sebelum selesai
+	if err := checkEventTransition(event.Status, entity.EventStatusCancelled); err != nil {
+		return err
 	}
 
 	// Batalkan event dan semua tiket terkait
-	return s.eventRepository.CancelEvent(eventID)
+	refunds, err := s.eventRepository.CancelEvent(eventID)
+	if err != nil {
+		return err
+	}
+
+	// Kembalikan dana melalui penyedia pembayaran seperti pada CancelTicket. Jika gagal,
+	// refund tetap berstatus Pending dan bisa diselesaikan admin secara manual.
+	for i := range refunds {
+		if refunds[i].OrderID == 0 {
+			continue
+		}
+		if err := s.paymentService.RefundPayment(refunds[i].OrderID, &refunds[i]); err != nil {
+			log.Printf("Failed to process refund %d: %v", refunds[i].ID, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *eventService) CreateTier(eventID int, req *entity.CreateTierReq, userID int, role string) (*entity.TierRes, error) {
+	event, err := s.eventRepository.FindEventByID(eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkEventOwner(event.OrganizerID, userID, role); err != nil {
+		return nil, err
+	}
+
+	saleStart, err := parseSaleTime(req.SaleStart)
+	if err != nil {
+		return nil, err
+	}
+
+	saleEnd, err := parseSaleTime(req.SaleEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	if saleStart != nil && saleEnd != nil && !saleEnd.After(*saleStart) {
+		return nil, errors.New("sale_end must be after sale_start")
+	}
+
+	tier := &entity.TicketTier{
+		EventID:          eventID,
+		Name:             req.Name,
+		Price:            req.Price,
+		Capacity:         req.Capacity,
+		AvailableTickets: req.Capacity,
+		SaleStart:        saleStart,
+		SaleEnd:          saleEnd,
+	}
+
+	// Kapasitas tier dicek terhadap kapasitas event di repository di bawah lock event
+	err = s.tierRepository.CreateTier(tier)
+	if err != nil {
+		return nil, err
+	}
+
+	tierRes := toTierRes(*tier)
+	return &tierRes, nil
+}
+
+func (s *eventService) FindTiersByEventID(eventID int) ([]entity.TierRes, error) {
+	tiers, err := s.tierRepository.FindTiersByEventID(eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	tierRes := []entity.TierRes{}
+	for _, tier := range tiers {
+		tierRes = append(tierRes, toTierRes(tier))
+	}
+
+	return tierRes, nil
+}
+
+func (s *eventService) UpdateTier(eventID, tierID int, req *entity.UpdateTierReq, userID int, role string) error {
+	event, err := s.eventRepository.FindEventByID(eventID)
+	if err != nil {
+		return err
+	}
+
+	if err := checkEventOwner(event.OrganizerID, userID, role); err != nil {
+		return err
+	}
+
+	existingTier, err := s.tierRepository.FindTierByID(tierID)
+	if err != nil {
+		return err
+	}
+
+	if existingTier.EventID != eventID {
+		return errors.New("ticket tier not found for this event")
+	}
+
+	if req.Name != "" {
+		existingTier.Name = req.Name
+	}
+
+	if req.Price != 0 {
+		existingTier.Price = req.Price
+	}
+
+	if req.SaleStart != "" {
+		existingTier.SaleStart, err = parseSaleTime(req.SaleStart)
+		if err != nil {
+			return err
+		}
+	}
+
+	if req.SaleEnd != "" {
+		existingTier.SaleEnd, err = parseSaleTime(req.SaleEnd)
+		if err != nil {
+			return err
+		}
+	}
+
+	return s.tierRepository.UpdateTier(tierID, existingTier)
+}
+
+func (s *eventService) FindSeatsByEventID(eventID int) ([]entity.SeatAvailability, error) {
+	event, err := s.eventRepository.FindEventByID(eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	if event.VenueID == 0 {
+		return nil, errors.New("this event does not use assigned seating")
+	}
+
+	return s.venueRepository.FindSeatAvailability(event.ID, event.VenueID)
+}
+
+// validateVenueCapacity memastikan kapasitas event tidak melebihi jumlah kursi di venue
+func (s *eventService) validateVenueCapacity(venueID, capacity int) error {
+	totalSeats, err := s.venueRepository.CountSeats(venueID)
+	if err != nil {
+		return err
+	}
+
+	if totalSeats == 0 {
+		return errors.New("venue not found or has no seats")
+	}
+
+	if capacity > totalSeats {
+		return errors.New("event capacity exceeds the number of seats in the venue")
+	}
+
+	return nil
+}
+
+// parseSaleTime mengubah string waktu penjualan menjadi *time.Time, string kosong berarti tanpa batas
+func parseSaleTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse("2006-01-02 15:04:05", value)
+	if err != nil {
+		return nil, errors.New("invalid sale time format, must be YYYY-MM-DD HH:MM:SS")
+	}
+
+	return &t, nil
+}
+
+func toTierRes(tier entity.TicketTier) entity.TierRes {
+	tierRes := entity.TierRes{
+		ID:               tier.ID,
+		EventID:          tier.EventID,
+		Name:             tier.Name,
+		Price:            tier.Price,
+		Capacity:         tier.Capacity,
+		AvailableTickets: tier.AvailableTickets,
+		CreatedAt:        tier.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:        tier.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+
+	if tier.SaleStart != nil {
+		tierRes.SaleStart = tier.SaleStart.Format("2006-01-02 15:04:05")
+	}
+
+	if tier.SaleEnd != nil {
+		tierRes.SaleEnd = tier.SaleEnd.Format("2006-01-02 15:04:05")
+	}
+
+	return tierRes
 }
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	// Database zona waktu ikut dibundel agar server tidak bergantung pada tzdata sistem
+	_ "time/tzdata"
+)
+
+// Zona waktu event yang tidak menyebutkan zonanya
+const defaultTimeZone = "UTC"
+
+var ErrInvalidTimeZone = errors.New("invalid time zone")
+
+// loadEventLocation memuat zona waktu IANA, misalnya Asia/Jakarta
+func loadEventLocation(name string) (*time.Location, error) {
+	if name == "" {
+		name = defaultTimeZone
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidTimeZone, name)
+	}
+
+	return loc, nil
+}
+
+// parseEventTime menerima timestamp RFC 3339, YYYY-MM-DD HH:MM:SS atau YYYY-MM-DD.
+// Dua format terakhir dibaca pada zona waktu event. dateOnly bernilai true jika
+// input hanya berupa tanggal.
+func parseEventTime(field, value string, loc *time.Location) (t time.Time, dateOnly bool, err error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.In(loc), false, nil
+	}
+
+	if t, err := time.ParseInLocation("2006-01-02 15:04:05", value, loc); err == nil {
+		return t, false, nil
+	}
+
+	if t, err := time.ParseInLocation("2006-01-02", value, loc); err == nil {
+		return t, true, nil
+	}
+
+	return time.Time{}, false, fmt.Errorf("invalid %s format, must be RFC 3339 (2006-01-02T15:04:05+07:00) or YYYY-MM-DD", field)
+}
+
+// parseEventTimes membaca waktu mulai dan selesai event. Waktu selesai yang kosong
+// berarti event selesai di akhir hari event dimulai, dan waktu selesai berupa tanggal
+// berarti event selesai di akhir hari tersebut.
+func parseEventTimes(startValue, endValue string, loc *time.Location) (time.Time, time.Time, error) {
+	start, _, err := parseEventTime("date", startValue, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	end, err := parseEventEndTime(endValue, start, loc)
+	return start, end, err
+}
+
+func parseEventEndTime(value string, start time.Time, loc *time.Location) (time.Time, error) {
+	var end time.Time
+	if value == "" {
+		year, month, day := start.In(loc).Date()
+		end = time.Date(year, month, day+1, 0, 0, 0, 0, loc)
+	} else {
+		var dateOnly bool
+		var err error
+		end, dateOnly, err = parseEventTime("end_date", value, loc)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		if dateOnly {
+			end = end.AddDate(0, 0, 1)
+		}
+	}
+
+	if !end.After(start) {
+		return time.Time{}, errors.New("end_date must be after the event start")
+	}
+
+	return end, nil
+}
+
+// formatEventTime menampilkan waktu event pada zona waktu event
+func formatEventTime(t time.Time, timeZone string) string {
+	loc, err := loadEventLocation(timeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+	return t.In(loc).Format(time.RFC3339)
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"github.com/Ayyasy123/dibimbing-take-home-test/repository"
+	"github.com/Ayyasy123/dibimbing-take-home-test/utils"
+	"gorm.io/gorm"
+)
+
+type KeyService interface {
+	RotateKeys(now time.Time) error
+	ReloadKeys() error
+	JWKS() utils.JWKSet
+}
+
+type keyService struct {
+	signingKeyRepository repository.SigningKeyRepository
+	keyRing              *utils.KeyRing
+	policy               entity.KeyPolicy
+}
+
+func NewKeyService(signingKeyRepository repository.SigningKeyRepository, keyRing *utils.KeyRing, policy entity.KeyPolicy) KeyService {
+	return &keyService{
+		signingKeyRepository: signingKeyRepository,
+		keyRing:              keyRing,
+		policy:               policy,
+	}
+}
+
+// RotateKeys membuat kunci baru jika belum ada kunci aktif, kunci aktif sudah melewati
+// RotationInterval atau algoritmanya berbeda dari policy. Kunci yang masa tenggangnya
+// habis dihapus lalu key ring dimuat ulang dari database.
+func (s *keyService) RotateKeys(now time.Time) error {
+	dueBefore := now.Add(-s.policy.RotationInterval)
+
+	active, err := s.signingKeyRepository.FindActiveKey()
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	// Kunci hanya dibuat jika memang perlu karena membuat kunci RSA cukup mahal
+	if err != nil || active.Algorithm != s.policy.Algorithm || !active.CreatedAt.After(dueBefore) {
+		if err := s.rotate(now, dueBefore); err != nil {
+			return err
+		}
+	}
+
+	deleted, err := s.signingKeyRepository.DeleteRetiredKeys(now.Add(-s.policy.GracePeriod))
+	if err != nil {
+		return err
+	}
+
+	if deleted > 0 {
+		log.Printf("Deleted %d retired JWT signing keys", deleted)
+	}
+
+	return s.ReloadKeys()
+}
+
+func (s *keyService) rotate(now, dueBefore time.Time) error {
+	keyPair, err := utils.GenerateKeyPair(s.policy.Algorithm)
+	if err != nil {
+		return err
+	}
+
+	privatePEM, publicPEM, err := utils.EncodeKeyPair(keyPair)
+	if err != nil {
+		return err
+	}
+
+	rotated, err := s.signingKeyRepository.RotateKey(&entity.SigningKey{
+		KID:        keyPair.KID,
+		Algorithm:  keyPair.Algorithm,
+		PrivateKey: privatePEM,
+		PublicKey:  publicPEM,
+		CreatedAt:  now,
+	}, dueBefore)
+	if err != nil {
+		return err
+	}
+
+	if rotated {
+		log.Printf("Rotated JWT signing key, new kid %s (%s)", keyPair.KID, keyPair.Algorithm)
+	}
+
+	return nil
+}
+
+// ReloadKeys memuat kunci aktif dan kunci dalam masa tenggang ke key ring
+func (s *keyService) ReloadKeys() error {
+	keys, err := s.signingKeyRepository.FindVerificationKeys(time.Now().Add(-s.policy.GracePeriod))
+	if err != nil {
+		return err
+	}
+
+	var active *utils.KeyPair
+	keyPairs := make([]*utils.KeyPair, 0, len(keys))
+	for _, key := range keys {
+		keyPair, err := utils.ParseKeyPair(key.KID, key.Algorithm, key.PrivateKey)
+		if err != nil {
+			return err
+		}
+
+		// Kunci diurutkan dari yang terbaru, kunci aktif pertama yang dipakai menandatangani
+		if key.RetiredAt == nil && active == nil {
+			active = keyPair
+		}
+		keyPairs = append(keyPairs, keyPair)
+	}
+
+	if active == nil {
+		return utils.ErrNoSigningKey
+	}
+
+	s.keyRing.Replace(active, keyPairs)
+	return nil
+}
+
+func (s *keyService) JWKS() utils.JWKSet {
+	return s.keyRing.JWKS()
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/repository"
+	"gorm.io/gorm"
+)
+
+// ErrNotEventOrganizer dikembalikan ketika organizer mencoba mengelola event atau
+// series milik organizer lain. RoleAuth hanya memeriksa role, kepemilikan diperiksa di sini.
+var ErrNotEventOrganizer = errors.New("event is managed by another organizer")
+
+// ErrInvalidOrganizer dikembalikan ketika organizer_id bukan milik user dengan role organizer
+var ErrInvalidOrganizer = errors.New("organizer_id must belong to a user with the organizer role")
+
+// checkEventOwner mengizinkan admin mengelola semua event, sedangkan organizer
+// hanya event yang OrganizerID-nya sama dengan ID miliknya
+func checkEventOwner(organizerID, userID int, role string) error {
+	if role == "admin" {
+		return nil
+	}
+
+	if role == "organizer" && organizerID != 0 && organizerID == userID {
+		return nil
+	}
+
+	return ErrNotEventOrganizer
+}
+
+// reportOrganizerID mengembalikan filter organizer untuk laporan. Organizer hanya
+// melihat data event miliknya, 0 berarti semua event.
+func reportOrganizerID(userID int, role string) int {
+	if role == "organizer" {
+		return userID
+	}
+	return 0
+}
+
+// resolveOrganizerID menentukan pemilik event atau series baru. Organizer selalu
+// menjadi pemilik, sedangkan admin boleh menunjuk organizer lain atau membiarkannya kosong.
+func resolveOrganizerID(userRepository repository.UserRepository, requestedID, userID int, role string) (int, error) {
+	if role == "organizer" {
+		if requestedID != 0 && requestedID != userID {
+			return 0, ErrNotEventOrganizer
+		}
+		return userID, nil
+	}
+
+	if requestedID == 0 {
+		return 0, nil
+	}
+
+	organizer, err := userRepository.FindUserByID(requestedID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && organizer.Role != "organizer") {
+		return 0, ErrInvalidOrganizer
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return organizer.ID, nil
+}
@@ -0,0 +1,263 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"github.com/Ayyasy123/dibimbing-take-home-test/payment"
+	"github.com/Ayyasy123/dibimbing-take-home-test/repository"
+)
+
+var (
+	ErrInvalidWebhookSignature  = errors.New("invalid webhook signature")
+	ErrInvalidPaymentTransition = errors.New("invalid payment status transition")
+	ErrNotOrderOwner            = errors.New("order does not belong to this user")
+)
+
+// Status pembayaran yang boleh dicapai dari setiap status
+var paymentTransitions = map[string][]string{
+	"Pending": {"Paid", "Failed"},
+	"Paid":    {"Refunded"},
+}
+
+type PaymentService interface {
+	StartPayment(order *entity.Order) (*entity.PaymentRes, error)
+	RefundPayment(orderID int, refund *entity.Refund) error
+	FindPaymentByOrderID(orderID int) (*entity.PaymentRes, error)
+	HandleWebhook(payload []byte, signature string) error
+	SimulateMockPayment(chargeID, eventType string, userID int) error
+}
+
+type paymentService struct {
+	gateway           payment.PaymentGateway
+	paymentRepository repository.PaymentRepository
+	refundRepository  repository.RefundRepository
+	orderRepository   repository.OrderRepository
+}
+
+func NewPaymentService(gateway payment.PaymentGateway, paymentRepository repository.PaymentRepository, refundRepository repository.RefundRepository, orderRepository repository.OrderRepository) PaymentService {
+	return &paymentService{
+		gateway:           gateway,
+		paymentRepository: paymentRepository,
+		refundRepository:  refundRepository,
+		orderRepository:   orderRepository,
+	}
+}
+
+func (s *paymentService) StartPayment(order *entity.Order) (*entity.PaymentRes, error) {
+	orderPayment := &entity.Payment{
+		OrderID:  order.ID,
+		Provider: s.gateway.Name(),
+		Amount:   order.TotalPrice,
+		Currency: order.Currency,
+		Status:   "Pending",
+	}
+
+	// Order gratis tidak perlu melalui penyedia pembayaran
+	if order.TotalPrice == 0 {
+		orderPayment.Provider = "free"
+	}
+
+	err := s.paymentRepository.CreatePayment(orderPayment)
+	if err != nil {
+		// Tanpa data pembayaran sweeper tidak akan pernah melepas order ini,
+		// jadi stok yang sudah dipesan langsung dikembalikan
+		if failErr := s.orderRepository.FailPendingOrder(order.ID); failErr != nil {
+			log.Printf("Failed to release order %d after payment error: %v", order.ID, failErr)
+		}
+		return nil, err
+	}
+
+	if order.TotalPrice == 0 {
+		if err := s.paymentRepository.MarkPaid(orderPayment.ID); err != nil {
+			return nil, err
+		}
+		orderPayment.Status = "Paid"
+		return toPaymentRes(orderPayment), nil
+	}
+
+	charge, err := s.gateway.CreateCharge(payment.ChargeRequest{
+		OrderID:     order.ID,
+		Amount:      order.TotalPrice,
+		Currency:    order.Currency,
+		Description: fmt.Sprintf("Order #%d", order.ID),
+	})
+	if err != nil {
+		// Charge gagal dibuat, lepas kembali stok yang sudah dipesan
+		if failErr := s.paymentRepository.MarkFailed(orderPayment.ID); failErr != nil {
+			return nil, failErr
+		}
+		return nil, err
+	}
+
+	err = s.paymentRepository.UpdateCharge(orderPayment.ID, charge.ID, charge.CheckoutURL)
+	if err != nil {
+		// Tanpa charge ID pembayaran tidak bisa diselesaikan, lepas kembali stoknya
+		if failErr := s.paymentRepository.MarkFailed(orderPayment.ID); failErr != nil {
+			log.Printf("Failed to release order %d after payment error: %v", order.ID, failErr)
+		}
+		return nil, err
+	}
+
+	orderPayment.ChargeID = charge.ID
+	orderPayment.CheckoutURL = charge.CheckoutURL
+	return toPaymentRes(orderPayment), nil
+}
+
+func (s *paymentService) RefundPayment(orderID int, refund *entity.Refund) error {
+	orderPayment, err := s.paymentRepository.FindPaymentByOrderID(orderID)
+	if err != nil {
+		return err
+	}
+
+	if orderPayment.Status != "Paid" {
+		return errors.New("only paid payments can be refunded")
+	}
+
+	// Tidak ada dana yang perlu dikembalikan lewat penyedia pembayaran
+	if refund.Amount == 0 || orderPayment.Provider == "free" {
+		return s.refundRepository.UpdateRefundStatus(refund.ID, "Completed")
+	}
+
+	_, err = s.gateway.Refund(orderPayment.ChargeID, refund.Amount)
+	if err != nil {
+		return err
+	}
+
+	err = s.paymentRepository.AddRefundedAmount(orderPayment.ID, refund.Amount)
+	if err != nil {
+		return err
+	}
+
+	refund.Status = "Completed"
+	return s.refundRepository.UpdateRefundStatus(refund.ID, "Completed")
+}
+
+func (s *paymentService) FindPaymentByOrderID(orderID int) (*entity.PaymentRes, error) {
+	orderPayment, err := s.paymentRepository.FindPaymentByOrderID(orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toPaymentRes(orderPayment), nil
+}
+
+func (s *paymentService) HandleWebhook(payload []byte, signature string) error {
+	event, err := s.gateway.VerifyWebhook(payload, signature)
+	if err != nil {
+		return ErrInvalidWebhookSignature
+	}
+
+	orderPayment, err := s.paymentRepository.FindPaymentByChargeID(event.ChargeID)
+	if err != nil {
+		return err
+	}
+
+	switch event.Type {
+	case payment.EventChargeAuthorized:
+		if err := checkPaymentTransition(orderPayment.Status, "Paid"); err != nil {
+			return ignoreDuplicateWebhook(orderPayment.Status, "Paid", err)
+		}
+
+		// Dana sudah diotorisasi, capture sebelum tiket diterbitkan
+		if _, err := s.gateway.Capture(orderPayment.ChargeID); err != nil {
+			return s.paymentRepository.MarkFailed(orderPayment.ID)
+		}
+		return s.markPaid(orderPayment)
+
+	case payment.EventChargeSucceeded:
+		if err := checkPaymentTransition(orderPayment.Status, "Paid"); err != nil {
+			return ignoreDuplicateWebhook(orderPayment.Status, "Paid", err)
+		}
+		return s.markPaid(orderPayment)
+
+	case payment.EventChargeFailed:
+		if err := checkPaymentTransition(orderPayment.Status, "Failed"); err != nil {
+			return ignoreDuplicateWebhook(orderPayment.Status, "Failed", err)
+		}
+		return s.paymentRepository.MarkFailed(orderPayment.ID)
+	}
+
+	return fmt.Errorf("unsupported webhook event type %q", event.Type)
+}
+
+// markPaid menerbitkan tiket order yang sudah dibayar. Jika order sudah tidak berlaku
+// saat dana masuk, misalnya karena event dibatalkan, dana dikembalikan seluruhnya.
+func (s *paymentService) markPaid(orderPayment *entity.Payment) error {
+	err := s.paymentRepository.MarkPaid(orderPayment.ID)
+	if !errors.Is(err, repository.ErrOrderNotPayable) {
+		return err
+	}
+
+	if orderPayment.Amount > 0 {
+		if _, err := s.gateway.Refund(orderPayment.ChargeID, orderPayment.Amount); err != nil {
+			return err
+		}
+	}
+
+	return s.paymentRepository.MarkRefunded(orderPayment.ID)
+}
+
+// SimulateMockPayment mengirim webhook bertanda tangan ke HandleWebhook seolah-olah
+// berasal dari penyedia pembayaran. Hanya tersedia saat memakai mock gateway.
+func (s *paymentService) SimulateMockPayment(chargeID, eventType string, userID int) error {
+	mockGateway, ok := s.gateway.(*payment.MockGateway)
+	if !ok {
+		return errors.New("payment simulation is only available with the mock gateway")
+	}
+
+	// User hanya boleh mensimulasikan pembayaran untuk order miliknya sendiri
+	orderPayment, err := s.paymentRepository.FindPaymentByChargeID(chargeID)
+	if err != nil {
+		return err
+	}
+	order, err := s.orderRepository.FindOrderByID(orderPayment.OrderID)
+	if err != nil {
+		return err
+	}
+	if order.UserID != userID {
+		return ErrNotOrderOwner
+	}
+
+	payload, err := json.Marshal(payment.WebhookEvent{Type: eventType, ChargeID: chargeID})
+	if err != nil {
+		return err
+	}
+
+	return s.HandleWebhook(payload, mockGateway.Sign(payload))
+}
+
+func checkPaymentTransition(from, to string) error {
+	for _, allowed := range paymentTransitions[from] {
+		if allowed == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s to %s", ErrInvalidPaymentTransition, from, to)
+}
+
+// Penyedia pembayaran bisa mengirim webhook yang sama lebih dari sekali,
+// jadi webhook untuk status yang sudah tercapai diabaikan.
+func ignoreDuplicateWebhook(current, target string, err error) error {
+	// Pembayaran yang sudah di-refund berarti dananya pernah diterima
+	if current == target || (target == "Paid" && current == "Refunded") {
+		return nil
+	}
+	return err
+}
+
+func toPaymentRes(orderPayment *entity.Payment) *entity.PaymentRes {
+	return &entity.PaymentRes{
+		ID:             orderPayment.ID,
+		Provider:       orderPayment.Provider,
+		ChargeID:       orderPayment.ChargeID,
+		Amount:         orderPayment.Amount,
+		RefundedAmount: orderPayment.RefundedAmount,
+		Currency:       orderPayment.Currency,
+		Status:         orderPayment.Status,
+		CheckoutURL:    orderPayment.CheckoutURL,
+	}
+}
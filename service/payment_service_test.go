@@ -0,0 +1,389 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"github.com/Ayyasy123/dibimbing-take-home-test/payment"
+	"github.com/Ayyasy123/dibimbing-take-home-test/repository"
+)
+
+// fakePaymentRepository menyimpan pembayaran di memori dan meniru aturan status
+// repository asli: hanya pembayaran Pending yang bisa dibayar, digagalkan atau di-refund.
+type fakePaymentRepository struct {
+	payments       map[int]*entity.Payment
+	nextID         int
+	createErr      error
+	orderNotValid  bool // MarkPaid mengembalikan ErrOrderNotPayable
+	markPaidCalls  int
+	markFailedIDs  []int
+	markRefundedID int
+}
+
+func newFakePaymentRepository() *fakePaymentRepository {
+	return &fakePaymentRepository{payments: map[int]*entity.Payment{}}
+}
+
+func (r *fakePaymentRepository) CreatePayment(p *entity.Payment) error {
+	if r.createErr != nil {
+		return r.createErr
+	}
+	r.nextID++
+	p.ID = r.nextID
+	stored := *p
+	r.payments[p.ID] = &stored
+	return nil
+}
+
+func (r *fakePaymentRepository) UpdateCharge(id int, chargeID, checkoutURL string) error {
+	r.payments[id].ChargeID = chargeID
+	r.payments[id].CheckoutURL = checkoutURL
+	return nil
+}
+
+func (r *fakePaymentRepository) FindPaymentByChargeID(chargeID string) (*entity.Payment, error) {
+	for _, p := range r.payments {
+		if p.ChargeID == chargeID {
+			found := *p
+			return &found, nil
+		}
+	}
+	return nil, errors.New("record not found")
+}
+
+func (r *fakePaymentRepository) FindPaymentByOrderID(orderID int) (*entity.Payment, error) {
+	for _, p := range r.payments {
+		if p.OrderID == orderID {
+			found := *p
+			return &found, nil
+		}
+	}
+	return nil, errors.New("record not found")
+}
+
+func (r *fakePaymentRepository) pending(id int) (*entity.Payment, error) {
+	p, ok := r.payments[id]
+	if !ok {
+		return nil, errors.New("record not found")
+	}
+	if p.Status != "Pending" {
+		return nil, errors.New("payment is no longer pending")
+	}
+	return p, nil
+}
+
+func (r *fakePaymentRepository) MarkPaid(id int) error {
+	r.markPaidCalls++
+	p, err := r.pending(id)
+	if err != nil {
+		return err
+	}
+	if r.orderNotValid {
+		return repository.ErrOrderNotPayable
+	}
+	p.Status = "Paid"
+	return nil
+}
+
+func (r *fakePaymentRepository) MarkFailed(id int) error {
+	r.markFailedIDs = append(r.markFailedIDs, id)
+	p, err := r.pending(id)
+	if err != nil {
+		return err
+	}
+	p.Status = "Failed"
+	return nil
+}
+
+func (r *fakePaymentRepository) MarkRefunded(id int) error {
+	p, err := r.pending(id)
+	if err != nil {
+		return err
+	}
+	r.markRefundedID = id
+	p.Status = "Refunded"
+	p.RefundedAmount = p.Amount
+	return nil
+}
+
+func (r *fakePaymentRepository) AddRefundedAmount(id, amount int) error {
+	p := r.payments[id]
+	p.RefundedAmount += amount
+	if p.RefundedAmount >= p.Amount {
+		p.Status = "Refunded"
+	}
+	return nil
+}
+
+func (r *fakePaymentRepository) FindPendingPaymentsBefore(before time.Time) ([]entity.Payment, error) {
+	return nil, nil
+}
+
+type fakeRefundRepository struct {
+	statuses map[int]string
+}
+
+func (r *fakeRefundRepository) FindRefundByID(id int) (*entity.Refund, error) {
+	return nil, errors.New("record not found")
+}
+
+func (r *fakeRefundRepository) FindAllRefunds() ([]entity.Refund, error) { return nil, nil }
+
+func (r *fakeRefundRepository) UpdateRefundStatus(id int, status string) error {
+	if r.statuses == nil {
+		r.statuses = map[int]string{}
+	}
+	r.statuses[id] = status
+	return nil
+}
+
+func (r *fakeRefundRepository) FindRefundRules(eventID int) ([]entity.RefundRule, error) {
+	return nil, nil
+}
+
+func (r *fakeRefundRepository) ReplaceRefundRules(eventID int, rules []entity.RefundRule) error {
+	return nil
+}
+
+type fakeOrderRepository struct {
+	orders       map[int]*entity.Order
+	failedOrders []int
+}
+
+func (r *fakeOrderRepository) CreateOrder(order *entity.Order, promoCode string) error {
+	return nil
+}
+
+func (r *fakeOrderRepository) FindOrderByID(id int) (*entity.Order, error) {
+	order, ok := r.orders[id]
+	if !ok {
+		return nil, errors.New("record not found")
+	}
+	return order, nil
+}
+
+func (r *fakeOrderRepository) FailPendingOrder(id int) error {
+	r.failedOrders = append(r.failedOrders, id)
+	return nil
+}
+
+type paymentFixture struct {
+	gateway  *payment.MockGateway
+	payments *fakePaymentRepository
+	refunds  *fakeRefundRepository
+	orders   *fakeOrderRepository
+	service  PaymentService
+}
+
+func newPaymentFixture() *paymentFixture {
+	f := &paymentFixture{
+		gateway:  payment.NewMockGateway("test-secret"),
+		payments: newFakePaymentRepository(),
+		refunds:  &fakeRefundRepository{},
+		orders:   &fakeOrderRepository{orders: map[int]*entity.Order{}},
+	}
+	f.service = NewPaymentService(f.gateway, f.payments, f.refunds, f.orders)
+	return f
+}
+
+// startPayment membuat order milik userID dan memulai pembayarannya
+func (f *paymentFixture) startPayment(t *testing.T, orderID, userID, amount int) *entity.PaymentRes {
+	t.Helper()
+	order := &entity.Order{ID: orderID, UserID: userID, TotalPrice: amount, Currency: "IDR"}
+	f.orders.orders[orderID] = order
+
+	paymentRes, err := f.service.StartPayment(order)
+	if err != nil {
+		t.Fatalf("StartPayment returned an error: %v", err)
+	}
+	return paymentRes
+}
+
+func (f *paymentFixture) webhook(t *testing.T, eventType, chargeID string) error {
+	t.Helper()
+	payload, err := json.Marshal(payment.WebhookEvent{Type: eventType, ChargeID: chargeID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f.service.HandleWebhook(payload, f.gateway.Sign(payload))
+}
+
+func TestStartPaymentCreatesCharge(t *testing.T) {
+	f := newPaymentFixture()
+
+	paymentRes := f.startPayment(t, 1, 10, 200000)
+	if paymentRes.Status != "Pending" || paymentRes.ChargeID == "" || paymentRes.CheckoutURL == "" {
+		t.Fatalf("unexpected payment %+v", paymentRes)
+	}
+
+	stored := f.payments.payments[paymentRes.ID]
+	if stored.ChargeID != paymentRes.ChargeID {
+		t.Fatalf("stored charge id = %q, want %q", stored.ChargeID, paymentRes.ChargeID)
+	}
+}
+
+func TestStartPaymentFreeOrderIsPaidImmediately(t *testing.T) {
+	f := newPaymentFixture()
+
+	paymentRes := f.startPayment(t, 1, 10, 0)
+	if paymentRes.Status != "Paid" || paymentRes.Provider != "free" {
+		t.Fatalf("unexpected payment %+v", paymentRes)
+	}
+	if f.payments.markPaidCalls != 1 {
+		t.Fatalf("MarkPaid called %d times, want 1", f.payments.markPaidCalls)
+	}
+}
+
+func TestStartPaymentReleasesOrderWhenPaymentCannotBeSaved(t *testing.T) {
+	f := newPaymentFixture()
+	f.payments.createErr = errors.New("database unavailable")
+
+	_, err := f.service.StartPayment(&entity.Order{ID: 7, UserID: 10, TotalPrice: 50000, Currency: "IDR"})
+	if err == nil {
+		t.Fatal("expected StartPayment to fail")
+	}
+
+	if len(f.orders.failedOrders) != 1 || f.orders.failedOrders[0] != 7 {
+		t.Fatalf("failed orders = %v, want [7]", f.orders.failedOrders)
+	}
+}
+
+func TestHandleWebhookRejectsInvalidSignature(t *testing.T) {
+	f := newPaymentFixture()
+	paymentRes := f.startPayment(t, 1, 10, 100000)
+
+	payload, _ := json.Marshal(payment.WebhookEvent{Type: payment.EventChargeSucceeded, ChargeID: paymentRes.ChargeID})
+	err := f.service.HandleWebhook(payload, payment.NewMockGateway("wrong-secret").Sign(payload))
+	if !errors.Is(err, ErrInvalidWebhookSignature) {
+		t.Fatalf("err = %v, want ErrInvalidWebhookSignature", err)
+	}
+
+	if f.payments.payments[paymentRes.ID].Status != "Pending" {
+		t.Fatal("payment changed status after a forged webhook")
+	}
+}
+
+func TestHandleWebhookStateMachine(t *testing.T) {
+	f := newPaymentFixture()
+	paymentRes := f.startPayment(t, 1, 10, 100000)
+
+	// Otorisasi di-capture lalu tiket diterbitkan
+	if err := f.webhook(t, payment.EventChargeAuthorized, paymentRes.ChargeID); err != nil {
+		t.Fatalf("authorized webhook returned an error: %v", err)
+	}
+	if status := f.payments.payments[paymentRes.ID].Status; status != "Paid" {
+		t.Fatalf("payment status = %q, want Paid", status)
+	}
+	if _, err := f.gateway.Capture(paymentRes.ChargeID); err == nil {
+		t.Fatal("expected the charge to be captured by the authorized webhook")
+	}
+
+	// Webhook yang sama dikirim ulang oleh penyedia pembayaran
+	if err := f.webhook(t, payment.EventChargeSucceeded, paymentRes.ChargeID); err != nil {
+		t.Fatalf("duplicate succeeded webhook returned an error: %v", err)
+	}
+	if f.payments.markPaidCalls != 1 {
+		t.Fatalf("MarkPaid called %d times, want 1", f.payments.markPaidCalls)
+	}
+
+	// Pembayaran yang sudah Paid tidak bisa menjadi Failed
+	err := f.webhook(t, payment.EventChargeFailed, paymentRes.ChargeID)
+	if !errors.Is(err, ErrInvalidPaymentTransition) {
+		t.Fatalf("err = %v, want ErrInvalidPaymentTransition", err)
+	}
+
+	if err := f.webhook(t, "charge.unknown", paymentRes.ChargeID); err == nil {
+		t.Fatal("expected an unsupported event type to be rejected")
+	}
+}
+
+func TestHandleWebhookFailedPayment(t *testing.T) {
+	f := newPaymentFixture()
+	paymentRes := f.startPayment(t, 1, 10, 100000)
+
+	if err := f.webhook(t, payment.EventChargeFailed, paymentRes.ChargeID); err != nil {
+		t.Fatalf("failed webhook returned an error: %v", err)
+	}
+	if status := f.payments.payments[paymentRes.ID].Status; status != "Failed" {
+		t.Fatalf("payment status = %q, want Failed", status)
+	}
+
+	// Dana yang masuk terlambat untuk pembayaran gagal tidak menerbitkan tiket
+	err := f.webhook(t, payment.EventChargeSucceeded, paymentRes.ChargeID)
+	if !errors.Is(err, ErrInvalidPaymentTransition) {
+		t.Fatalf("err = %v, want ErrInvalidPaymentTransition", err)
+	}
+}
+
+func TestHandleWebhookRefundsOrderThatIsNoLongerValid(t *testing.T) {
+	f := newPaymentFixture()
+	paymentRes := f.startPayment(t, 1, 10, 100000)
+	f.payments.orderNotValid = true
+
+	if err := f.webhook(t, payment.EventChargeAuthorized, paymentRes.ChargeID); err != nil {
+		t.Fatalf("authorized webhook returned an error: %v", err)
+	}
+
+	stored := f.payments.payments[paymentRes.ID]
+	if stored.Status != "Refunded" || stored.RefundedAmount != 100000 {
+		t.Fatalf("payment = %+v, want fully refunded", stored)
+	}
+
+	// Seluruh dana sudah dikembalikan lewat gateway
+	if _, err := f.gateway.Refund(paymentRes.ChargeID, 1); err == nil {
+		t.Fatal("expected the charge to be fully refunded")
+	}
+
+	// Webhook sukses yang dikirim ulang setelah refund diabaikan
+	if err := f.webhook(t, payment.EventChargeSucceeded, paymentRes.ChargeID); err != nil {
+		t.Fatalf("duplicate webhook after refund returned an error: %v", err)
+	}
+}
+
+func TestSimulateMockPaymentChecksOrderOwner(t *testing.T) {
+	f := newPaymentFixture()
+	paymentRes := f.startPayment(t, 1, 10, 100000)
+
+	err := f.service.SimulateMockPayment(paymentRes.ChargeID, payment.EventChargeAuthorized, 11)
+	if !errors.Is(err, ErrNotOrderOwner) {
+		t.Fatalf("err = %v, want ErrNotOrderOwner", err)
+	}
+	if f.payments.payments[paymentRes.ID].Status != "Pending" {
+		t.Fatal("another user's simulation changed the payment")
+	}
+
+	if err := f.service.SimulateMockPayment(paymentRes.ChargeID, payment.EventChargeAuthorized, 10); err != nil {
+		t.Fatalf("owner simulation returned an error: %v", err)
+	}
+	if f.payments.payments[paymentRes.ID].Status != "Paid" {
+		t.Fatal("owner simulation did not pay the order")
+	}
+}
+
+func TestCheckPaymentTransition(t *testing.T) {
+	tests := []struct {
+		from, to string
+		allowed  bool
+	}{
+		{"Pending", "Paid", true},
+		{"Pending", "Failed", true},
+		{"Paid", "Refunded", true},
+		{"Paid", "Failed", false},
+		{"Failed", "Paid", false},
+		{"Refunded", "Paid", false},
+		{"Pending", "Refunded", false},
+	}
+
+	for _, tt := range tests {
+		err := checkPaymentTransition(tt.from, tt.to)
+		if tt.allowed && err != nil {
+			t.Errorf("%s -> %s: unexpected error %v", tt.from, tt.to, err)
+		}
+		if !tt.allowed && !errors.Is(err, ErrInvalidPaymentTransition) {
+			t.Errorf("%s -> %s: err = %v, want ErrInvalidPaymentTransition", tt.from, tt.to, err)
+		}
+	}
+}
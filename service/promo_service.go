@@ -0,0 +1,133 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"github.com/Ayyasy123/dibimbing-take-home-test/repository"
+)
+
+type PromoService interface {
+	CreatePromoCode(req *entity.CreatePromoCodeReq) (*entity.PromoCodeRes, error)
+	FindAllPromoCodes() ([]entity.PromoCodeRes, error)
+	ValidatePromoCode(req *entity.ValidatePromoCodeReq, userID int) (*entity.PromoPreviewRes, error)
+}
+
+type promoService struct {
+	promoRepository repository.PromoRepository
+	eventRepository repository.EventRepository
+}
+
+func NewPromoService(promoRepository repository.PromoRepository, eventRepository repository.EventRepository) PromoService {
+	return &promoService{promoRepository: promoRepository, eventRepository: eventRepository}
+}
+
+func (s *promoService) CreatePromoCode(req *entity.CreatePromoCodeReq) (*entity.PromoCodeRes, error) {
+	// Kode promo tidak membedakan huruf besar dan kecil
+	code := normalizePromoCode(req.Code)
+
+	exists, err := s.promoRepository.IsPromoCodeExists(code)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, errors.New("promo code already exists")
+	}
+
+	if req.DiscountType == "percentage" && req.DiscountValue > 100 {
+		return nil, errors.New("percentage discount cannot exceed 100")
+	}
+
+	// Promo khusus event hanya bisa dibuat untuk event yang ada
+	if req.EventID != 0 {
+		if _, err := s.eventRepository.FindEventByID(req.EventID); err != nil {
+			return nil, err
+		}
+	}
+
+	validFrom, err := parseSaleTime(req.ValidFrom)
+	if err != nil {
+		return nil, err
+	}
+
+	validUntil, err := parseSaleTime(req.ValidUntil)
+	if err != nil {
+		return nil, err
+	}
+
+	if validFrom != nil && validUntil != nil && !validUntil.After(*validFrom) {
+		return nil, errors.New("valid_until must be after valid_from")
+	}
+
+	promo := &entity.PromoCode{
+		Code:          code,
+		DiscountType:  req.DiscountType,
+		DiscountValue: req.DiscountValue,
+		EventID:       req.EventID,
+		UsageLimit:    req.UsageLimit,
+		PerUserLimit:  req.PerUserLimit,
+		ValidFrom:     validFrom,
+		ValidUntil:    validUntil,
+	}
+
+	err = s.promoRepository.CreatePromoCode(promo)
+	if err != nil {
+		return nil, err
+	}
+
+	promoRes := toPromoCodeRes(*promo)
+	return &promoRes, nil
+}
+
+func (s *promoService) FindAllPromoCodes() ([]entity.PromoCodeRes, error) {
+	promos, err := s.promoRepository.FindAllPromoCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	promoRes := []entity.PromoCodeRes{}
+	for _, promo := range promos {
+		promoRes = append(promoRes, toPromoCodeRes(promo))
+	}
+
+	return promoRes, nil
+}
+
+func (s *promoService) ValidatePromoCode(req *entity.ValidatePromoCodeReq, userID int) (*entity.PromoPreviewRes, error) {
+	quantity := req.Quantity
+	if quantity == 0 {
+		quantity = 1
+	}
+
+	return s.promoRepository.PreviewPromoCode(normalizePromoCode(req.Code), req.EventID, req.TierID, userID, quantity)
+}
+
+func normalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func toPromoCodeRes(promo entity.PromoCode) entity.PromoCodeRes {
+	promoRes := entity.PromoCodeRes{
+		ID:            promo.ID,
+		Code:          promo.Code,
+		DiscountType:  promo.DiscountType,
+		DiscountValue: promo.DiscountValue,
+		EventID:       promo.EventID,
+		UsageLimit:    promo.UsageLimit,
+		PerUserLimit:  promo.PerUserLimit,
+		UsedCount:     promo.UsedCount,
+		CreatedAt:     promo.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:     promo.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+
+	if promo.ValidFrom != nil {
+		promoRes.ValidFrom = promo.ValidFrom.Format("2006-01-02 15:04:05")
+	}
+
+	if promo.ValidUntil != nil {
+		promoRes.ValidUntil = promo.ValidUntil.Format("2006-01-02 15:04:05")
+	}
+
+	return promoRes
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"github.com/Ayyasy123/dibimbing-take-home-test/repository"
+)
+
+// Kebijakan yang dipakai jika event belum memiliki kebijakan refund sendiri:
+// 100% sampai 7 hari sebelum event, 50% setelahnya sampai event dimulai.
+var defaultRefundRules = []entity.RefundRule{
+	{DaysBeforeEvent: 7, Percentage: 100},
+	{DaysBeforeEvent: 0, Percentage: 50},
+}
+
+type RefundService interface {
+	FindAllRefunds() ([]entity.RefundRes, error)
+	UpdateRefund(id int, req *entity.UpdateRefundReq) error
+	FindRefundPolicy(eventID int) ([]entity.RefundRule, error)
+	SetRefundPolicy(eventID int, req *entity.SetRefundPolicyReq, userID int, role string) error
+}
+
+type refundService struct {
+	refundRepository repository.RefundRepository
+	eventRepository  repository.EventRepository
+}
+
+func NewRefundService(refundRepository repository.RefundRepository, eventRepository repository.EventRepository) RefundService {
+	return &refundService{refundRepository: refundRepository, eventRepository: eventRepository}
+}
+
+func (s *refundService) FindAllRefunds() ([]entity.RefundRes, error) {
+	refunds, err := s.refundRepository.FindAllRefunds()
+	if err != nil {
+		return nil, err
+	}
+
+	refundRes := []entity.RefundRes{}
+	for _, refund := range refunds {
+		refundRes = append(refundRes, toRefundRes(refund))
+	}
+
+	return refundRes, nil
+}
+
+func (s *refundService) UpdateRefund(id int, req *entity.UpdateRefundReq) error {
+	refund, err := s.refundRepository.FindRefundByID(id)
+	if err != nil {
+		return err
+	}
+
+	// Refund yang sudah selesai atau gagal tidak bisa diubah lagi
+	if refund.Status != "Pending" {
+		return errors.New("refund is no longer pending")
+	}
+
+	return s.refundRepository.UpdateRefundStatus(id, req.Status)
+}
+
+func (s *refundService) FindRefundPolicy(eventID int) ([]entity.RefundRule, error) {
+	rules, err := s.refundRepository.FindRefundRules(eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rules) == 0 {
+		return defaultRefundRules, nil
+	}
+
+	return rules, nil
+}
+
+func (s *refundService) SetRefundPolicy(eventID int, req *entity.SetRefundPolicyReq, userID int, role string) error {
+	event, err := s.eventRepository.FindEventByID(eventID)
+	if err != nil {
+		return err
+	}
+
+	if err := checkEventOwner(event.OrganizerID, userID, role); err != nil {
+		return err
+	}
+
+	seen := make(map[int]bool)
+	var rules []entity.RefundRule
+	for _, ruleReq := range req.Rules {
+		if seen[ruleReq.DaysBeforeEvent] {
+			return errors.New("days_before_event must be unique within a refund policy")
+		}
+		seen[ruleReq.DaysBeforeEvent] = true
+
+		rules = append(rules, entity.RefundRule{
+			EventID:         eventID,
+			DaysBeforeEvent: ruleReq.DaysBeforeEvent,
+			Percentage:      ruleReq.Percentage,
+		})
+	}
+
+	return s.refundRepository.ReplaceRefundRules(eventID, rules)
+}
+
+// refundPercentage mencari aturan dengan DaysBeforeEvent terbesar yang masih
+// terpenuhi. Jika event sudah dimulai atau tidak ada aturan yang cocok, tidak ada refund.
+func refundPercentage(rules []entity.RefundRule, eventDate, now time.Time) int {
+	if len(rules) == 0 {
+		rules = defaultRefundRules
+	}
+
+	if !now.Before(eventDate) {
+		return 0
+	}
+
+	daysBefore := int(math.Floor(eventDate.Sub(now).Hours() / 24))
+
+	percentage := 0
+	bestDays := -1
+	for _, rule := range rules {
+		if daysBefore >= rule.DaysBeforeEvent && rule.DaysBeforeEvent > bestDays {
+			bestDays = rule.DaysBeforeEvent
+			percentage = rule.Percentage
+		}
+	}
+
+	return percentage
+}
+
+func toRefundRes(refund entity.Refund) entity.RefundRes {
+	return entity.RefundRes{
+		ID:         refund.ID,
+		TicketID:   refund.TicketID,
+		OrderID:    refund.OrderID,
+		EventID:    refund.EventID,
+		UserID:     refund.UserID,
+		Amount:     refund.Amount,
+		Percentage: refund.Percentage,
+		Currency:   refund.Currency,
+		Reason:     refund.Reason,
+		Status:     refund.Status,
+		CreatedAt:  refund.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:  refund.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+)
+
+func TestRefundPercentage(t *testing.T) {
+	eventDate := time.Date(2025, 6, 30, 19, 0, 0, 0, time.UTC)
+	rules := []entity.RefundRule{
+		{DaysBeforeEvent: 14, Percentage: 100},
+		{DaysBeforeEvent: 3, Percentage: 75},
+		{DaysBeforeEvent: 1, Percentage: 25},
+	}
+
+	tests := []struct {
+		name  string
+		rules []entity.RefundRule
+		now   time.Time
+		want  int
+	}{
+		{"well before the event", rules, eventDate.AddDate(0, 0, -30), 100},
+		{"exactly on a rule boundary", rules, eventDate.AddDate(0, 0, -3), 75},
+		{"just under a rule boundary", rules, eventDate.AddDate(0, 0, -3).Add(time.Minute), 25},
+		{"on the event day", rules, eventDate.Add(-time.Hour), 0},
+		{"after the event started", rules, eventDate.Add(time.Minute), 0},
+		{"at the start time", rules, eventDate, 0},
+		{"default rules a week before", nil, eventDate.AddDate(0, 0, -7), 100},
+		{"default rules on the event day", nil, eventDate.Add(-time.Hour), 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := refundPercentage(tt.rules, eventDate, tt.now); got != tt.want {
+				t.Fatalf("refundPercentage = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
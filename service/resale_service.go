@@ -0,0 +1,195 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"github.com/Ayyasy123/dibimbing-take-home-test/repository"
+)
+
+// ErrResalePriceTooHigh dikembalikan ketika harga listing melebihi batas harga resale
+var ErrResalePriceTooHigh = errors.New("resale price exceeds the allowed maximum")
+
+type ResaleService interface {
+	CreateListing(userID int, req *entity.CreateListingReq) (*entity.ListingRes, error)
+	FindActiveListings(eventID int) ([]entity.ListingRes, error)
+	WithdrawListing(id, userID int) error
+	BuyListing(id, userID int) (*entity.OrderRes, error)
+	FindPayouts(userID int, role string) ([]entity.PayoutRes, error)
+	UpdatePayout(id int, req *entity.UpdatePayoutReq) error
+}
+
+type resaleService struct {
+	resaleRepository repository.ResaleRepository
+	ticketRepository repository.TicketRepository
+	paymentService   PaymentService
+	policy           entity.ResalePolicy
+}
+
+func NewResaleService(resaleRepository repository.ResaleRepository, ticketRepository repository.TicketRepository, paymentService PaymentService, policy entity.ResalePolicy) ResaleService {
+	return &resaleService{
+		resaleRepository: resaleRepository,
+		ticketRepository: ticketRepository,
+		paymentService:   paymentService,
+		policy:           policy,
+	}
+}
+
+func (s *resaleService) CreateListing(userID int, req *entity.CreateListingReq) (*entity.ListingRes, error) {
+	ticket, err := s.ticketRepository.FindTicketByID(req.TicketID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Hanya pemilik tiket yang boleh menjual tiketnya
+	if ticket.UserID != userID {
+		return nil, ErrNotTicketOwner
+	}
+
+	// Batas harga dihitung dari harga yang dibayar saat tiket pertama kali dibeli
+	maxPrice := (ticket.UnitPrice - ticket.Discount) * s.policy.MaxPricePercent / 100
+	if req.Price > maxPrice {
+		return nil, fmt.Errorf("%w: maximum price is %d", ErrResalePriceTooHigh, maxPrice)
+	}
+
+	fee := req.Price * s.policy.FeePercent / 100
+	listing := &entity.ResaleListing{
+		TicketID:     ticket.ID,
+		EventID:      ticket.EventID,
+		SellerID:     userID,
+		Price:        req.Price,
+		FeeAmount:    fee,
+		SellerPayout: req.Price - fee,
+		Currency:     ticket.Currency,
+		Status:       "Active",
+	}
+
+	err = s.resaleRepository.CreateListing(listing)
+	if err != nil {
+		return nil, err
+	}
+
+	listingRes := toListingRes(*listing)
+	return &listingRes, nil
+}
+
+func (s *resaleService) FindActiveListings(eventID int) ([]entity.ListingRes, error) {
+	listings, err := s.resaleRepository.FindActiveListings(eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	listingRes := []entity.ListingRes{}
+	for _, listing := range listings {
+		listingRes = append(listingRes, toListingRes(listing))
+	}
+
+	return listingRes, nil
+}
+
+func (s *resaleService) WithdrawListing(id, userID int) error {
+	listing, err := s.resaleRepository.FindListingByID(id)
+	if err != nil {
+		return err
+	}
+
+	// Hanya penjual yang boleh menarik listing-nya
+	if listing.SellerID != userID {
+		return ErrNotTicketOwner
+	}
+
+	return s.resaleRepository.WithdrawListing(id)
+}
+
+func (s *resaleService) BuyListing(id, userID int) (*entity.OrderRes, error) {
+	order := &entity.Order{
+		UserID: userID,
+		Status: entity.TicketStatusPendingPayment,
+	}
+
+	err := s.resaleRepository.ReserveListing(id, order)
+	if err != nil {
+		return nil, err
+	}
+
+	// Pembelian resale melewati alur pembayaran yang sama dengan pembelian tiket baru
+	paymentRes, err := s.paymentService.StartPayment(order)
+	if err != nil {
+		return nil, err
+	}
+
+	if paymentRes.Status == "Paid" {
+		order.Status = entity.TicketStatusPurchased
+	}
+
+	orderRes := toOrderRes(order)
+	orderRes.Payment = paymentRes
+	return orderRes, nil
+}
+
+// FindPayouts mengambil payout milik penjual. Admin melihat semua payout.
+func (s *resaleService) FindPayouts(userID int, role string) ([]entity.PayoutRes, error) {
+	sellerID := userID
+	if role == "admin" {
+		sellerID = 0
+	}
+
+	payouts, err := s.resaleRepository.FindPayouts(sellerID)
+	if err != nil {
+		return nil, err
+	}
+
+	payoutRes := []entity.PayoutRes{}
+	for _, payout := range payouts {
+		payoutRes = append(payoutRes, toPayoutRes(payout))
+	}
+
+	return payoutRes, nil
+}
+
+// UpdatePayout dipakai admin untuk mencatat bahwa dana penjual sudah ditransfer
+// atau dibatalkan, misalnya karena event-nya dibatalkan
+func (s *resaleService) UpdatePayout(id int, req *entity.UpdatePayoutReq) error {
+	return s.resaleRepository.UpdatePayoutStatus(id, req.Status)
+}
+
+func toListingRes(listing entity.ResaleListing) entity.ListingRes {
+	listingRes := entity.ListingRes{
+		ID:           listing.ID,
+		TicketID:     listing.TicketID,
+		EventID:      listing.EventID,
+		SellerID:     listing.SellerID,
+		Price:        listing.Price,
+		FeeAmount:    listing.FeeAmount,
+		SellerPayout: listing.SellerPayout,
+		Currency:     listing.Currency,
+		Status:       listing.Status,
+		CreatedAt:    listing.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+
+	if listing.SoldAt != nil {
+		listingRes.SoldAt = listing.SoldAt.Format("2006-01-02 15:04:05")
+	}
+
+	return listingRes
+}
+
+func toPayoutRes(payout entity.ResalePayout) entity.PayoutRes {
+	payoutRes := entity.PayoutRes{
+		ID:        payout.ID,
+		ListingID: payout.ListingID,
+		SellerID:  payout.SellerID,
+		OrderID:   payout.OrderID,
+		Amount:    payout.Amount,
+		Currency:  payout.Currency,
+		Status:    payout.Status,
+		CreatedAt: payout.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+
+	if payout.PaidAt != nil {
+		payoutRes.PaidAt = payout.PaidAt.Format("2006-01-02 15:04:05")
+	}
+
+	return payoutRes
+}
@@ -0,0 +1,285 @@
+package service
+
+import (
+	"time"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"github.com/Ayyasy123/dibimbing-take-home-test/repository"
+	"github.com/Ayyasy123/dibimbing-take-home-test/utils"
+)
+
+// Series tanpa COUNT atau UNTIL hanya dibuatkan kemunculan sampai sekian hari ke
+// depan, sisanya dibuat bertahap oleh scheduler
+const seriesHorizonDays = 90
+
+type SeriesService interface {
+	CreateSeries(req *entity.CreateSeriesReq, userID int, role string) (*entity.SeriesRes, error)
+	FindSeriesByID(id int) (*entity.SeriesRes, error)
+	UpdateSeries(id int, req *entity.UpdateSeriesReq, userID int, role string) (*entity.SeriesRes, error)
+	GenerateOccurrences(id int, userID int, role string) (int, error)
+	GenerateAllOccurrences() (int, error)
+	GetSeriesReport(id int, userID int, role string) (*entity.SeriesReport, error)
+}
+
+type seriesService struct {
+	seriesRepository repository.SeriesRepository
+	userRepository   repository.UserRepository
+}
+
+func NewSeriesService(seriesRepository repository.SeriesRepository, userRepository repository.UserRepository) SeriesService {
+	return &seriesService{seriesRepository: seriesRepository, userRepository: userRepository}
+}
+
+func (s *seriesService) CreateSeries(req *entity.CreateSeriesReq, userID int, role string) (*entity.SeriesRes, error) {
+	// Validasi aturan pengulangan sebelum series disimpan
+	if _, err := utils.ParseRRule(req.RRule); err != nil {
+		return nil, err
+	}
+
+	organizerID, err := resolveOrganizerID(s.userRepository, req.OrganizerID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	loc, err := loadEventLocation(req.TimeZone)
+	if err != nil {
+		return nil, err
+	}
+
+	startDate, endDate, err := parseEventTimes(req.StartDate, req.EndDate, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	series := &entity.EventSeries{
+		Name:              req.Name,
+		Description:       req.Description,
+		Location:          req.Location,
+		Category:          req.Category,
+		Capacity:          req.Capacity,
+		Price:             req.Price,
+		MaxTicketsPerUser: req.MaxTicketsPerUser,
+		RRule:             req.RRule,
+		StartDate:         startDate,
+		EndDate:           endDate,
+		TimeZone:          loc.String(),
+		OrganizerID:       organizerID,
+	}
+
+	if err := s.seriesRepository.CreateSeries(series); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.generateOccurrences(series, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return s.FindSeriesByID(series.ID)
+}
+
+func (s *seriesService) FindSeriesByID(id int) (*entity.SeriesRes, error) {
+	series, err := s.seriesRepository.FindSeriesByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return toSeriesRes(series), nil
+}
+
+func (s *seriesService) UpdateSeries(id int, req *entity.UpdateSeriesReq, userID int, role string) (*entity.SeriesRes, error) {
+	series, err := s.seriesRepository.FindSeriesByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkEventOwner(series.OrganizerID, userID, role); err != nil {
+		return nil, err
+	}
+
+	if req.Name != "" {
+		series.Name = req.Name
+	}
+
+	if req.Description != "" {
+		series.Description = req.Description
+	}
+
+	if req.Location != "" {
+		series.Location = req.Location
+	}
+
+	if req.Category != "" {
+		series.Category = req.Category
+	}
+
+	if req.Price != 0 {
+		series.Price = req.Price
+	}
+
+	if req.MaxTicketsPerUser != 0 {
+		series.MaxTicketsPerUser = req.MaxTicketsPerUser
+	}
+
+	// Kapasitas diterapkan ke setiap kemunculan sebagai selisih dari kapasitas lama
+	capacityDelta := 0
+	if req.Capacity != 0 {
+		capacityDelta = req.Capacity - series.Capacity
+		series.Capacity = req.Capacity
+	}
+
+	if _, err := s.seriesRepository.UpdateSeries(series, capacityDelta, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return s.FindSeriesByID(id)
+}
+
+func (s *seriesService) GenerateOccurrences(id int, userID int, role string) (int, error) {
+	series, err := s.seriesRepository.FindSeriesByID(id)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := checkEventOwner(series.OrganizerID, userID, role); err != nil {
+		return 0, err
+	}
+
+	return s.generateOccurrences(series, time.Now())
+}
+
+// GenerateAllOccurrences memperpanjang kemunculan semua series, dipanggil oleh scheduler
+func (s *seriesService) GenerateAllOccurrences() (int, error) {
+	allSeries, err := s.seriesRepository.FindAllSeries()
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for i := range allSeries {
+		created, err := s.generateOccurrences(&allSeries[i], time.Now())
+		if err != nil {
+			return total, err
+		}
+		total += created
+	}
+
+	return total, nil
+}
+
+// generateOccurrences membuat Event untuk setiap kemunculan yang belum dimulai dan
+// belum pernah dibuat, sehingga aman dipanggil berulang kali
+func (s *seriesService) generateOccurrences(series *entity.EventSeries, now time.Time) (int, error) {
+	rule, err := utils.ParseRRule(series.RRule)
+	if err != nil {
+		return 0, err
+	}
+
+	horizon := now.AddDate(0, 0, seriesHorizonDays)
+	if rule.IsBounded() {
+		// Series yang memiliki akhir langsung dibuatkan semua kemunculannya
+		horizon = series.StartDate.AddDate(100, 0, 0)
+	}
+
+	existingDates, err := s.seriesRepository.FindOccurrenceDates(series.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	existing := make(map[int64]bool)
+	for _, date := range existingDates {
+		existing[date.Unix()] = true
+	}
+
+	loc, err := loadEventLocation(series.TimeZone)
+	if err != nil {
+		return 0, err
+	}
+
+	duration := series.EndDate.Sub(series.StartDate)
+
+	// Pengulangan dihitung pada zona waktu series agar jam mulai tetap sama
+	// walaupun ada perubahan daylight saving time
+	var events []entity.Event
+	for _, date := range rule.Occurrences(series.StartDate.In(loc), horizon) {
+		if !date.After(now) || existing[date.Unix()] {
+			continue
+		}
+
+		events = append(events, entity.Event{
+			Name:               series.Name,
+			Description:        series.Description,
+			Location:           series.Location,
+			Date:               date,
+			EndDate:            date.Add(duration),
+			TimeZone:           series.TimeZone,
+			Category:           series.Category,
+			Capacity:           series.Capacity,
+			Price:              series.Price,
+			Status:             entity.EventStatusActive,
+			AvailableTickets:   series.Capacity,
+			TicketAvailability: entity.TicketAvailabilityAvailable,
+			MaxTicketsPerUser:  series.MaxTicketsPerUser,
+			SeriesID:           series.ID,
+			OrganizerID:        series.OrganizerID,
+		})
+	}
+
+	return len(events), s.seriesRepository.CreateOccurrences(events)
+}
+
+func (s *seriesService) GetSeriesReport(id int, userID int, role string) (*entity.SeriesReport, error) {
+	series, err := s.seriesRepository.FindSeriesByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkEventOwner(series.OrganizerID, userID, role); err != nil {
+		return nil, err
+	}
+
+	sales, err := s.seriesRepository.GetSeriesSales(id)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &entity.SeriesReport{
+		SeriesID:         series.ID,
+		SeriesName:       series.Name,
+		TotalOccurrences: len(sales),
+		Occurrences:      sales,
+	}
+
+	for _, occurrence := range sales {
+		report.TotalTickets += occurrence.TotalTickets
+		report.TotalRevenue += occurrence.TotalRevenue
+	}
+
+	return report, nil
+}
+
+func toSeriesRes(series *entity.EventSeries) *entity.SeriesRes {
+	seriesRes := &entity.SeriesRes{
+		ID:                series.ID,
+		Name:              series.Name,
+		Description:       series.Description,
+		Location:          series.Location,
+		Category:          series.Category,
+		Capacity:          series.Capacity,
+		Price:             series.Price,
+		MaxTicketsPerUser: series.MaxTicketsPerUser,
+		RRule:             series.RRule,
+		StartDate:         formatEventTime(series.StartDate, series.TimeZone),
+		EndDate:           formatEventTime(series.EndDate, series.TimeZone),
+		TimeZone:          series.TimeZone,
+		OrganizerID:       series.OrganizerID,
+		Occurrences:       []entity.EventRes{},
+		CreatedAt:         series.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:         series.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+
+	for _, event := range series.Events {
+		seriesRes.Occurrences = append(seriesRes.Occurrences, toEventRes(event))
+	}
+
+	return seriesRes
+}
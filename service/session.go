@@ -0,0 +1,140 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"github.com/Ayyasy123/dibimbing-take-home-test/utils"
+)
+
+// Masa berlaku refresh token, sesi berakhir jika tidak di-refresh selama ini
+const refreshTokenTTL = 30 * 24 * time.Hour
+
+// RefreshSession menukar refresh token dengan access token dan refresh token baru.
+// Role dibaca ulang dari database sehingga perubahan role langsung berlaku.
+func (s *userService) RefreshSession(refreshToken string) (*entity.TokenRes, error) {
+	newRefreshToken, err := generateSecureToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	next := &entity.RefreshToken{
+		TokenHash: hashToken(newRefreshToken),
+		ExpiresAt: now.Add(refreshTokenTTL),
+	}
+
+	current, err := s.tokenRepository.RotateRefreshToken(hashToken(refreshToken), next, now)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepository.FindUserByID(current.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	token, expiresAt, err := utils.GenerateJWT(user.ID, user.Role, current.MFA)
+	if err != nil {
+		return nil, errors.New("failed to generate token")
+	}
+
+	return &entity.TokenRes{
+		Token:        token,
+		RefreshToken: newRefreshToken,
+		ExpiresAt:    expiresAt,
+	}, nil
+}
+
+// Logout memasukkan jti access token ke denylist sampai token itu kedaluwarsa, lalu
+// mencabut refresh token sesi ini atau semua sesi milik user
+func (s *userService) Logout(userID int, jti string, tokenExpiresAt time.Time, req *entity.LogoutReq) error {
+	if err := s.tokenRepository.RevokeAccessToken(&entity.RevokedToken{
+		JTI:       jti,
+		UserID:    userID,
+		ExpiresAt: tokenExpiresAt,
+	}); err != nil {
+		return err
+	}
+
+	if req.AllSessions {
+		return s.tokenRepository.RevokeUserRefreshTokens(userID)
+	}
+
+	if req.RefreshToken != "" {
+		return s.tokenRepository.RevokeRefreshTokenFamily(hashToken(req.RefreshToken), userID)
+	}
+
+	return nil
+}
+
+// issueSession membuat access token dan refresh token untuk login yang sudah lolos
+// semua pemeriksaan. mfa bernilai true jika login memakai kode 2FA.
+func (s *userService) issueSession(user *entity.User, mfa bool) (*entity.UserRes, error) {
+	token, _, err := utils.GenerateJWT(user.ID, user.Role, mfa)
+	if err != nil {
+		return nil, errors.New("failed to generate token")
+	}
+
+	// Setiap login memulai keluarga refresh token baru
+	refreshToken, err := s.issueRefreshToken(user.ID, mfa)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.UserRes{
+		ID:                     user.ID,
+		Name:                   user.Name,
+		Email:                  user.Email,
+		Role:                   user.Role,
+		EmailVerifiedAt:        user.EmailVerifiedAt,
+		CreatedAt:              user.CreatedAt,
+		UpdatedAt:              user.UpdatedAt,
+		Token:                  token,
+		RefreshToken:           refreshToken,
+		TwoFactorEnabled:       user.TOTPEnabledAt != nil,
+		TwoFactorSetupRequired: user.TOTPEnabledAt == nil && s.twoFactorRequired(user.Role),
+	}, nil
+}
+
+// issueRefreshToken memulai sesi baru dengan keluarga refresh token baru. Hanya
+// hash yang disimpan, token aslinya dikembalikan ke client.
+func (s *userService) issueRefreshToken(userID int, mfa bool) (string, error) {
+	token, err := generateSecureToken()
+	if err != nil {
+		return "", err
+	}
+
+	familyID, err := generateSecureToken()
+	if err != nil {
+		return "", err
+	}
+
+	err = s.tokenRepository.CreateRefreshToken(&entity.RefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(refreshTokenTTL),
+		MFA:       mfa,
+	})
+	return token, err
+}
+
+// hashToken dipakai untuk token acak yang disimpan di database, cukup SHA-256
+// karena tokennya sendiri sudah 256 bit acak
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func generateSecureToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,26 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+)
+
+// ErrInvalidStatusTransition dikembalikan ketika perubahan status tidak ada di
+// tabel transisi, misalnya event Selesai yang diubah kembali menjadi Aktif
+var ErrInvalidStatusTransition = errors.New("invalid status transition")
+
+func checkEventTransition(from, to entity.EventStatus) error {
+	if !from.CanTransitionTo(to) {
+		return fmt.Errorf("%w: event cannot move from '%s' to '%s'", ErrInvalidStatusTransition, from, to)
+	}
+	return nil
+}
+
+func checkTicketTransition(from, to entity.TicketStatus) error {
+	if !from.CanTransitionTo(to) {
+		return fmt.Errorf("%w: ticket cannot move from '%s' to '%s'", ErrInvalidStatusTransition, from, to)
+	}
+	return nil
+}
@@ -1,73 +1,194 @@
 package service
 
 import (
+	"crypto/subtle"
 	"errors"
+	"fmt"
+	"log"
 	"time"
 
 	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
 	"github.com/Ayyasy123/dibimbing-take-home-test/repository"
+	"github.com/Ayyasy123/dibimbing-take-home-test/utils"
+	"github.com/skip2/go-qrcode"
 )
 
 type TicketService interface {
-	CreateTicket(req *entity.CreateTicketReq) (*entity.TicketRes, error)
+	CreateTicket(req *entity.CreateTicketReq, userID int) (*entity.OrderRes, error)
+	FindOrderByID(id, userID int, role string) (*entity.OrderRes, error)
+	CreateHold(req *entity.CreateHoldReq, userID int) (*entity.HoldRes, error)
+	ConfirmHold(id, userID int) (*entity.OrderRes, error)
 	FindTicketByID(id int) (*entity.TicketRes, error)
 	FindAllTickets() ([]entity.TicketRes, error)
 	UpdateTicket(id int, req *entity.UpdateTicketReq) error
 	DeleteTicket(id int) error
 	FindAllTicketsByUserID(userID int) ([]entity.TicketRes, error)
-	GetTicketReport(startDate, endDate time.Time) (*entity.TicketReport, error)
-	GetTicketsSoldPerEvent(startDate, endDate time.Time, eventID int) ([]entity.TicketsSoldPerEvent, error)
-	CancelTicket(id int) error
+	GetTicketReport(startDate, endDate time.Time, userID int, role string) (*entity.TicketReport, error)
+	GetTicketsSoldPerEvent(startDate, endDate time.Time, eventID int, userID int, role string) ([]entity.TicketsSoldPerEvent, error)
+	CancelTicket(id, userID int, role string, req *entity.CancelTicketReq) (*entity.RefundRes, error)
+	GenerateTicketQR(id, userID int, role string) ([]byte, error)
+	CheckIn(req *entity.CheckInReq, staffID int) (*entity.CheckInRes, error)
 }
 
+// ErrInvalidTicketStatus dikembalikan ketika status yang dikirim bukan status tiket yang dikenal
+var ErrInvalidTicketStatus = errors.New("invalid ticket status")
+
+// ErrTicketNotValidForEntry dikembalikan ketika tiket yang dipindai tidak berstatus Dibeli
+var ErrTicketNotValidForEntry = errors.New("ticket is not valid for entry")
+
+// Lama default sebuah hold sebelum stoknya dilepas kembali oleh sweeper
+const defaultHoldMinutes = 10
+
 type ticketService struct {
-	ticketRepository repository.TicketRepository
+	ticketRepository   repository.TicketRepository
+	orderRepository    repository.OrderRepository
+	holdRepository     repository.HoldRepository
+	waitlistRepository repository.WaitlistRepository
+	refundRepository   repository.RefundRepository
+	eventRepository    repository.EventRepository
+	paymentService     PaymentService
 }
 
-func NewTicketService(ticketRepository repository.TicketRepository) TicketService {
-	return &ticketService{ticketRepository: ticketRepository}
+func NewTicketService(ticketRepository repository.TicketRepository, orderRepository repository.OrderRepository, holdRepository repository.HoldRepository, waitlistRepository repository.WaitlistRepository, refundRepository repository.RefundRepository, eventRepository repository.EventRepository, paymentService PaymentService) TicketService {
+	return &ticketService{
+		ticketRepository:   ticketRepository,
+		orderRepository:    orderRepository,
+		holdRepository:     holdRepository,
+		waitlistRepository: waitlistRepository,
+		refundRepository:   refundRepository,
+		eventRepository:    eventRepository,
+		paymentService:     paymentService,
+	}
 }
 
-func (s *ticketService) CreateTicket(req *entity.CreateTicketReq) (*entity.TicketRes, error) {
-	ticket := &entity.Ticket{
-		EventID: req.EventID,
-		UserID:  req.UserID,
-		Status:  "Dibeli",
+func (s *ticketService) CreateTicket(req *entity.CreateTicketReq, userID int) (*entity.OrderRes, error) {
+	quantity, err := resolveQuantity(req.Quantity, req.SeatIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkOrderQuantity(req.EventID, quantity); err != nil {
+		return nil, err
+	}
+
+	order := newOrder(req.EventID, req.TierID, userID, quantity)
+	for i, seatID := range req.SeatIDs {
+		order.Tickets[i].SeatID = seatID
 	}
 
-	err := s.ticketRepository.CreateTicket(ticket)
+	err = s.orderRepository.CreateOrder(order, normalizePromoCode(req.PromoCode))
 	if err != nil {
 		return nil, err
 	}
 
-	ticketRes := &entity.TicketRes{
-		ID:        ticket.ID,
-		EventID:   ticket.EventID,
-		UserID:    ticket.UserID,
-		Status:    ticket.Status,
-		CreatedAt: ticket.CreatedAt.Format("2006-01-02 15:04:05"),
-		UpdatedAt: ticket.UpdatedAt.Format("2006-01-02 15:04:05"),
+	return s.checkout(order)
+}
+
+func (s *ticketService) CreateHold(req *entity.CreateHoldReq, userID int) (*entity.HoldRes, error) {
+	quantity, err := resolveQuantity(req.Quantity, req.SeatIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkOrderQuantity(req.EventID, quantity); err != nil {
+		return nil, err
 	}
 
-	return ticketRes, err
+	minutes := req.Minutes
+	if minutes == 0 {
+		minutes = defaultHoldMinutes
+	}
+
+	hold := &entity.TicketHold{
+		EventID:   req.EventID,
+		TierID:    req.TierID,
+		UserID:    userID,
+		Quantity:  quantity,
+		Status:    "Reserved",
+		ExpiresAt: time.Now().Add(time.Duration(minutes) * time.Minute),
+	}
+
+	err = s.holdRepository.CreateHold(hold, req.SeatIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	holdRes := toHoldRes(hold)
+	holdRes.SeatIDs = req.SeatIDs
+	return holdRes, nil
 }
 
-func (s *ticketService) FindTicketByID(id int) (*entity.TicketRes, error) {
-	ticket, err := s.ticketRepository.FindTicketByID(id)
+func (s *ticketService) ConfirmHold(id, userID int) (*entity.OrderRes, error) {
+	hold, err := s.holdRepository.FindHoldByID(id)
 	if err != nil {
 		return nil, err
 	}
 
-	ticketRes := &entity.TicketRes{
-		ID:        ticket.ID,
-		EventID:   ticket.EventID,
-		UserID:    ticket.UserID,
-		Status:    ticket.Status,
-		CreatedAt: ticket.CreatedAt.Format("2006-01-02 15:04:05"),
-		UpdatedAt: ticket.UpdatedAt.Format("2006-01-02 15:04:05"),
+	// Hanya user yang membuat hold yang boleh mengonfirmasinya
+	if hold.UserID != userID {
+		return nil, errors.New("hold does not belong to this user")
 	}
 
-	return ticketRes, nil
+	order := newOrder(hold.EventID, hold.TierID, hold.UserID, hold.Quantity)
+
+	err = s.holdRepository.ConfirmHold(hold.ID, order)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.checkout(order)
+}
+
+func (s *ticketService) FindOrderByID(id, userID int, role string) (*entity.OrderRes, error) {
+	order, err := s.orderRepository.FindOrderByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Order hanya bisa dilihat oleh pembelinya atau admin
+	if role != "admin" && order.UserID != userID {
+		return nil, ErrNotOrderOwner
+	}
+
+	orderRes := toOrderRes(order)
+
+	// Order lama yang dibuat sebelum ada pembayaran tidak memiliki data payment
+	paymentRes, err := s.paymentService.FindPaymentByOrderID(order.ID)
+	if err == nil {
+		orderRes.Payment = paymentRes
+	}
+
+	return orderRes, nil
+}
+
+// checkout memulai pembayaran untuk order yang baru dibuat. Tiket baru berstatus
+// "Dibeli" setelah pembayaran berhasil, kecuali untuk order gratis.
+func (s *ticketService) checkout(order *entity.Order) (*entity.OrderRes, error) {
+	paymentRes, err := s.paymentService.StartPayment(order)
+	if err != nil {
+		return nil, err
+	}
+
+	if paymentRes.Status == "Paid" {
+		order.Status = entity.TicketStatusPurchased
+		for i := range order.Tickets {
+			order.Tickets[i].Status = entity.TicketStatusPurchased
+		}
+	}
+
+	orderRes := toOrderRes(order)
+	orderRes.Payment = paymentRes
+	return orderRes, nil
+}
+
+func (s *ticketService) FindTicketByID(id int) (*entity.TicketRes, error) {
+	ticket, err := s.ticketRepository.FindTicketByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	ticketRes := toTicketRes(*ticket)
+	return &ticketRes, nil
 }
 
 func (s *ticketService) FindAllTickets() ([]entity.TicketRes, error) {
@@ -78,14 +199,7 @@ func (s *ticketService) FindAllTickets() ([]entity.TicketRes, error) {
 
 	var ticketRes []entity.TicketRes
 	for _, ticket := range tickets {
-		ticketRes = append(ticketRes, entity.TicketRes{
-			ID:        ticket.ID,
-			EventID:   ticket.EventID,
-			UserID:    ticket.UserID,
-			Status:    ticket.Status,
-			CreatedAt: ticket.CreatedAt.Format("2006-01-02 15:04:05"),
-			UpdatedAt: ticket.UpdatedAt.Format("2006-01-02 15:04:05"),
-		})
+		ticketRes = append(ticketRes, toTicketRes(ticket))
 	}
 
 	return ticketRes, nil
@@ -97,8 +211,21 @@ func (s *ticketService) UpdateTicket(id int, req *entity.UpdateTicketReq) error
 		return err
 	}
 
-	if req.Status != "" && req.Status != existingTicket.Status {
-		existingTicket.Status = req.Status
+	status := entity.TicketStatus(req.Status)
+	if !status.IsValid() {
+		return ErrInvalidTicketStatus
+	}
+
+	// Pembatalan harus mengembalikan stok dan membuat refund, jadi tidak lewat update biasa
+	if status == entity.TicketStatusCancelled && existingTicket.Status != status {
+		return errors.New("use PATCH /tickets/:id/cancel to cancel a ticket")
+	}
+
+	if status != existingTicket.Status {
+		if err := checkTicketTransition(existingTicket.Status, status); err != nil {
+			return err
+		}
+		existingTicket.Status = status
 	}
 
 	return s.ticketRepository.UpdateTicket(id, existingTicket)
@@ -116,75 +243,334 @@ func (s *ticketService) FindAllTicketsByUserID(userID int) ([]entity.TicketRes,
 
 	var ticketRes []entity.TicketRes
 	for _, ticket := range tickets {
-		ticketRes = append(ticketRes, entity.TicketRes{
-			ID:        ticket.ID,
-			EventID:   ticket.EventID,
-			UserID:    ticket.UserID,
-			Status:    ticket.Status,
-			CreatedAt: ticket.CreatedAt.Format("2006-01-02 15:04:05"),
-			UpdatedAt: ticket.UpdatedAt.Format("2006-01-02 15:04:05"),
-		})
+		ticketRes = append(ticketRes, toTicketRes(ticket))
 	}
 
 	return ticketRes, nil
 }
 
-func (s *ticketService) GetTicketReport(startDate, endDate time.Time) (*entity.TicketReport, error) {
+func (s *ticketService) GetTicketReport(startDate, endDate time.Time, userID int, role string) (*entity.TicketReport, error) {
+	// Organizer hanya melihat penjualan event miliknya
+	organizerID := reportOrganizerID(userID, role)
+
 	// Hitung total tiket yang terjual
-	totalTickets, err := s.ticketRepository.GetTotalTickets(startDate, endDate)
+	totalTickets, err := s.ticketRepository.GetTotalTickets(startDate, endDate, organizerID)
 	if err != nil {
 		return nil, err
 	}
 
 	// Hitung total pendapatan dari tiket yang terjual
-	totalRevenue, err := s.ticketRepository.GetTotalRevenue(startDate, endDate)
+	totalRevenue, err := s.ticketRepository.GetTotalRevenue(startDate, endDate, organizerID)
 	if err != nil {
 		return nil, err
 	}
 
 	// Daftar status tiket yang ingin dihitung
-	statuses := []string{"Dibeli", "Dibatalkan"}
+	statuses := []entity.TicketStatus{entity.TicketStatusPurchased, entity.TicketStatusCancelled}
 
 	// Slice untuk menyimpan distribusi status tiket
 	var statusDistribution []entity.TicketStatusDistribution
 
 	// Loop melalui setiap status dan hitung distribusinya
 	for _, status := range statuses {
-		totalTicketsByStatus, totalRevenueByStatus, err := s.ticketRepository.GetTicketStatusDistribution(status, startDate, endDate)
+		totalTicketsByStatus, totalRevenueByStatus, err := s.ticketRepository.GetTicketStatusDistribution(status, startDate, endDate, organizerID)
 		if err != nil {
 			return nil, err
 		}
 
 		statusDistribution = append(statusDistribution, entity.TicketStatusDistribution{
-			Status:       status,
+			Status:       string(status),
 			TotalTickets: totalTicketsByStatus,
 			TotalRevenue: totalRevenueByStatus,
 		})
 	}
 
+	// Hitung total refund yang tidak gagal
+	totalRefunds, totalRefunded, err := s.ticketRepository.GetTotalRefunds(startDate, endDate, organizerID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Hitung pemakaian setiap promo code
+	promoRedemptions, err := s.ticketRepository.GetPromoRedemptions(startDate, endDate, organizerID)
+	if err != nil {
+		return nil, err
+	}
+
 	return &entity.TicketReport{
 		TotalTickets:             int(totalTickets),
 		TotalRevenue:             totalRevenue,
+		TotalRefunds:             totalRefunds,
+		TotalRefunded:            totalRefunded,
+		PromoRedemptions:         promoRedemptions,
 		TicketStatusDistribution: statusDistribution,
 	}, nil
 }
 
-func (s *ticketService) GetTicketsSoldPerEvent(startDate, endDate time.Time, eventID int) ([]entity.TicketsSoldPerEvent, error) {
-	return s.ticketRepository.GetTicketsSoldPerEvent(startDate, endDate, eventID)
+func (s *ticketService) GetTicketsSoldPerEvent(startDate, endDate time.Time, eventID int, userID int, role string) ([]entity.TicketsSoldPerEvent, error) {
+	return s.ticketRepository.GetTicketsSoldPerEvent(startDate, endDate, eventID, reportOrganizerID(userID, role))
 }
 
-func (s *ticketService) CancelTicket(id int) error {
+func (s *ticketService) CancelTicket(id, userID int, role string, req *entity.CancelTicketReq) (*entity.RefundRes, error) {
 	// Cek apakah tiket ada
 	ticket, err := s.ticketRepository.FindTicketByID(id)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	// Hanya pemilik tiket atau admin yang boleh membatalkan tiket
+	if role != "admin" && ticket.UserID != userID {
+		return nil, ErrNotTicketOwner
+	}
+
+	// Tiket yang sudah dipakai masuk tidak bisa dibatalkan dan di-refund
+	if ticket.CheckedInAt != nil {
+		return nil, repository.ErrTicketAlreadyCheckedIn
+	}
+
+	// Refund dikembalikan ke pembayaran order tiket, jadi hanya pembeli order tersebut
+	// yang boleh membatalkan. Tiket hasil transfer atau resale tidak bisa di-refund.
+	if ticket.OrderID != 0 {
+		order, err := s.orderRepository.FindOrderByID(ticket.OrderID)
+		if err != nil {
+			return nil, err
+		}
+		if order.UserID != ticket.UserID {
+			return nil, repository.ErrTicketNotRefundable
+		}
 	}
 
 	// Validasi: Tiket hanya bisa dibatalkan jika statusnya "Dibeli"
-	if ticket.Status != "Dibeli" {
-		return errors.New("ticket cannot be cancelled because it is not in 'Dibeli' status")
+	if err := checkTicketTransition(ticket.Status, entity.TicketStatusCancelled); err != nil {
+		return nil, err
+	}
+
+	// Hitung jumlah refund berdasarkan kebijakan refund event
+	rules, err := s.refundRepository.FindRefundRules(ticket.EventID)
+	if err != nil {
+		return nil, err
+	}
+
+	percentage := refundPercentage(rules, ticket.Event.Date, time.Now())
+	refund := &entity.Refund{
+		TicketID:   ticket.ID,
+		OrderID:    ticket.OrderID,
+		EventID:    ticket.EventID,
+		UserID:     ticket.UserID,
+		Amount:     (ticket.UnitPrice - ticket.Discount) * percentage / 100,
+		Percentage: percentage,
+		Currency:   ticket.Currency,
+		Reason:     req.Reason,
+		Status:     "Pending",
+	}
+
+	// Update status tiket menjadi "cancelled", kembalikan stoknya dan catat refund
+	err = s.ticketRepository.CancelTicket(id, refund)
+	if err != nil {
+		return nil, err
 	}
 
-	// Update status tiket menjadi "cancelled"
-	return s.ticketRepository.UpdateTicketStatus(id, "Dibatalkan")
+	// Kembalikan dana melalui penyedia pembayaran. Jika gagal, refund tetap
+	// berstatus Pending dan bisa diselesaikan admin secara manual.
+	if refund.OrderID != 0 {
+		if err := s.paymentService.RefundPayment(refund.OrderID, refund); err != nil {
+			log.Printf("Failed to process refund %d: %v", refund.ID, err)
+		}
+	}
+
+	// Tawarkan stok yang kembali kepada user di waitlist. Pembatalan sudah
+	// tersimpan, jadi kegagalan di sini cukup dicatat dan akan diulang oleh sweeper.
+	if _, err := s.waitlistRepository.OfferAvailableTickets(ticket.EventID); err != nil {
+		log.Printf("Failed to process waitlist for event %d: %v", ticket.EventID, err)
+	}
+
+	refundRes := toRefundRes(*refund)
+	return &refundRes, nil
+}
+
+// resolveQuantity menentukan jumlah tiket dari request. Jika kursi dipilih,
+// jumlah tiket mengikuti jumlah kursi.
+func resolveQuantity(quantity int, seatIDs []int) (int, error) {
+	if len(seatIDs) == 0 {
+		if quantity == 0 {
+			return 1, nil
+		}
+		return quantity, nil
+	}
+
+	if quantity != 0 && quantity != len(seatIDs) {
+		return 0, errors.New("quantity must match the number of selected seats")
+	}
+
+	return len(seatIDs), nil
+}
+
+// checkOrderQuantity menolak jumlah tiket yang jelas tidak mungkin dipenuhi sebelum
+// tiket order disiapkan. Stok dan batas per user tetap dicek ulang di repository
+// di bawah lock event.
+func (s *ticketService) checkOrderQuantity(eventID, quantity int) error {
+	event, err := s.eventRepository.FindEventByID(eventID)
+	if err != nil {
+		return err
+	}
+
+	if event.MaxTicketsPerUser > 0 && quantity > event.MaxTicketsPerUser {
+		return errors.New("ticket purchase limit per user exceeded for this event")
+	}
+
+	if event.AvailableTickets <= 0 {
+		return repository.ErrNoAvailableTickets
+	}
+
+	if event.AvailableTickets < quantity {
+		return errors.New("not enough available tickets for this event")
+	}
+
+	return nil
+}
+
+func newOrder(eventID, tierID, userID, quantity int) *entity.Order {
+	order := &entity.Order{
+		EventID:  eventID,
+		TierID:   tierID,
+		UserID:   userID,
+		Quantity: quantity,
+		Status:   entity.TicketStatusPendingPayment,
+	}
+
+	for i := 0; i < quantity; i++ {
+		order.Tickets = append(order.Tickets, entity.Ticket{
+			EventID: eventID,
+			TierID:  tierID,
+			UserID:  userID,
+			Status:  entity.TicketStatusPendingPayment,
+		})
+	}
+
+	return order
+}
+
+func (s *ticketService) GenerateTicketQR(id, userID int, role string) ([]byte, error) {
+	ticket, err := s.ticketRepository.FindTicketByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// QR hanya bisa diambil oleh pemilik tiket atau admin
+	if ticket.UserID != userID && role != "admin" {
+		return nil, ErrNotTicketOwner
+	}
+
+	if ticket.Status != entity.TicketStatusPurchased {
+		return nil, ErrTicketNotValidForEntry
+	}
+
+	return qrcode.Encode(ticket.Code, qrcode.Medium, 256)
+}
+
+func (s *ticketService) CheckIn(req *entity.CheckInReq, staffID int) (*entity.CheckInRes, error) {
+	// Signature diverifikasi dulu agar kode palsu ditolak tanpa query ke database
+	ticketID, err := utils.ParseTicketCode(req.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	ticket, err := s.ticketRepository.FindTicketByID(ticketID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Kode lama yang sudah diganti tidak boleh dipakai lagi
+	if subtle.ConstantTimeCompare([]byte(ticket.Code), []byte(req.Code)) != 1 {
+		return nil, utils.ErrInvalidTicketCode
+	}
+
+	if ticket.Status != entity.TicketStatusPurchased {
+		return nil, fmt.Errorf("%w: ticket status is %s", ErrTicketNotValidForEntry, ticket.Status)
+	}
+
+	if ticket.CheckedInAt != nil {
+		return nil, fmt.Errorf("%w at %s (gate %s)", repository.ErrTicketAlreadyCheckedIn,
+			ticket.CheckedInAt.Format("2006-01-02 15:04:05"), ticket.CheckInGate)
+	}
+
+	now := time.Now()
+	err = s.ticketRepository.CheckInTicket(ticket.ID, req.Gate, staffID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.CheckInRes{
+		TicketID:    ticket.ID,
+		EventID:     ticket.EventID,
+		EventName:   ticket.Event.Name,
+		SeatID:      ticket.SeatID,
+		Gate:        req.Gate,
+		CheckedInAt: now.Format("2006-01-02 15:04:05"),
+	}, nil
+}
+
+func toTicketRes(ticket entity.Ticket) entity.TicketRes {
+	ticketRes := entity.TicketRes{
+		ID:          ticket.ID,
+		OrderID:     ticket.OrderID,
+		EventID:     ticket.EventID,
+		TierID:      ticket.TierID,
+		SeatID:      ticket.SeatID,
+		UserID:      ticket.UserID,
+		Status:      string(ticket.Status),
+		UnitPrice:   ticket.UnitPrice,
+		Discount:    ticket.Discount,
+		Currency:    ticket.Currency,
+		CheckInGate: ticket.CheckInGate,
+		CreatedAt:   ticket.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:   ticket.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+
+	if ticket.CheckedInAt != nil {
+		ticketRes.CheckedInAt = ticket.CheckedInAt.Format("2006-01-02 15:04:05")
+	}
+
+	return ticketRes
+}
+
+func toOrderRes(order *entity.Order) *entity.OrderRes {
+	tickets := []entity.TicketRes{}
+	for _, ticket := range order.Tickets {
+		tickets = append(tickets, toTicketRes(ticket))
+	}
+
+	return &entity.OrderRes{
+		ID:              order.ID,
+		UserID:          order.UserID,
+		EventID:         order.EventID,
+		TierID:          order.TierID,
+		Quantity:        order.Quantity,
+		Status:          string(order.Status),
+		TotalPrice:      order.TotalPrice,
+		Discount:        order.Discount,
+		PromoCodeID:     order.PromoCodeID,
+		ResaleListingID: order.ResaleListingID,
+		Currency:        order.Currency,
+		Tickets:         tickets,
+		CreatedAt:       order.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:       order.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+func toHoldRes(hold *entity.TicketHold) *entity.HoldRes {
+	return &entity.HoldRes{
+		ID:        hold.ID,
+		EventID:   hold.EventID,
+		TierID:    hold.TierID,
+		UserID:    hold.UserID,
+		OrderID:   hold.OrderID,
+		Quantity:  hold.Quantity,
+		UnitPrice: hold.UnitPrice,
+		Currency:  hold.Currency,
+		Status:    hold.Status,
+		ExpiresAt: hold.ExpiresAt.Format("2006-01-02 15:04:05"),
+		CreatedAt: hold.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt: hold.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
 }
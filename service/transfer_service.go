@@ -0,0 +1,226 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"github.com/Ayyasy123/dibimbing-take-home-test/repository"
+)
+
+var (
+	// ErrNotTicketOwner dikembalikan ketika user mencoba mengelola tiket milik orang lain
+	ErrNotTicketOwner = errors.New("ticket does not belong to this user")
+	// ErrNotTransferRecipient dikembalikan ketika transfer tidak ditujukan ke email user
+	ErrNotTransferRecipient = errors.New("ticket transfer is not addressed to this user")
+)
+
+type TransferService interface {
+	CreateTransfer(ticketID, userID int, req *entity.CreateTransferReq) (*entity.TransferRes, error)
+	AcceptTransfer(id, userID int) error
+	DeclineTransfer(id, userID int) error
+	CancelTransfer(id, userID int) error
+	FindIncomingTransfers(userID int) ([]entity.TransferRes, error)
+	FindTransferHistory(ticketID, userID int, role string) ([]entity.TransferRes, error)
+}
+
+type transferService struct {
+	transferRepository repository.TransferRepository
+	ticketRepository   repository.TicketRepository
+	userRepository     repository.UserRepository
+}
+
+func NewTransferService(transferRepository repository.TransferRepository, ticketRepository repository.TicketRepository, userRepository repository.UserRepository) TransferService {
+	return &transferService{
+		transferRepository: transferRepository,
+		ticketRepository:   ticketRepository,
+		userRepository:     userRepository,
+	}
+}
+
+func (s *transferService) CreateTransfer(ticketID, userID int, req *entity.CreateTransferReq) (*entity.TransferRes, error) {
+	ticket, err := s.ticketRepository.FindTicketByID(ticketID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Hanya pemilik tiket yang boleh mentransfer tiketnya
+	if ticket.UserID != userID {
+		return nil, ErrNotTicketOwner
+	}
+
+	if ticket.Status != entity.TicketStatusPurchased {
+		return nil, errors.New("only purchased tickets can be transferred")
+	}
+
+	if ticket.CheckedInAt != nil {
+		return nil, errors.New("ticket has already been used")
+	}
+
+	owner, err := s.userRepository.FindUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if strings.EqualFold(owner.Email, email) {
+		return nil, errors.New("cannot transfer a ticket to yourself")
+	}
+
+	transfer := &entity.TicketTransfer{
+		TicketID:   ticket.ID,
+		FromUserID: userID,
+		ToEmail:    email,
+		Status:     "Pending",
+	}
+
+	err = s.transferRepository.CreateTransfer(transfer)
+	if err != nil {
+		return nil, err
+	}
+
+	transferRes := toTransferRes(*transfer)
+	return &transferRes, nil
+}
+
+func (s *transferService) AcceptTransfer(id, userID int) error {
+	if _, err := s.findTransferForRecipient(id, userID); err != nil {
+		return err
+	}
+
+	return s.transferRepository.AcceptTransfer(id, userID)
+}
+
+func (s *transferService) DeclineTransfer(id, userID int) error {
+	if _, err := s.findTransferForRecipient(id, userID); err != nil {
+		return err
+	}
+
+	return s.transferRepository.CloseTransfer(id, "Declined")
+}
+
+func (s *transferService) CancelTransfer(id, userID int) error {
+	transfer, err := s.transferRepository.FindTransferByID(id)
+	if err != nil {
+		return err
+	}
+
+	// Hanya pengirim yang boleh membatalkan transfer
+	if transfer.FromUserID != userID {
+		return ErrNotTicketOwner
+	}
+
+	return s.transferRepository.CloseTransfer(id, "Cancelled")
+}
+
+func (s *transferService) FindIncomingTransfers(userID int) ([]entity.TransferRes, error) {
+	user, err := s.findVerifiedRecipient(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	transfers, err := s.transferRepository.FindPendingTransfersByEmail(strings.ToLower(user.Email))
+	if err != nil {
+		return nil, err
+	}
+
+	transferRes := []entity.TransferRes{}
+	for _, transfer := range transfers {
+		transferRes = append(transferRes, toTransferRes(transfer))
+	}
+
+	return transferRes, nil
+}
+
+func (s *transferService) FindTransferHistory(ticketID, userID int, role string) ([]entity.TransferRes, error) {
+	ticket, err := s.ticketRepository.FindTicketByID(ticketID)
+	if err != nil {
+		return nil, err
+	}
+
+	transfers, err := s.transferRepository.FindTransfersByTicketID(ticketID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Riwayat bisa dilihat admin, pemilik saat ini dan user yang pernah memiliki tiket
+	allowed := role == "admin" || ticket.UserID == userID
+	for _, transfer := range transfers {
+		if transfer.FromUserID == userID {
+			allowed = true
+		}
+	}
+	if !allowed {
+		return nil, ErrNotTicketOwner
+	}
+
+	transferRes := []entity.TransferRes{}
+	for _, transfer := range transfers {
+		transferRes = append(transferRes, toTransferRes(transfer))
+	}
+
+	return transferRes, nil
+}
+
+// findTransferForRecipient memastikan transfer ditujukan ke email user yang sedang login
+func (s *transferService) findTransferForRecipient(id, userID int) (*entity.TicketTransfer, error) {
+	transfer, err := s.transferRepository.FindTransferByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.findVerifiedRecipient(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.EqualFold(transfer.ToEmail, user.Email) {
+		return nil, ErrNotTransferRecipient
+	}
+
+	return transfer, nil
+}
+
+// findVerifiedRecipient mengambil user penerima transfer. Transfer dicocokkan lewat
+// email, jadi email harus sudah diverifikasi dan hanya dimiliki satu akun agar
+// tiket tidak bisa diambil oleh akun yang sekadar mendaftar dengan email penerima.
+func (s *transferService) findVerifiedRecipient(userID int) (*entity.User, error) {
+	user, err := s.userRepository.FindUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.EmailVerifiedAt == nil {
+		return nil, fmt.Errorf("%w: verify your email address to receive ticket transfers", ErrNotTransferRecipient)
+	}
+
+	count, err := s.userRepository.CountUsersByEmail(user.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	if count != 1 {
+		return nil, fmt.Errorf("%w: email address is used by more than one account", ErrNotTransferRecipient)
+	}
+
+	return user, nil
+}
+
+func toTransferRes(transfer entity.TicketTransfer) entity.TransferRes {
+	transferRes := entity.TransferRes{
+		ID:         transfer.ID,
+		TicketID:   transfer.TicketID,
+		FromUserID: transfer.FromUserID,
+		ToEmail:    transfer.ToEmail,
+		ToUserID:   transfer.ToUserID,
+		Status:     transfer.Status,
+		CreatedAt:  transfer.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+
+	if transfer.RespondedAt != nil {
+		transferRes.RespondedAt = transfer.RespondedAt.Format("2006-01-02 15:04:05")
+	}
+
+	return transferRes
+}
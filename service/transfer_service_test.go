@@ -0,0 +1,36 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+)
+
+func TestFindVerifiedRecipient(t *testing.T) {
+	verifiedAt := time.Now()
+	userRepo := &fakeUserRepository{
+		users: map[int]*entity.User{
+			1: {ID: 1, Email: "recipient@example.com", EmailVerifiedAt: &verifiedAt},
+			2: {ID: 2, Email: "unverified@example.com"},
+			3: {ID: 3, Email: "shared@example.com", EmailVerifiedAt: &verifiedAt},
+		},
+		emailCounts: map[string]int64{"shared@example.com": 2},
+	}
+	s := &transferService{userRepository: userRepo}
+
+	if _, err := s.findVerifiedRecipient(1); err != nil {
+		t.Fatalf("verified recipient rejected: %v", err)
+	}
+
+	// Akun yang mendaftar dengan email penerima tetapi belum memverifikasinya
+	if _, err := s.findVerifiedRecipient(2); !errors.Is(err, ErrNotTransferRecipient) {
+		t.Fatalf("unverified email err = %v, want ErrNotTransferRecipient", err)
+	}
+
+	// Email yang dipakai lebih dari satu akun tidak bisa menerima transfer
+	if _, err := s.findVerifiedRecipient(3); !errors.Is(err, ErrNotTransferRecipient) {
+		t.Fatalf("shared email err = %v, want ErrNotTransferRecipient", err)
+	}
+}
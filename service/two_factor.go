@@ -0,0 +1,242 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"github.com/Ayyasy123/dibimbing-take-home-test/utils"
+)
+
+const (
+	// Waktu yang diberikan untuk memasukkan kode authenticator setelah password benar
+	loginChallengeTTL = 5 * time.Minute
+	recoveryCodeCount = 10
+)
+
+var (
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorNotSetup       = errors.New("start two-factor setup first")
+	ErrTwoFactorRequired       = errors.New("two-factor authentication is required for this role")
+	ErrInvalidTwoFactorCode    = errors.New("invalid two-factor code")
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// SetupTwoFactor membuat secret baru yang belum aktif sampai dikonfirmasi dengan kode
+// dari aplikasi authenticator. Setup boleh diulang selama 2FA belum aktif.
+func (s *userService) SetupTwoFactor(userID int) (*entity.TwoFactorSetupRes, error) {
+	user, err := s.userRepository.FindUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TOTPEnabledAt != nil {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	saved, err := s.userRepository.SetTOTPSecret(user.ID, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	if !saved {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	return &entity.TwoFactorSetupRes{
+		Secret:     secret,
+		OTPAuthURI: utils.TOTPURI(s.authPolicy.TOTPIssuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmTwoFactor mengaktifkan 2FA jika kode cocok dengan secret dari setup, lalu
+// mengembalikan recovery code yang hanya ditampilkan sekali ini
+func (s *userService) ConfirmTwoFactor(userID int, req *entity.TwoFactorCodeReq) (*entity.RecoveryCodesRes, error) {
+	user, err := s.userRepository.FindUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TOTPEnabledAt != nil {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	if user.TOTPSecret == "" {
+		return nil, ErrTwoFactorNotSetup
+	}
+
+	now := time.Now()
+	step, ok := utils.ValidateTOTP(user.TOTPSecret, req.Code, now)
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	enabled, err := s.userRepository.EnableTOTP(user.ID, step, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if !enabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	return s.replaceRecoveryCodes(user.ID)
+}
+
+// VerifyLoginChallenge adalah langkah kedua login. Challenge token langsung hangus
+// setelah dipakai, jadi kode yang salah mengharuskan user login ulang dengan password.
+func (s *userService) VerifyLoginChallenge(req *entity.TwoFactorVerifyReq) (*entity.UserRes, error) {
+	challenge, err := s.tokenRepository.ConsumeAccountToken(hashToken(req.ChallengeToken), entity.AccountTokenLoginChallenge, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepository.FindUserByID(challenge.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.verifyTwoFactorCode(user, req.Code); err != nil {
+		return nil, err
+	}
+
+	return s.issueSession(user, true)
+}
+
+func (s *userService) DisableTwoFactor(userID int, req *entity.TwoFactorCodeReq) error {
+	user, err := s.userRepository.FindUserByID(userID)
+	if err != nil {
+		return err
+	}
+
+	if s.twoFactorRequired(user.Role) {
+		return ErrTwoFactorRequired
+	}
+
+	if err := s.verifyTwoFactorCode(user, req.Code); err != nil {
+		return err
+	}
+
+	if err := s.userRepository.DisableTOTP(user.ID); err != nil {
+		return err
+	}
+
+	return s.recoveryCodeRepository.DeleteRecoveryCodes(user.ID)
+}
+
+// RegenerateRecoveryCodes mengganti semua recovery code, kode lama tidak berlaku lagi
+func (s *userService) RegenerateRecoveryCodes(userID int, req *entity.TwoFactorCodeReq) (*entity.RecoveryCodesRes, error) {
+	user, err := s.userRepository.FindUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.verifyTwoFactorCode(user, req.Code); err != nil {
+		return nil, err
+	}
+
+	return s.replaceRecoveryCodes(user.ID)
+}
+
+func (s *userService) GetTwoFactorStatus(userID int) (*entity.TwoFactorStatusRes, error) {
+	user, err := s.userRepository.FindUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	remaining, err := s.recoveryCodeRepository.CountUnusedRecoveryCodes(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.TwoFactorStatusRes{
+		Enabled:                user.TOTPEnabledAt != nil,
+		EnabledAt:              user.TOTPEnabledAt,
+		RecoveryCodesRemaining: int(remaining),
+		Required:               s.twoFactorRequired(user.Role),
+	}, nil
+}
+
+// verifyTwoFactorCode menerima kode authenticator 6 digit atau recovery code. Keduanya
+// hanya bisa dipakai sekali.
+func (s *userService) verifyTwoFactorCode(user *entity.User, code string) error {
+	if user.TOTPEnabledAt == nil {
+		return ErrTwoFactorNotEnabled
+	}
+
+	if step, ok := utils.ValidateTOTP(user.TOTPSecret, code, time.Now()); ok {
+		used, err := s.userRepository.UseTOTPStep(user.ID, step)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return ErrInvalidTwoFactorCode
+		}
+		return nil
+	}
+
+	used, err := s.recoveryCodeRepository.UseRecoveryCode(user.ID, hashToken(normalizeRecoveryCode(code)), time.Now())
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrInvalidTwoFactorCode
+	}
+
+	return nil
+}
+
+func (s *userService) replaceRecoveryCodes(userID int) (*entity.RecoveryCodesRes, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	records := make([]entity.RecoveryCode, 0, recoveryCodeCount)
+
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+
+		codes = append(codes, code)
+		records = append(records, entity.RecoveryCode{
+			UserID:   userID,
+			CodeHash: hashToken(normalizeRecoveryCode(code)),
+		})
+	}
+
+	if err := s.recoveryCodeRepository.ReplaceRecoveryCodes(userID, records); err != nil {
+		return nil, err
+	}
+
+	return &entity.RecoveryCodesRes{RecoveryCodes: codes}, nil
+}
+
+func (s *userService) twoFactorRequired(role string) bool {
+	return s.authPolicy.RequireAdminTwoFactor && role == "admin"
+}
+
+// generateRecoveryCode membuat kode 50 bit dengan format xxxxx-xxxxx
+func generateRecoveryCode() (string, error) {
+	b := make([]byte, 7)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	code := strings.ToLower(recoveryCodeEncoding.EncodeToString(b))[:10]
+	return code[:5] + "-" + code[5:], nil
+}
+
+// normalizeRecoveryCode membuat kode yang diketik dengan huruf besar, spasi atau
+// tanpa tanda hubung tetap cocok
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
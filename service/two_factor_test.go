@@ -0,0 +1,121 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"github.com/Ayyasy123/dibimbing-take-home-test/repository"
+	"github.com/Ayyasy123/dibimbing-take-home-test/utils"
+)
+
+// fakeUserRepository hanya mengimplementasikan method yang dipakai test. Method lain
+// dari interface yang tertanam akan panic jika terpanggil.
+type fakeUserRepository struct {
+	repository.UserRepository
+	users       map[int]*entity.User
+	emailCounts map[string]int64
+}
+
+func (r *fakeUserRepository) FindUserByID(id int) (*entity.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, errors.New("record not found")
+	}
+	found := *user
+	return &found, nil
+}
+
+func (r *fakeUserRepository) CountUsersByEmail(email string) (int64, error) {
+	if count, ok := r.emailCounts[email]; ok {
+		return count, nil
+	}
+	return 1, nil
+}
+
+// UseTOTPStep meniru update bersyarat totp_last_step < step pada repository asli
+func (r *fakeUserRepository) UseTOTPStep(id int, step int64) (bool, error) {
+	user := r.users[id]
+	if user.TOTPLastStep >= step {
+		return false, nil
+	}
+	user.TOTPLastStep = step
+	return true, nil
+}
+
+type fakeRecoveryCodeRepository struct {
+	repository.RecoveryCodeRepository
+	unused map[string]bool // Hash recovery code yang belum dipakai
+}
+
+func (r *fakeRecoveryCodeRepository) UseRecoveryCode(userID int, codeHash string, now time.Time) (bool, error) {
+	if !r.unused[codeHash] {
+		return false, nil
+	}
+	r.unused[codeHash] = false
+	return true, nil
+}
+
+func TestVerifyTwoFactorCode(t *testing.T) {
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	enabledAt := time.Now()
+	user := &entity.User{ID: 1, TOTPSecret: secret, TOTPEnabledAt: &enabledAt}
+	userRepo := &fakeUserRepository{users: map[int]*entity.User{1: user}}
+	recoveryRepo := &fakeRecoveryCodeRepository{unused: map[string]bool{
+		hashToken(normalizeRecoveryCode("abcde-fghij")): true,
+	}}
+	s := &userService{userRepository: userRepo, recoveryCodeRepository: recoveryRepo}
+
+	code, err := utils.TOTPCode(secret, utils.TOTPStep(time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.verifyTwoFactorCode(user, code); err != nil {
+		t.Fatalf("valid authenticator code rejected: %v", err)
+	}
+
+	// Kode yang sama tidak bisa dipakai dua kali dalam periode yang sama
+	if err := s.verifyTwoFactorCode(user, code); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Fatalf("replayed code err = %v, want ErrInvalidTwoFactorCode", err)
+	}
+
+	// Recovery code boleh diketik dengan huruf besar dan tanpa tanda hubung, tetapi hanya sekali
+	if err := s.verifyTwoFactorCode(user, "ABCDE FGHIJ"); err != nil {
+		t.Fatalf("valid recovery code rejected: %v", err)
+	}
+	if err := s.verifyTwoFactorCode(user, "abcde-fghij"); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Fatalf("reused recovery code err = %v, want ErrInvalidTwoFactorCode", err)
+	}
+
+	if err := s.verifyTwoFactorCode(user, "000000x"); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Fatalf("invalid code err = %v, want ErrInvalidTwoFactorCode", err)
+	}
+
+	disabled := &entity.User{ID: 2}
+	if err := s.verifyTwoFactorCode(disabled, code); !errors.Is(err, ErrTwoFactorNotEnabled) {
+		t.Fatalf("user without 2FA err = %v, want ErrTwoFactorNotEnabled", err)
+	}
+}
+
+func TestGenerateRecoveryCode(t *testing.T) {
+	seen := map[string]bool{}
+	for i := 0; i < 20; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			t.Fatalf("generateRecoveryCode returned an error: %v", err)
+		}
+		if len(code) != 11 || code[5] != '-' {
+			t.Fatalf("recovery code %q is not in the xxxxx-xxxxx format", code)
+		}
+		if seen[code] {
+			t.Fatalf("recovery code %q was generated twice", code)
+		}
+		seen[code] = true
+	}
+}
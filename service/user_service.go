@@ -2,11 +2,12 @@ package service
 
 import (
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"github.com/Ayyasy123/dibimbing-take-home-test/mail"
 	"github.com/Ayyasy123/dibimbing-take-home-test/repository"
-	"github.com/Ayyasy123/dibimbing-take-home-test/utils"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -15,18 +16,45 @@ type UserService interface {
 	LoginUser(req *entity.LoginReq) (*entity.UserRes, error)
 	FindUserByID(id int) (*entity.UserRes, error)
 	FindAllUsers() ([]entity.UserRes, error)
-	UpdateUser(id int, req *entity.UpdateUserReq) error
+	UpdateUser(id int, req *entity.UpdateUserReq, userID int, role string) error
 	DeleteUser(id int) error
-	RegisterAsAdmin(req *entity.RegisterReq) (*entity.UserRes, error)
 	GetUserReport(startDate, endDate time.Time) (*entity.UserReport, error)
+	RefreshSession(refreshToken string) (*entity.TokenRes, error)
+	Logout(userID int, jti string, tokenExpiresAt time.Time, req *entity.LogoutReq) error
+	ForgotPassword(req *entity.ForgotPasswordReq) error
+	ResetPassword(req *entity.ResetPasswordReq) error
+	VerifyEmail(token string) error
+	ResendVerificationEmail(userID int) error
+	SetupTwoFactor(userID int) (*entity.TwoFactorSetupRes, error)
+	ConfirmTwoFactor(userID int, req *entity.TwoFactorCodeReq) (*entity.RecoveryCodesRes, error)
+	VerifyLoginChallenge(req *entity.TwoFactorVerifyReq) (*entity.UserRes, error)
+	DisableTwoFactor(userID int, req *entity.TwoFactorCodeReq) error
+	RegenerateRecoveryCodes(userID int, req *entity.TwoFactorCodeReq) (*entity.RecoveryCodesRes, error)
+	GetTwoFactorStatus(userID int) (*entity.TwoFactorStatusRes, error)
 }
 
+var (
+	ErrNotAccountOwner      = errors.New("you can only update your own account")
+	ErrRoleChangeNotAllowed = errors.New("role cannot be changed here, use PUT /admin/users/:id/role")
+	ErrEmailAlreadyExists   = errors.New("email already registered")
+)
+
 type userService struct {
-	userRepository repository.UserRepository
+	userRepository         repository.UserRepository
+	tokenRepository        repository.TokenRepository
+	recoveryCodeRepository repository.RecoveryCodeRepository
+	mailer                 mail.Sender
+	authPolicy             entity.AuthPolicy
 }
 
-func NewUserService(userRepository repository.UserRepository) UserService {
-	return &userService{userRepository: userRepository}
+func NewUserService(userRepository repository.UserRepository, tokenRepository repository.TokenRepository, recoveryCodeRepository repository.RecoveryCodeRepository, mailer mail.Sender, authPolicy entity.AuthPolicy) UserService {
+	return &userService{
+		userRepository:         userRepository,
+		tokenRepository:        tokenRepository,
+		recoveryCodeRepository: recoveryCodeRepository,
+		mailer:                 mailer,
+		authPolicy:             authPolicy,
+	}
 }
 
 func (s *userService) RegisterUser(req *entity.RegisterReq) (*entity.UserRes, error) {
@@ -37,7 +65,7 @@ func (s *userService) RegisterUser(req *entity.RegisterReq) (*entity.UserRes, er
 	}
 
 	if exists {
-		return nil, errors.New("email already registered")
+		return nil, ErrEmailAlreadyExists
 	}
 
 	// hash password
@@ -58,13 +86,16 @@ func (s *userService) RegisterUser(req *entity.RegisterReq) (*entity.UserRes, er
 		return nil, err
 	}
 
+	s.notifyVerification(user)
+
 	userRes := &entity.UserRes{
-		ID:        user.ID,
-		Name:      user.Name,
-		Email:     user.Email,
-		Role:      user.Role,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
+		ID:              user.ID,
+		Name:            user.Name,
+		Email:           user.Email,
+		Role:            user.Role,
+		EmailVerifiedAt: user.EmailVerifiedAt,
+		CreatedAt:       user.CreatedAt,
+		UpdatedAt:       user.UpdatedAt,
 	}
 
 	return userRes, nil
@@ -81,23 +112,28 @@ func (s *userService) LoginUser(req *entity.LoginReq) (*entity.UserRes, error) {
 		return nil, err
 	}
 
-	// Generate token JWT
-	token, err := utils.GenerateJWT(user.ID, user.Role)
-	if err != nil {
-		return nil, errors.New("failed to generate token")
-	}
+	// Dengan 2FA aktif, password saja belum cukup. Client menukar challenge token dan
+	// kode authenticator di POST /auth/2fa/verify untuk mendapatkan token.
+	if user.TOTPEnabledAt != nil {
+		challengeToken, err := s.issueAccountToken(user, entity.AccountTokenLoginChallenge, loginChallengeTTL)
+		if err != nil {
+			return nil, err
+		}
 
-	userRes := &entity.UserRes{
-		ID:        user.ID,
-		Name:      user.Name,
-		Email:     user.Email,
-		Role:      user.Role,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
-		Token:     token, // Tambahkan field Token ke UserRes
+		return &entity.UserRes{
+			ID:               user.ID,
+			Name:             user.Name,
+			Email:            user.Email,
+			Role:             user.Role,
+			EmailVerifiedAt:  user.EmailVerifiedAt,
+			CreatedAt:        user.CreatedAt,
+			UpdatedAt:        user.UpdatedAt,
+			TwoFactorEnabled: true,
+			ChallengeToken:   challengeToken,
+		}, nil
 	}
 
-	return userRes, nil
+	return s.issueSession(user, false)
 }
 
 func (s *userService) FindUserByID(id int) (*entity.UserRes, error) {
@@ -107,12 +143,13 @@ func (s *userService) FindUserByID(id int) (*entity.UserRes, error) {
 	}
 
 	userRes := &entity.UserRes{
-		ID:        user.ID,
-		Name:      user.Name,
-		Email:     user.Email,
-		Role:      user.Role,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
+		ID:              user.ID,
+		Name:            user.Name,
+		Email:           user.Email,
+		Role:            user.Role,
+		EmailVerifiedAt: user.EmailVerifiedAt,
+		CreatedAt:       user.CreatedAt,
+		UpdatedAt:       user.UpdatedAt,
 	}
 
 	return userRes, nil
@@ -127,28 +164,52 @@ func (s *userService) FindAllUsers() ([]entity.UserRes, error) {
 	var userRes []entity.UserRes
 	for _, user := range users {
 		userRes = append(userRes, entity.UserRes{
-			ID:        user.ID,
-			Name:      user.Name,
-			Email:     user.Email,
-			Role:      user.Role,
-			CreatedAt: user.CreatedAt,
-			UpdatedAt: user.UpdatedAt,
+			ID:              user.ID,
+			Name:            user.Name,
+			Email:           user.Email,
+			Role:            user.Role,
+			EmailVerifiedAt: user.EmailVerifiedAt,
+			CreatedAt:       user.CreatedAt,
+			UpdatedAt:       user.UpdatedAt,
 		})
 	}
 
 	return userRes, nil
 }
 
-func (s *userService) UpdateUser(id int, req *entity.UpdateUserReq) error {
+// UpdateUser mengubah profil user. Selain admin, user hanya boleh mengubah akunnya
+// sendiri. Role tidak bisa diubah di sini karena setiap pemberian role harus tercatat
+// lewat AdminService.UpdateUserRole.
+func (s *userService) UpdateUser(id int, req *entity.UpdateUserReq, userID int, role string) error {
+	if role != "admin" && id != userID {
+		return ErrNotAccountOwner
+	}
+
 	existingUser, err := s.userRepository.FindUserByID(id)
 	if err != nil {
 		return err
 	}
 
+	if req.Role != "" && req.Role != existingUser.Role {
+		return ErrRoleChangeNotAllowed
+	}
+
 	if req.Name != "" {
 		existingUser.Name = req.Name
 	}
 
+	// Email baru harus diverifikasi ulang dan belum dipakai akun lain
+	emailChanged := req.Email != "" && !strings.EqualFold(req.Email, existingUser.Email)
+	if emailChanged {
+		exists, err := s.userRepository.IsEmailExists(req.Email)
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			return ErrEmailAlreadyExists
+		}
+	}
 	if req.Email != "" {
 		existingUser.Email = req.Email
 	}
@@ -161,56 +222,31 @@ func (s *userService) UpdateUser(id int, req *entity.UpdateUserReq) error {
 		existingUser.Password = string(hashedPassword)
 	}
 
-	if req.Role != "" {
-		existingUser.Role = req.Role
-	}
-
-	return s.userRepository.UpdateUser(id, existingUser)
-
-}
-
-func (s *userService) DeleteUser(id int) error {
-	return s.userRepository.DeleteUser(id)
-}
-
-func (s *userService) RegisterAsAdmin(req *entity.RegisterReq) (*entity.UserRes, error) {
-	exist, err := s.userRepository.IsEmailExists(req.Email)
-	if err != nil {
-		return nil, err
-	}
-
-	if exist {
-		return nil, errors.New("email already registered")
-	}
-
-	// hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	err = s.userRepository.UpdateUser(id, existingUser)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	user := &entity.User{
-		Name:     req.Name,
-		Email:    req.Email,
-		Password: string(hashedPassword),
-		Role:     "admin",
+	// Sama seperti reset password, sesi yang sudah ada harus login ulang dengan password baru
+	if req.Password != "" {
+		if err := s.tokenRepository.RevokeUserRefreshTokens(id); err != nil {
+			return err
+		}
 	}
 
-	err = s.userRepository.CreateUser(user)
-	if err != nil {
-		return nil, err
+	if emailChanged {
+		if err := s.userRepository.ResetEmailVerification(id); err != nil {
+			return err
+		}
+		existingUser.EmailVerifiedAt = nil
+		s.notifyVerification(existingUser)
 	}
 
-	userRes := &entity.UserRes{
-		ID:        user.ID,
-		Name:      user.Name,
-		Email:     user.Email,
-		Role:      user.Role,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
-	}
+	return nil
+}
 
-	return userRes, nil
+func (s *userService) DeleteUser(id int) error {
+	return s.userRepository.DeleteUser(id)
 }
 
 func (s *userService) GetUserReport(startDate, endDate time.Time) (*entity.UserReport, error) {
@@ -221,7 +257,7 @@ func (s *userService) GetUserReport(startDate, endDate time.Time) (*entity.UserR
 	}
 
 	// Daftar role user yang ingin dihitung
-	roles := []string{"admin", "user"}
+	roles := []string{"admin", "organizer", "staff", "user"}
 
 	// Slice untuk menyimpan distribusi role user
 	var roleDistribution []entity.UserRoleDistribution
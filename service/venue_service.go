@@ -0,0 +1,50 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"github.com/Ayyasy123/dibimbing-take-home-test/repository"
+)
+
+type VenueService interface {
+	CreateVenue(req *entity.CreateVenueReq) (*entity.Venue, error)
+	FindVenueByID(id int) (*entity.Venue, error)
+}
+
+type venueService struct {
+	venueRepository repository.VenueRepository
+}
+
+func NewVenueService(venueRepository repository.VenueRepository) VenueService {
+	return &venueService{venueRepository: venueRepository}
+}
+
+func (s *venueService) CreateVenue(req *entity.CreateVenueReq) (*entity.Venue, error) {
+	venue := &entity.Venue{
+		Name:    req.Name,
+		Address: req.Address,
+	}
+
+	// Buat kursi untuk setiap baris di setiap section, label kursi = nama baris + nomor
+	for _, sectionReq := range req.Sections {
+		section := entity.Section{Name: sectionReq.Name}
+		for _, row := range sectionReq.Rows {
+			for number := 1; number <= sectionReq.SeatsPerRow; number++ {
+				section.Seats = append(section.Seats, entity.Seat{
+					Row:    row,
+					Number: number,
+					Label:  fmt.Sprintf("%s%d", row, number),
+				})
+			}
+		}
+		venue.Sections = append(venue.Sections, section)
+	}
+
+	err := s.venueRepository.CreateVenue(venue)
+	return venue, err
+}
+
+func (s *venueService) FindVenueByID(id int) (*entity.Venue, error) {
+	return s.venueRepository.FindVenueByID(id)
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Ayyasy123/dibimbing-take-home-test/entity"
+	"github.com/Ayyasy123/dibimbing-take-home-test/repository"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidWaitlistTier dikembalikan ketika tier_id tidak bisa dipakai untuk event tersebut
+var ErrInvalidWaitlistTier = errors.New("invalid tier_id for this event")
+
+type WaitlistService interface {
+	JoinWaitlist(eventID, userID int, req *entity.JoinWaitlistReq) (*entity.WaitlistRes, error)
+	FindMyWaitlistEntry(eventID, userID int) (*entity.WaitlistRes, error)
+}
+
+type waitlistService struct {
+	waitlistRepository repository.WaitlistRepository
+	eventRepository    repository.EventRepository
+	tierRepository     repository.TierRepository
+}
+
+func NewWaitlistService(waitlistRepository repository.WaitlistRepository, eventRepository repository.EventRepository, tierRepository repository.TierRepository) WaitlistService {
+	return &waitlistService{waitlistRepository: waitlistRepository, eventRepository: eventRepository, tierRepository: tierRepository}
+}
+
+func (s *waitlistService) JoinWaitlist(eventID, userID int, req *entity.JoinWaitlistReq) (*entity.WaitlistRes, error) {
+	event, err := s.eventRepository.FindEventByID(eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Event yang sudah dimulai, selesai atau dibatalkan tidak membuka waitlist
+	if event.Status != entity.EventStatusActive {
+		return nil, repository.ErrEventNotOnSale
+	}
+
+	// Waitlist hanya untuk event yang sudah habis
+	if event.AvailableTickets > 0 {
+		return nil, errors.New("tickets are still available for this event")
+	}
+
+	// Penawaran waitlist tidak bisa memilih kursi, jadi event dengan seat map tidak didukung
+	if event.VenueID != 0 {
+		return nil, errors.New("waitlist is not available for events with assigned seating")
+	}
+
+	// Tier dicek sekarang agar entry yang tidak akan pernah bisa dipenuhi tidak masuk antrean
+	if err := s.checkTier(eventID, req.TierID); err != nil {
+		return nil, err
+	}
+
+	quantity := req.Quantity
+	if quantity == 0 {
+		quantity = 1
+	}
+
+	if event.MaxTicketsPerUser > 0 && quantity > event.MaxTicketsPerUser {
+		return nil, errors.New("ticket purchase limit per user exceeded for this event")
+	}
+
+	_, err = s.waitlistRepository.FindActiveEntry(eventID, userID)
+	if err == nil {
+		return nil, errors.New("user is already on the waitlist for this event")
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	entry := &entity.WaitlistEntry{
+		EventID:  eventID,
+		UserID:   userID,
+		TierID:   req.TierID,
+		Quantity: quantity,
+		Status:   "Waiting",
+	}
+
+	err = s.waitlistRepository.CreateEntry(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toWaitlistRes(entry)
+}
+
+func (s *waitlistService) FindMyWaitlistEntry(eventID, userID int) (*entity.WaitlistRes, error) {
+	entry, err := s.waitlistRepository.FindActiveEntry(eventID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toWaitlistRes(entry)
+}
+
+func (s *waitlistService) toWaitlistRes(entry *entity.WaitlistEntry) (*entity.WaitlistRes, error) {
+	waitlistRes := &entity.WaitlistRes{
+		ID:        entry.ID,
+		EventID:   entry.EventID,
+		UserID:    entry.UserID,
+		TierID:    entry.TierID,
+		Quantity:  entry.Quantity,
+		Status:    entry.Status,
+		HoldID:    entry.HoldID,
+		CreatedAt: entry.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+
+	if entry.OfferExpiresAt != nil {
+		waitlistRes.OfferExpiresAt = entry.OfferExpiresAt.Format("2006-01-02 15:04:05")
+	}
+
+	// Posisi hanya relevan selama user masih menunggu
+	if entry.Status == "Waiting" {
+		position, err := s.waitlistRepository.GetPosition(entry)
+		if err != nil {
+			return nil, err
+		}
+		waitlistRes.Position = position
+	}
+
+	return waitlistRes, nil
+}
+
+// checkTier memastikan tier_id milik event dan masih bisa dijual. Event yang memakai
+// tier wajib memilih tier, event tanpa tier tidak boleh memilih tier.
+func (s *waitlistService) checkTier(eventID, tierID int) error {
+	tiers, err := s.tierRepository.FindTiersByEventID(eventID)
+	if err != nil {
+		return err
+	}
+
+	if tierID == 0 {
+		if len(tiers) > 0 {
+			return fmt.Errorf("%w: tier_id is required for this event", ErrInvalidWaitlistTier)
+		}
+		return nil
+	}
+
+	for _, tier := range tiers {
+		if tier.ID != tierID {
+			continue
+		}
+		if tier.SaleEnd != nil && time.Now().After(*tier.SaleEnd) {
+			return fmt.Errorf("%w: ticket tier sale has ended", ErrInvalidWaitlistTier)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("%w: ticket tier not found for this event", ErrInvalidWaitlistTier)
+}
@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	icalProdID     = "-//dibimbing-take-home-test//Event Ticketing//ID"
+	icalTimeLayout = "20060102T150405Z"
+	// Panjang maksimal satu baris dalam octet sebelum harus dilipat (RFC 5545 3.1)
+	icalLineLimit = 75
+)
+
+const (
+	ICalStatusConfirmed = "CONFIRMED"
+	ICalStatusCancelled = "CANCELLED"
+)
+
+// ICalEvent adalah data satu VEVENT. Waktu selalu ditulis dalam UTC sehingga
+// kalender klien yang mengonversinya ke zona waktu pengguna.
+type ICalEvent struct {
+	UID          string
+	Summary      string
+	Description  string
+	Location     string
+	Start        time.Time
+	End          time.Time
+	Status       string
+	Sequence     int // Naik setiap kali event berubah agar klien mengganti salinan lamanya
+	LastModified time.Time
+}
+
+// BuildCalendar membuat dokumen VCALENDAR (RFC 5545) berisi event yang diberikan
+func BuildCalendar(name string, events []ICalEvent, now time.Time) []byte {
+	var b strings.Builder
+
+	writeICalLine(&b, "BEGIN:VCALENDAR")
+	writeICalLine(&b, "VERSION:2.0")
+	writeICalLine(&b, "PRODID:"+icalProdID)
+	writeICalLine(&b, "CALSCALE:GREGORIAN")
+	writeICalLine(&b, "METHOD:PUBLISH")
+	if name != "" {
+		writeICalLine(&b, "X-WR-CALNAME:"+EscapeICalText(name))
+	}
+
+	for _, event := range events {
+		writeICalLine(&b, "BEGIN:VEVENT")
+		writeICalLine(&b, "UID:"+event.UID)
+		writeICalLine(&b, "DTSTAMP:"+formatICalTime(now))
+		writeICalLine(&b, "DTSTART:"+formatICalTime(event.Start))
+		writeICalLine(&b, "DTEND:"+formatICalTime(event.End))
+		writeICalLine(&b, "SUMMARY:"+EscapeICalText(event.Summary))
+		if event.Description != "" {
+			writeICalLine(&b, "DESCRIPTION:"+EscapeICalText(event.Description))
+		}
+		if event.Location != "" {
+			writeICalLine(&b, "LOCATION:"+EscapeICalText(event.Location))
+		}
+		if event.Status != "" {
+			writeICalLine(&b, "STATUS:"+event.Status)
+		}
+		writeICalLine(&b, "SEQUENCE:"+strconv.Itoa(event.Sequence))
+		if !event.LastModified.IsZero() {
+			writeICalLine(&b, "LAST-MODIFIED:"+formatICalTime(event.LastModified))
+		}
+		writeICalLine(&b, "END:VEVENT")
+	}
+
+	writeICalLine(&b, "END:VCALENDAR")
+
+	return []byte(b.String())
+}
+
+// EscapeICalText meng-escape karakter khusus pada nilai bertipe TEXT
+func EscapeICalText(value string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	)
+	return replacer.Replace(value)
+}
+
+func formatICalTime(t time.Time) string {
+	return t.UTC().Format(icalTimeLayout)
+}
+
+// writeICalLine menulis satu content line dengan akhiran CRLF. Baris yang lebih
+// panjang dari 75 octet dilipat dengan CRLF diikuti spasi, tanpa memotong karakter UTF-8.
+func writeICalLine(b *strings.Builder, line string) {
+	limit := icalLineLimit
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+
+		// Spasi di awal baris lanjutan ikut dihitung dalam batas panjang
+		limit = icalLineLimit - 1
+	}
+
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
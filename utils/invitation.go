@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// InvitationTTL adalah masa berlaku link undangan. Masa tenggang kunci JWT tidak boleh
+// lebih pendek dari ini agar undangan tetap bisa diverifikasi setelah kunci dirotasi.
+const InvitationTTL = 24 * time.Hour
+
+const invitationAudience = "admin-invitation"
+
+var ErrInvalidInvitationToken = errors.New("invalid or expired invitation token")
+
+// GenerateInvitationToken menandatangani ID undangan dengan kunci JWT aktif. Data
+// undangan (email, role, status) tetap dibaca dari database saat diterima.
+func GenerateInvitationToken(invitationID int, expiresAt time.Time) (string, error) {
+	key, err := defaultKeyRing.signingKey()
+	if err != nil {
+		return "", err
+	}
+
+	return signToken(key, jwt.RegisteredClaims{
+		Subject:   strconv.Itoa(invitationID),
+		Audience:  jwt.ClaimStrings{invitationAudience},
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+	})
+}
+
+// ValidateInvitationToken memeriksa tanda tangan dan masa berlaku undangan lalu
+// mengembalikan ID undangan
+func ValidateInvitationToken(tokenString string) (int, error) {
+	claims := &jwt.RegisteredClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, verificationKeyFunc,
+		jwt.WithValidMethods([]string{AlgRS256, AlgEdDSA}),
+		jwt.WithAudience(invitationAudience),
+		jwt.WithExpirationRequired())
+	if err != nil {
+		return 0, ErrInvalidInvitationToken
+	}
+
+	invitationID, err := strconv.Atoi(claims.Subject)
+	if err != nil {
+		return 0, ErrInvalidInvitationToken
+	}
+
+	return invitationID, nil
+}
@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestInvitationToken(t *testing.T) {
+	active, err := GenerateKeyPair(AlgEdDSA)
+	if err != nil {
+		t.Fatalf("GenerateKeyPair returned an error: %v", err)
+	}
+	defaultKeyRing.Replace(active, nil)
+
+	token, err := GenerateInvitationToken(42, time.Now().Add(InvitationTTL))
+	if err != nil {
+		t.Fatalf("GenerateInvitationToken returned an error: %v", err)
+	}
+
+	invitationID, err := ValidateInvitationToken(token)
+	if err != nil {
+		t.Fatalf("ValidateInvitationToken rejected a valid token: %v", err)
+	}
+	if invitationID != 42 {
+		t.Fatalf("invitation id = %d, want 42", invitationID)
+	}
+
+	expired, err := GenerateInvitationToken(42, time.Now().Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("GenerateInvitationToken returned an error: %v", err)
+	}
+	if _, err := ValidateInvitationToken(expired); !errors.Is(err, ErrInvalidInvitationToken) {
+		t.Fatalf("expired token err = %v, want ErrInvalidInvitationToken", err)
+	}
+
+	// Token dengan audience lain, misalnya access token, tidak bisa dipakai sebagai undangan
+	other, err := signToken(active, jwt.RegisteredClaims{
+		Subject:   "42",
+		Audience:  jwt.ClaimStrings{"access"},
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	})
+	if err != nil {
+		t.Fatalf("signToken returned an error: %v", err)
+	}
+	if _, err := ValidateInvitationToken(other); !errors.Is(err, ErrInvalidInvitationToken) {
+		t.Fatalf("wrong audience err = %v, want ErrInvalidInvitationToken", err)
+	}
+
+	// Setelah kunci dirotasi tanpa kunci lama, undangan lama tidak bisa diverifikasi
+	rotated, err := GenerateKeyPair(AlgEdDSA)
+	if err != nil {
+		t.Fatalf("GenerateKeyPair returned an error: %v", err)
+	}
+	defaultKeyRing.Replace(rotated, nil)
+	if _, err := ValidateInvitationToken(token); !errors.Is(err, ErrInvalidInvitationToken) {
+		t.Fatalf("token signed by a removed key err = %v, want ErrInvalidInvitationToken", err)
+	}
+}
@@ -1,50 +1,63 @@
 package utils
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
-	"os"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-var secretKey = os.Getenv("JWT_SECRET_KEY")
-var jwtKey = []byte(secretKey)
-
-// var jwtKey = []byte("your-secret-key")
+// AccessTokenTTL sengaja singkat, sesi diperpanjang lewat refresh token
+const AccessTokenTTL = 15 * time.Minute
 
 type Claims struct {
 	UserID int    `json:"user_id"`
 	Role   string `json:"role"`
+	MFA    bool   `json:"mfa,omitempty"` // Login sudah melewati verifikasi dua langkah
 	jwt.RegisteredClaims
 }
 
-func GenerateJWT(userID int, role string) (string, error) {
-	expirationTime := time.Now().Add(24 * time.Hour) // Token berlaku selama 24 jam
+// GenerateJWT membuat access token dengan jti acak agar token bisa dicabut saat logout.
+// Token ditandatangani kunci aktif di key ring dan header kid menunjuk kunci tersebut.
+func GenerateJWT(userID int, role string, mfa bool) (string, time.Time, error) {
+	key, err := defaultKeyRing.signingKey()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	now := time.Now()
+	expirationTime := now.Add(AccessTokenTTL)
+
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", time.Time{}, err
+	}
 
 	claims := &Claims{
 		UserID: userID,
 		Role:   role,
+		MFA:    mfa,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        hex.EncodeToString(jti),
+			IssuedAt:  jwt.NewNumericDate(now),
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(jwtKey)
+	tokenString, err := signToken(key, claims)
 	if err != nil {
-		return "", err
+		return "", time.Time{}, err
 	}
 
-	return tokenString, nil
+	return tokenString, expirationTime, nil
 }
 
 func ValidateJWT(tokenString string) (*Claims, error) {
 	claims := &Claims{}
 
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		return jwtKey, nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, claims, verificationKeyFunc, jwt.WithValidMethods([]string{AlgRS256, AlgEdDSA}))
 
 	if err != nil {
 		return nil, err
@@ -54,5 +67,38 @@ func ValidateJWT(tokenString string) (*Claims, error) {
 		return nil, errors.New("invalid token")
 	}
 
+	// Token lama tanpa jti tidak bisa dicabut, jadi tidak diterima lagi
+	if claims.ID == "" || claims.ExpiresAt == nil {
+		return nil, errors.New("token has no id")
+	}
+
+	// Access token tidak memiliki audience. Token lain yang ditandatangani kunci yang
+	// sama, misalnya undangan admin, selalu memiliki audience sehingga ditolak di sini.
+	if len(claims.Audience) > 0 {
+		return nil, errors.New("token is not an access token")
+	}
+
 	return claims, nil
 }
+
+func signToken(key *KeyPair, claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(key.Algorithm), claims)
+	token.Header["kid"] = key.KID
+	return token.SignedString(key.Private)
+}
+
+// verificationKeyFunc mencari kunci publik berdasarkan header kid
+func verificationKeyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, err := defaultKeyRing.verificationKey(kid)
+	if err != nil {
+		return nil, err
+	}
+
+	// Algoritma di header harus sama dengan algoritma kunci agar tidak bisa ditukar
+	if token.Method.Alg() != key.Algorithm {
+		return nil, errors.New("token algorithm does not match signing key")
+	}
+
+	return key.Public, nil
+}
@@ -0,0 +1,324 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+)
+
+const (
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+var (
+	ErrNoSigningKey      = errors.New("no active JWT signing key")
+	ErrUnknownSigningKey = errors.New("unknown JWT signing key")
+	ErrUnsupportedKeyAlg = errors.New("unsupported JWT signing algorithm, use RS256 or EdDSA")
+	ErrInvalidSignature  = errors.New("invalid signature")
+)
+
+var defaultKeyRing = NewKeyRing()
+
+// KeyPair adalah satu kunci penandatangan JWT beserta kunci publiknya
+type KeyPair struct {
+	KID       string
+	Algorithm string
+	Private   crypto.Signer
+	Public    crypto.PublicKey
+}
+
+// KeyRing menyimpan kunci aktif untuk menandatangani token baru dan semua kunci yang
+// masih boleh dipakai untuk verifikasi. Isinya diganti utuh setiap kali kunci dirotasi.
+type KeyRing struct {
+	mu     sync.RWMutex
+	active *KeyPair
+	keys   map[string]*KeyPair
+
+	// refresh dipanggil ketika token memakai kid yang belum dikenal, misalnya kunci
+	// baru hasil rotasi instance lain. Dibatasi sekali per refreshInterval.
+	refresh         func() error
+	refreshInterval time.Duration
+	lastRefresh     time.Time
+	refreshMu       sync.Mutex
+}
+
+func NewKeyRing() *KeyRing {
+	return &KeyRing{keys: make(map[string]*KeyPair)}
+}
+
+// DefaultKeyRing adalah key ring yang dipakai GenerateJWT dan ValidateJWT
+func DefaultKeyRing() *KeyRing {
+	return defaultKeyRing
+}
+
+// Replace mengganti kunci aktif dan daftar kunci verifikasi sekaligus
+func (r *KeyRing) Replace(active *KeyPair, keys []*KeyPair) {
+	byKID := make(map[string]*KeyPair, len(keys)+1)
+	for _, key := range keys {
+		byKID[key.KID] = key
+	}
+	if active != nil {
+		byKID[active.KID] = active
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.active = active
+	r.keys = byKID
+}
+
+func (r *KeyRing) signingKey() (*KeyPair, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.active == nil {
+		return nil, ErrNoSigningKey
+	}
+	return r.active, nil
+}
+
+// SetRefresher mengatur fungsi untuk memuat ulang kunci saat kid tidak dikenal
+func (r *KeyRing) SetRefresher(refresh func() error, interval time.Duration) {
+	r.refreshMu.Lock()
+	defer r.refreshMu.Unlock()
+	r.refresh = refresh
+	r.refreshInterval = interval
+	r.lastRefresh = time.Time{}
+}
+
+func (r *KeyRing) verificationKey(kid string) (*KeyPair, error) {
+	if key, ok := r.lookup(kid); ok {
+		return key, nil
+	}
+
+	if !r.refreshKeys() {
+		return nil, ErrUnknownSigningKey
+	}
+
+	key, ok := r.lookup(kid)
+	if !ok {
+		return nil, ErrUnknownSigningKey
+	}
+	return key, nil
+}
+
+func (r *KeyRing) lookup(kid string) (*KeyPair, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	key, ok := r.keys[kid]
+	return key, ok
+}
+
+// refreshKeys memanggil refresher jika ada dan belum dipanggil dalam interval terakhir,
+// sehingga token dengan kid acak tidak bisa dipakai untuk membanjiri database
+func (r *KeyRing) refreshKeys() bool {
+	r.refreshMu.Lock()
+	defer r.refreshMu.Unlock()
+
+	if r.refresh == nil || time.Since(r.lastRefresh) < r.refreshInterval {
+		return false
+	}
+
+	r.lastRefresh = time.Now()
+	return r.refresh() == nil
+}
+
+// SignData menandatangani data selain JWT, misalnya bundle check-in offline, dengan
+// kunci aktif. Penerima memverifikasinya dengan kunci publik dari JWKS sesuai kid,
+// jadi perangkat yang memverifikasi tidak pernah memegang kunci privat.
+func (r *KeyRing) SignData(payload []byte) (*KeyPair, string, error) {
+	key, err := r.signingKey()
+	if err != nil {
+		return nil, "", err
+	}
+
+	var signature []byte
+	switch key.Algorithm {
+	case AlgRS256:
+		digest := sha256.Sum256(payload)
+		signature, err = key.Private.Sign(rand.Reader, digest[:], crypto.SHA256)
+	case AlgEdDSA:
+		signature, err = key.Private.Sign(rand.Reader, payload, crypto.Hash(0))
+	default:
+		err = ErrUnsupportedKeyAlg
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	return key, base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// VerifyData memverifikasi signature dari SignData dengan kunci publik kid
+func (r *KeyRing) VerifyData(kid string, payload []byte, signature string) error {
+	key, err := r.verificationKey(kid)
+	if err != nil {
+		return err
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	switch public := key.Public.(type) {
+	case *rsa.PublicKey:
+		digest := sha256.Sum256(payload)
+		if rsa.VerifyPKCS1v15(public, crypto.SHA256, digest[:], raw) != nil {
+			return ErrInvalidSignature
+		}
+	case ed25519.PublicKey:
+		if !ed25519.Verify(public, payload, raw) {
+			return ErrInvalidSignature
+		}
+	default:
+		return ErrUnsupportedKeyAlg
+	}
+
+	return nil
+}
+
+// JWK adalah kunci publik dalam format JSON Web Key (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n,omitempty"`   // Modulus RSA
+	E   string `json:"e,omitempty"`   // Eksponen RSA
+	Crv string `json:"crv,omitempty"` // Kurva OKP, selalu Ed25519
+	X   string `json:"x,omitempty"`   // Kunci publik Ed25519
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS mengembalikan kunci publik semua kunci di key ring agar layanan lain bisa
+// memverifikasi token tanpa mengetahui kunci privat
+func (r *KeyRing) JWKS() JWKSet {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	set := JWKSet{Keys: []JWK{}}
+	for _, key := range r.keys {
+		jwk := JWK{Use: "sig", Alg: key.Algorithm, Kid: key.KID}
+
+		switch public := key.Public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		default:
+			continue
+		}
+
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	return set
+}
+
+// GenerateKeyPair membuat kunci baru dengan kid acak
+func GenerateKeyPair(algorithm string) (*KeyPair, error) {
+	kid := make([]byte, 8)
+	if _, err := rand.Read(kid); err != nil {
+		return nil, err
+	}
+
+	var private crypto.Signer
+	switch algorithm {
+	case AlgRS256:
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return nil, err
+		}
+		private = key
+	case AlgEdDSA:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		private = key
+	default:
+		return nil, ErrUnsupportedKeyAlg
+	}
+
+	return &KeyPair{
+		KID:       hex.EncodeToString(kid),
+		Algorithm: algorithm,
+		Private:   private,
+		Public:    private.Public(),
+	}, nil
+}
+
+// EncodeKeyPair mengubah kunci menjadi PEM (PKCS#8 untuk kunci privat, PKIX untuk
+// kunci publik) agar bisa disimpan di database
+func EncodeKeyPair(key *KeyPair) (string, string, error) {
+	privateDER, err := x509.MarshalPKCS8PrivateKey(key.Private)
+	if err != nil {
+		return "", "", err
+	}
+
+	publicDER, err := x509.MarshalPKIXPublicKey(key.Public)
+	if err != nil {
+		return "", "", err
+	}
+
+	privatePEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER})
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+	return string(privatePEM), string(publicPEM), nil
+}
+
+// ParseKeyPair membaca kembali kunci privat PEM yang disimpan oleh EncodeKeyPair
+func ParseKeyPair(kid, algorithm, privatePEM string) (*KeyPair, error) {
+	block, _ := pem.Decode([]byte(privatePEM))
+	if block == nil {
+		return nil, fmt.Errorf("signing key %s is not valid PEM", kid)
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	var private crypto.Signer
+	switch key := parsed.(type) {
+	case *rsa.PrivateKey:
+		if algorithm != AlgRS256 {
+			return nil, fmt.Errorf("signing key %s is RSA but marked as %s", kid, algorithm)
+		}
+		private = key
+	case ed25519.PrivateKey:
+		if algorithm != AlgEdDSA {
+			return nil, fmt.Errorf("signing key %s is Ed25519 but marked as %s", kid, algorithm)
+		}
+		private = key
+	default:
+		return nil, ErrUnsupportedKeyAlg
+	}
+
+	return &KeyPair{
+		KID:       kid,
+		Algorithm: algorithm,
+		Private:   private,
+		Public:    private.Public(),
+	}, nil
+}
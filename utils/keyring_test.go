@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestKeyRingSignData(t *testing.T) {
+	for _, algorithm := range []string{AlgRS256, AlgEdDSA} {
+		t.Run(algorithm, func(t *testing.T) {
+			active, err := GenerateKeyPair(algorithm)
+			if err != nil {
+				t.Fatalf("GenerateKeyPair returned an error: %v", err)
+			}
+
+			ring := NewKeyRing()
+			ring.Replace(active, nil)
+
+			payload := []byte(`{"event_id":1,"tickets":[]}`)
+			key, signature, err := ring.SignData(payload)
+			if err != nil {
+				t.Fatalf("SignData returned an error: %v", err)
+			}
+			if key.KID != active.KID {
+				t.Fatalf("signed with kid %q, want %q", key.KID, active.KID)
+			}
+
+			if err := ring.VerifyData(key.KID, payload, signature); err != nil {
+				t.Fatalf("VerifyData rejected a valid signature: %v", err)
+			}
+
+			tampered := []byte(`{"event_id":2,"tickets":[]}`)
+			if err := ring.VerifyData(key.KID, tampered, signature); !errors.Is(err, ErrInvalidSignature) {
+				t.Fatalf("tampered payload: err = %v, want ErrInvalidSignature", err)
+			}
+
+			if err := ring.VerifyData("unknown", payload, signature); !errors.Is(err, ErrUnknownSigningKey) {
+				t.Fatalf("unknown kid: err = %v, want ErrUnknownSigningKey", err)
+			}
+		})
+	}
+}
+
+func TestKeyRingSignDataWithoutActiveKey(t *testing.T) {
+	if _, _, err := NewKeyRing().SignData([]byte("payload")); !errors.Is(err, ErrNoSigningKey) {
+		t.Fatalf("err = %v, want ErrNoSigningKey", err)
+	}
+}
@@ -0,0 +1,188 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Batas jumlah kemunculan yang dihitung untuk satu aturan, mencegah loop tanpa akhir
+const maxRecurrenceOccurrences = 1000
+
+var ErrInvalidRRule = errors.New("invalid recurrence rule")
+
+var rruleWeekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// RRule adalah bagian dari aturan pengulangan iCalendar (RFC 5545) yang didukung:
+// FREQ=DAILY|WEEKLY|MONTHLY, INTERVAL, BYDAY (hanya untuk WEEKLY), COUNT dan UNTIL.
+// Contoh: FREQ=WEEKLY;BYDAY=SA;COUNT=10
+type RRule struct {
+	Freq     string
+	Interval int
+	ByDay    []time.Weekday
+	Count    int       // 0 berarti tidak dibatasi jumlah
+	Until    time.Time // Zero berarti tidak dibatasi tanggal
+}
+
+func ParseRRule(rule string) (*RRule, error) {
+	r := &RRule{Interval: 1}
+
+	for _, part := range strings.Split(strings.TrimPrefix(strings.TrimSpace(rule), "RRULE:"), ";") {
+		if part == "" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: %q is not a KEY=VALUE pair", ErrInvalidRRule, part)
+		}
+
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			r.Freq = strings.ToUpper(value)
+		case "INTERVAL":
+			interval, err := strconv.Atoi(value)
+			if err != nil || interval < 1 {
+				return nil, fmt.Errorf("%w: INTERVAL must be a positive number", ErrInvalidRRule)
+			}
+			r.Interval = interval
+		case "COUNT":
+			count, err := strconv.Atoi(value)
+			if err != nil || count < 1 {
+				return nil, fmt.Errorf("%w: COUNT must be a positive number", ErrInvalidRRule)
+			}
+			r.Count = count
+		case "UNTIL":
+			until, err := parseRRuleUntil(value)
+			if err != nil {
+				return nil, err
+			}
+			r.Until = until
+		case "BYDAY":
+			for _, day := range strings.Split(strings.ToUpper(value), ",") {
+				weekday, ok := rruleWeekdays[day]
+				if !ok {
+					return nil, fmt.Errorf("%w: unknown BYDAY value %q", ErrInvalidRRule, day)
+				}
+				r.ByDay = append(r.ByDay, weekday)
+			}
+		default:
+			return nil, fmt.Errorf("%w: %s is not supported", ErrInvalidRRule, key)
+		}
+	}
+
+	switch r.Freq {
+	case "DAILY", "MONTHLY":
+		if len(r.ByDay) > 0 {
+			return nil, fmt.Errorf("%w: BYDAY is only supported with FREQ=WEEKLY", ErrInvalidRRule)
+		}
+	case "WEEKLY":
+	default:
+		return nil, fmt.Errorf("%w: FREQ must be DAILY, WEEKLY or MONTHLY", ErrInvalidRRule)
+	}
+
+	if r.Count > 0 && !r.Until.IsZero() {
+		return nil, fmt.Errorf("%w: COUNT and UNTIL cannot be used together", ErrInvalidRRule)
+	}
+
+	// Urutkan hari mulai dari Senin seperti minggu pada iCalendar
+	sort.Slice(r.ByDay, func(i, j int) bool {
+		return mondayOffset(r.ByDay[i]) < mondayOffset(r.ByDay[j])
+	})
+
+	return r, nil
+}
+
+func parseRRuleUntil(value string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102"} {
+		if until, err := time.Parse(layout, value); err == nil {
+			// UNTIL berupa tanggal berarti kemunculan pada hari itu masih termasuk
+			if layout == "20060102" {
+				until = until.AddDate(0, 0, 1).Add(-time.Second)
+			}
+			return until, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: UNTIL must be YYYYMMDD or YYYYMMDDTHHMMSSZ", ErrInvalidRRule)
+}
+
+// IsBounded bernilai true jika aturan memiliki COUNT atau UNTIL
+func (r *RRule) IsBounded() bool {
+	return r.Count > 0 || !r.Until.IsZero()
+}
+
+// Occurrences mengembalikan semua kemunculan mulai dari dtstart (termasuk) sampai
+// horizon (termasuk). COUNT dihitung sejak dtstart sehingga hasilnya selalu sama
+// walaupun dipanggil ulang dengan horizon yang lebih jauh.
+func (r *RRule) Occurrences(dtstart, horizon time.Time) []time.Time {
+	var occurrences []time.Time
+	total := 0
+
+	// add mengembalikan false jika pengulangan sudah selesai
+	add := func(t time.Time) bool {
+		if t.Before(dtstart) {
+			return true
+		}
+		if !r.Until.IsZero() && t.After(r.Until) {
+			return false
+		}
+		if t.After(horizon) {
+			return false
+		}
+		total++
+		occurrences = append(occurrences, t)
+		return !(r.Count > 0 && total >= r.Count) && total < maxRecurrenceOccurrences
+	}
+
+	switch r.Freq {
+	case "DAILY":
+		for i := 0; ; i++ {
+			if !add(dtstart.AddDate(0, 0, i*r.Interval)) {
+				return occurrences
+			}
+		}
+	case "WEEKLY":
+		days := r.ByDay
+		if len(days) == 0 {
+			days = []time.Weekday{dtstart.Weekday()}
+		}
+
+		weekStart := dtstart.AddDate(0, 0, -mondayOffset(dtstart.Weekday()))
+		for week := 0; ; week++ {
+			base := weekStart.AddDate(0, 0, week*7*r.Interval)
+			for _, day := range days {
+				if !add(base.AddDate(0, 0, mondayOffset(day))) {
+					return occurrences
+				}
+			}
+		}
+	case "MONTHLY":
+		// Bulan yang tidak memiliki tanggal tersebut dilewati, misalnya tanggal 31
+		for i := 0; i < maxRecurrenceOccurrences*r.Interval; i++ {
+			t := dtstart.AddDate(0, i*r.Interval, 0)
+			if t.Day() != dtstart.Day() {
+				continue
+			}
+			if !add(t) {
+				return occurrences
+			}
+		}
+	}
+
+	return occurrences
+}
+
+func mondayOffset(day time.Weekday) int {
+	return (int(day) + 6) % 7
+}
@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MinTicketCodeSecretLength adalah panjang minimal secret kode tiket (256 bit)
+const MinTicketCodeSecretLength = 32
+
+var ticketCodeKey []byte
+
+var (
+	ErrInvalidTicketCode        = errors.New("invalid ticket code")
+	ErrTicketCodeSecretNotSet   = errors.New("ticket code secret is not configured")
+	ErrTicketCodeSecretTooShort = fmt.Errorf("ticket code secret must be at least %d characters", MinTicketCodeSecretLength)
+)
+
+// SetTicketCodeSecret memasang secret untuk menandatangani kode tiket. Dipanggil
+// sekali saat aplikasi start, sebelum ada kode tiket yang dibuat atau diverifikasi.
+func SetTicketCodeSecret(secret string) error {
+	if secret == "" {
+		return ErrTicketCodeSecretNotSet
+	}
+	if len(secret) < MinTicketCodeSecretLength {
+		return ErrTicketCodeSecretTooShort
+	}
+
+	ticketCodeKey = []byte(secret)
+	return nil
+}
+
+// GenerateTicketCode membuat kode tiket dengan format <ticket id>.<nonce acak>.<signature>.
+// Nonce membuat kode tidak bisa ditebak dan signature HMAC memastikan kode
+// tidak bisa dipalsukan tanpa secret.
+func GenerateTicketCode(ticketID int) (string, error) {
+	if len(ticketCodeKey) == 0 {
+		return "", ErrTicketCodeSecretNotSet
+	}
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	payload := fmt.Sprintf("%d.%s", ticketID, base64.RawURLEncoding.EncodeToString(nonce))
+	return payload + "." + signTicketCode([]byte(payload)), nil
+}
+
+// ParseTicketCode memverifikasi signature kode tiket dan mengembalikan ID tiketnya
+func ParseTicketCode(code string) (int, error) {
+	parts := strings.Split(code, ".")
+	if len(parts) != 3 || len(ticketCodeKey) == 0 {
+		return 0, ErrInvalidTicketCode
+	}
+
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(signTicketCode([]byte(payload)))) {
+		return 0, ErrInvalidTicketCode
+	}
+
+	ticketID, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, ErrInvalidTicketCode
+	}
+
+	return ticketID, nil
+}
+
+// signTicketCode menandatangani kode tiket dengan TICKET_CODE_SECRET. Secret ini hanya
+// dipakai untuk kode tiket dan tidak pernah dibagikan ke perangkat scanner.
+func signTicketCode(payload []byte) string {
+	mac := hmac.New(sha256.New, ticketCodeKey)
+	mac.Write(payload)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
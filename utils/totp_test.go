@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// Secret dari test vector RFC 6238 lampiran B ("12345678901234567890") dalam base32
+const rfcTOTPSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCodeMatchesRFCVectors(t *testing.T) {
+	// Kode 8 digit di RFC dipotong menjadi 6 digit terakhir
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tt := range tests {
+		code, err := TOTPCode(rfcTOTPSecret, TOTPStep(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("TOTPCode returned an error: %v", err)
+		}
+		if code != tt.want {
+			t.Fatalf("TOTPCode at %d = %s, want %s", tt.unix, code, tt.want)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	step := TOTPStep(now)
+
+	code, err := TOTPCode(rfcTOTPSecret, step-1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Kode dari periode sebelumnya masih diterima untuk menoleransi selisih jam
+	matched, ok := ValidateTOTP(rfcTOTPSecret, code[:3]+" "+code[3:], now)
+	if !ok || matched != step-1 {
+		t.Fatalf("ValidateTOTP = %d, %v, want %d, true", matched, ok, step-1)
+	}
+
+	// Secret dengan huruf kecil tetap valid
+	if _, ok := ValidateTOTP(strings.ToLower(rfcTOTPSecret), code, now); !ok {
+		t.Fatal("expected a lowercase secret to be accepted")
+	}
+
+	old, err := TOTPCode(rfcTOTPSecret, step-2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := ValidateTOTP(rfcTOTPSecret, old, now); ok {
+		t.Fatal("expected a code outside the allowed skew to be rejected")
+	}
+
+	for _, invalid := range []string{"", "12345", "1234567", "abcdef"} {
+		if _, ok := ValidateTOTP(rfcTOTPSecret, invalid, now); ok {
+			t.Fatalf("expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestTOTPURI(t *testing.T) {
+	uri := TOTPURI("Event Tickets", "user@example.com", rfcTOTPSecret)
+
+	if !strings.HasPrefix(uri, "otpauth://totp/Event%20Tickets:user@example.com?") {
+		t.Fatalf("unexpected label in %s", uri)
+	}
+	if strings.Contains(uri, "+") {
+		t.Fatalf("URI must not encode spaces as +: %s", uri)
+	}
+	if !strings.Contains(uri, "secret="+rfcTOTPSecret) {
+		t.Fatalf("URI does not contain the secret: %s", uri)
+	}
+}